@@ -12,5 +12,8 @@ provider "zabbix" {
   # Serialize Zabbix API calls (false by default)
   # Note: race conditions have been observed, enable this if required
   serialize = true
+
+  # Convert legacy trigger expressions to the zabbix 5.4+ syntax (false by default)
+  normalize_expressions = true
 }
 
@@ -6,6 +6,7 @@ require (
 	github.com/hashicorp/terraform v0.12.23
 	github.com/hashicorp/terraform-plugin-sdk v1.7.0
 	github.com/tpretz/go-zabbix-api v0.16.0
+	gopkg.in/yaml.v2 v2.4.0
 )
 
 //replace github.com/tpretz/go-zabbix-api => ../go-zabbix-api
@@ -1,15 +1,128 @@
 package main
 
 import (
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
 	"github.com/hashicorp/terraform-plugin-sdk/plugin"
 	"github.com/hashicorp/terraform-plugin-sdk/terraform"
+	"github.com/tpretz/go-zabbix-api"
 	"github.com/tpretz/terraform-provider-zabbix/provider"
 )
 
+// subcommands, otherwise run as a terraform plugin
+var commands = map[string]func([]string) error{
+	"export":  export,
+	"convert": convert,
+}
+
 func main() {
+	if len(os.Args) > 1 {
+		if cmd, ok := commands[os.Args[1]]; ok {
+			if err := cmd(os.Args[2:]); err != nil {
+				fmt.Fprintf(os.Stderr, "%s: %s\n", os.Args[1], err)
+				os.Exit(1)
+			}
+			return
+		}
+	}
+
 	plugin.Serve(&plugin.ServeOpts{
 		ProviderFunc: func() terraform.ResourceProvider {
 			return provider.Provider()
 		},
 	})
 }
+
+// env first non empty environment variable
+func env(keys ...string) string {
+	for _, k := range keys {
+		if v := os.Getenv(k); v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+// export render an existing template as terraform config with import blocks
+func export(args []string) error {
+	flags := flag.NewFlagSet("export", flag.ExitOnError)
+	template := flags.String("template", "", "template host (internal name) to export")
+	prefix := flags.String("prefix", "", "terraform resource name prefix")
+	url := flags.String("url", env("ZABBIX_URL", "ZABBIX_SERVER_URL"), "Zabbix API url")
+	username := flags.String("username", env("ZABBIX_USER", "ZABBIX_USERNAME"), "Zabbix API username")
+	password := flags.String("password", env("ZABBIX_PASS", "ZABBIX_PASSWORD"), "Zabbix API password")
+	insecure := flags.Bool("tls-insecure", false, "disable TLS certificate checking")
+	debug := flags.Bool("debug", false, "log api requests to stderr")
+	flags.Parse(args)
+
+	if !*debug {
+		log.SetOutput(ioutil.Discard)
+	}
+
+	if *template == "" {
+		return fmt.Errorf("-template is required")
+	}
+	if *url == "" {
+		return fmt.Errorf("-url or ZABBIX_URL is required")
+	}
+
+	api, err := zabbix.NewAPI(zabbix.Config{
+		Url:         *url,
+		TlsNoVerify: *insecure,
+	})
+	if err != nil {
+		return err
+	}
+	if _, err := api.Login(*username, *password); err != nil {
+		return err
+	}
+
+	return provider.ExportTemplate(api, provider.ExportOptions{
+		Template: *template,
+		Prefix:   *prefix,
+	}, os.Stdout)
+}
+
+// convert render a template export file (xml, json or yaml) as terraform config
+func convert(args []string) error {
+	flags := flag.NewFlagSet("convert", flag.ExitOnError)
+	input := flags.String("input", "", "template export file, - for stdin")
+	format := flags.String("format", "", "export format, xml, json or yaml (default from file extension)")
+	prefix := flags.String("prefix", "", "terraform resource name prefix")
+	snmp := flags.String("snmp", "2", "snmp version of snmp items, for 5.0+ exports")
+	flags.Parse(args)
+
+	if *input == "" {
+		return fmt.Errorf("-input is required")
+	}
+
+	var in []byte
+	var err error
+	if *input == "-" {
+		in, err = ioutil.ReadAll(os.Stdin)
+	} else {
+		in, err = ioutil.ReadFile(*input)
+	}
+	if err != nil {
+		return err
+	}
+
+	if *format == "" {
+		*format = strings.TrimPrefix(strings.ToLower(filepath.Ext(*input)), ".")
+		if *format == "yml" {
+			*format = "yaml"
+		}
+	}
+
+	return provider.ConvertTemplate(in, provider.ConvertOptions{
+		Format:      *format,
+		Prefix:      *prefix,
+		SnmpVersion: *snmp,
+	}, os.Stdout)
+}
@@ -0,0 +1,1116 @@
+package provider
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+)
+
+// exprFunction accepted parameter counts, max -1 for unlimited
+type exprFunction struct {
+	min int
+	max int
+}
+
+// 5.4+ history and aggregate functions, the item query is counted as the first parameter,
+// functions not listed are passed to the server unchecked
+var EXPRESSION_HISTORY_FUNCTIONS = map[string]exprFunction{
+	"avg":                 {2, 2},
+	"baselinedev":         {4, 4},
+	"baselinewma":         {4, 4},
+	"bucket_percentile":   {3, 3},
+	"bucket_rate_foreach": {2, 3},
+	"change":              {1, 1},
+	"changecount":         {2, 3},
+	"count":               {2, 4},
+	"countunique":         {2, 4},
+	"find":                {1, 4},
+	"first":               {2, 2},
+	"firstclock":          {2, 2},
+	"forecast":            {3, 5},
+	"fuzzytime":           {2, 2},
+	"kurtosis":            {2, 2},
+	"last":                {1, 2},
+	"lastclock":           {1, 2},
+	"logeventid":          {1, 3},
+	"logseverity":         {1, 2},
+	"logsource":           {1, 3},
+	"logtimestamp":        {1, 2},
+	"mad":                 {2, 2},
+	"max":                 {2, 2},
+	"min":                 {2, 2},
+	"monodec":             {2, 3},
+	"monoinc":             {2, 3},
+	"nodata":              {2, 3},
+	"percentile":          {3, 3},
+	"rate":                {2, 2},
+	"skewness":            {2, 2},
+	"stddevpop":           {2, 2},
+	"stddevsamp":          {2, 2},
+	"sum":                 {2, 2},
+	"sumofsquares":        {2, 2},
+	"timeleft":            {3, 4},
+	"trendavg":            {2, 2},
+	"trendcount":          {2, 2},
+	"trendmax":            {2, 2},
+	"trendmin":            {2, 2},
+	"trendstl":            {4, 7},
+	"trendsum":            {2, 2},
+	"varpop":              {2, 2},
+	"varsamp":             {2, 2},
+
+	// aggregate, wildcard item queries
+	"avg_foreach":    {2, 2},
+	"count_foreach":  {2, 4},
+	"exists_foreach": {1, 1},
+	"item_count":     {1, 1},
+	"last_foreach":   {1, 2},
+	"max_foreach":    {2, 2},
+	"min_foreach":    {2, 2},
+	"sum_foreach":    {2, 2},
+}
+
+// 5.4+ math, string, date and operator functions, not taking an item query
+var EXPRESSION_FUNCTIONS = map[string]exprFunction{
+	"abs":                {1, 1},
+	"acos":               {1, 1},
+	"ascii":              {1, 1},
+	"asin":               {1, 1},
+	"atan":               {1, 1},
+	"atan2":              {2, 2},
+	"avg":                {1, -1},
+	"between":            {3, 3},
+	"bitand":             {2, 2},
+	"bitlength":          {1, 1},
+	"bitlshift":          {2, 2},
+	"bitnot":             {1, 1},
+	"bitor":              {2, 2},
+	"bitrshift":          {2, 2},
+	"bitxor":             {2, 2},
+	"bytelength":         {1, 1},
+	"cbrt":               {1, 1},
+	"ceil":               {1, 1},
+	"char":               {1, 1},
+	"concat":             {2, -1},
+	"cos":                {1, 1},
+	"cosh":               {1, 1},
+	"cot":                {1, 1},
+	"date":               {0, 0},
+	"dayofmonth":         {0, 0},
+	"dayofweek":          {0, 0},
+	"degrees":            {1, 1},
+	"e":                  {0, 0},
+	"exp":                {1, 1},
+	"expm1":              {1, 1},
+	"floor":              {1, 1},
+	"histogram_quantile": {2, -1},
+	"in":                 {2, -1},
+	"insert":             {4, 4},
+	"jsonpath":           {2, 3},
+	"kurtosis":           {1, -1},
+	"left":               {2, 2},
+	"length":             {1, 1},
+	"log":                {1, 1},
+	"log10":              {1, 1},
+	"ltrim":              {1, 2},
+	"mad":                {1, -1},
+	"max":                {1, -1},
+	"mid":                {3, 3},
+	"min":                {1, -1},
+	"mod":                {2, 2},
+	"now":                {0, 0},
+	"pi":                 {0, 0},
+	"power":              {2, 2},
+	"radians":            {1, 1},
+	"rand":               {0, 0},
+	"repeat":             {2, 2},
+	"replace":            {3, 3},
+	"right":              {2, 2},
+	"round":              {2, 2},
+	"rtrim":              {1, 2},
+	"signum":             {1, 1},
+	"sin":                {1, 1},
+	"sinh":               {1, 1},
+	"skewness":           {1, -1},
+	"sqrt":               {1, 1},
+	"stddevpop":          {1, -1},
+	"stddevsamp":         {1, -1},
+	"sum":                {1, -1},
+	"sumofsquares":       {1, -1},
+	"tan":                {1, 1},
+	"time":               {0, 0},
+	"trim":               {1, 2},
+	"truncate":           {2, 2},
+	"varpop":             {1, -1},
+	"varsamp":            {1, -1},
+	"xmlxpath":           {2, 3},
+}
+
+// pre 5.4 {host:key.func(params)} functions, the item is not counted
+var EXPRESSION_LEGACY_FUNCTIONS = map[string]exprFunction{
+	"abschange":   {0, 0},
+	"avg":         {1, 2},
+	"band":        {2, 3},
+	"change":      {0, 0},
+	"count":       {1, 4},
+	"date":        {0, 0},
+	"dayofmonth":  {0, 0},
+	"dayofweek":   {0, 0},
+	"delta":       {1, 2},
+	"diff":        {0, 0},
+	"forecast":    {2, 5},
+	"fuzzytime":   {1, 1},
+	"iregexp":     {1, 2},
+	"last":        {0, 2},
+	"logeventid":  {1, 1},
+	"logseverity": {0, 0},
+	"logsource":   {1, 1},
+	"max":         {1, 2},
+	"min":         {1, 2},
+	"nodata":      {1, 2},
+	"now":         {0, 0},
+	"percentile":  {2, 3},
+	"prev":        {0, 0},
+	"regexp":      {1, 2},
+	"str":         {1, 2},
+	"strlen":      {0, 2},
+	"sum":         {1, 2},
+	"time":        {0, 0},
+	"timeleft":    {2, 4},
+	"trendavg":    {2, 2},
+	"trendcount":  {2, 2},
+	"trenddelta":  {2, 2},
+	"trendmax":    {2, 2},
+	"trendmin":    {2, 2},
+	"trendsum":    {2, 2},
+}
+
+// first version using func(/host/key) expressions
+const expressionVersion = 50400
+
+type exprNodeType int
+
+const (
+	exprNumber exprNodeType = iota
+	exprString
+	exprMacro
+	exprParam
+	exprQuery
+	exprFunc
+	exprUnary
+	exprBinary
+	exprGroup
+)
+
+// exprNode parsed expression element
+type exprNode struct {
+	Type exprNodeType
+	Pos  int
+
+	// literal, macro, raw parameter or operator
+	Value string
+
+	// function name, legacy set for {host:key.func()} references
+	Func   string
+	Legacy bool
+
+	// item query, filter only on 5.4+ queries
+	Host   string
+	Key    string
+	Filter string
+
+	// function parameters, operands or group content
+	Args []*exprNode
+}
+
+// exprError parse error at a position of the expression
+type exprError struct {
+	Pos int
+	Msg string
+}
+
+func (e *exprError) Error() string {
+	return fmt.Sprintf("column %d: %s", e.Pos+1, e.Msg)
+}
+
+// exprParser recursive descent parser, version 0 accepts both syntaxes
+type exprParser struct {
+	src     string
+	pos     int
+	version int
+}
+
+var exprMacroName = regexp.MustCompile(`^[A-Z][A-Z0-9_]*(\.[A-Z0-9_]+)*$`)
+var exprPeriod = regexp.MustCompile(`^#?[0-9]+[smhdwMy]?(:now(/[smhdwMy])?([-+][0-9]+[smhdwMy]?)*)?$`)
+
+// parseExpression parse a trigger expression or calculated item formula
+func parseExpression(src string, version int) (*exprNode, error) {
+	p := &exprParser{src: src, version: version}
+	n, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	p.skipSpace()
+	if p.pos < len(p.src) {
+		return nil, p.errorf(p.pos, "unexpected %q", p.src[p.pos])
+	}
+	return n, nil
+}
+
+func (p *exprParser) errorf(pos int, format string, a ...interface{}) error {
+	return &exprError{Pos: pos, Msg: fmt.Sprintf(format, a...)}
+}
+
+func (p *exprParser) skipSpace() {
+	for p.pos < len(p.src) && strings.IndexByte(" \t\r\n", p.src[p.pos]) >= 0 {
+		p.pos++
+	}
+}
+
+// keyword match a word operator, not followed by an identifier character
+func (p *exprParser) keyword(word string) bool {
+	p.skipSpace()
+	end := p.pos + len(word)
+	if !strings.HasPrefix(p.src[p.pos:], word) || (end < len(p.src) && exprIsIdent(p.src[end])) {
+		return false
+	}
+	p.pos = end
+	return true
+}
+
+// operator match the longest of the given symbol operators
+func (p *exprParser) operator(ops ...string) string {
+	p.skipSpace()
+	match := ""
+	for _, op := range ops {
+		if strings.HasPrefix(p.src[p.pos:], op) && len(op) > len(match) {
+			match = op
+		}
+	}
+	p.pos += len(match)
+	return match
+}
+
+// binary parse a left associative level of operators
+func (p *exprParser) binary(next func() (*exprNode, error), match func() string) (*exprNode, error) {
+	left, err := next()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		pos := p.pos
+		op := match()
+		if op == "" {
+			return left, nil
+		}
+		right, err := next()
+		if err != nil {
+			return nil, err
+		}
+		left = &exprNode{Type: exprBinary, Pos: pos, Value: op, Args: []*exprNode{left, right}}
+	}
+}
+
+func (p *exprParser) parseOr() (*exprNode, error) {
+	return p.binary(p.parseAnd, func() string {
+		if p.keyword("or") {
+			return "or"
+		}
+		return ""
+	})
+}
+
+func (p *exprParser) parseAnd() (*exprNode, error) {
+	return p.binary(p.parseEquality, func() string {
+		if p.keyword("and") {
+			return "and"
+		}
+		return ""
+	})
+}
+
+func (p *exprParser) parseEquality() (*exprNode, error) {
+	return p.binary(p.parseRelational, func() string {
+		return p.operator("=", "<>")
+	})
+}
+
+func (p *exprParser) parseRelational() (*exprNode, error) {
+	return p.binary(p.parseAdditive, func() string {
+		// <> belongs to the equality level
+		if strings.HasPrefix(p.src[p.pos:], "<>") {
+			return ""
+		}
+		return p.operator("<", "<=", ">", ">=")
+	})
+}
+
+func (p *exprParser) parseAdditive() (*exprNode, error) {
+	return p.binary(p.parseMultiplicative, func() string {
+		return p.operator("+", "-")
+	})
+}
+
+func (p *exprParser) parseMultiplicative() (*exprNode, error) {
+	return p.binary(p.parseUnary, func() string {
+		return p.operator("*", "/")
+	})
+}
+
+func (p *exprParser) parseUnary() (*exprNode, error) {
+	p.skipSpace()
+	pos := p.pos
+	op := ""
+	if p.keyword("not") {
+		op = "not"
+	} else if p.operator("-") != "" {
+		op = "-"
+	}
+	if op == "" {
+		return p.parsePrimary()
+	}
+	n, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	return &exprNode{Type: exprUnary, Pos: pos, Value: op, Args: []*exprNode{n}}, nil
+}
+
+func (p *exprParser) parsePrimary() (*exprNode, error) {
+	p.skipSpace()
+	if p.pos >= len(p.src) {
+		return nil, p.errorf(p.pos, "unexpected end of expression")
+	}
+	pos := p.pos
+	c := p.src[p.pos]
+
+	switch {
+	case c == '(':
+		p.pos++
+		n, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		p.skipSpace()
+		if p.pos >= len(p.src) || p.src[p.pos] != ')' {
+			return nil, p.errorf(pos, "missing closing )")
+		}
+		p.pos++
+		return &exprNode{Type: exprGroup, Pos: pos, Args: []*exprNode{n}}, nil
+	case c == '"':
+		s, err := p.scanString()
+		if err != nil {
+			return nil, err
+		}
+		return &exprNode{Type: exprString, Pos: pos, Value: s}, nil
+	case c == '{':
+		return p.parseBrace()
+	case c >= '0' && c <= '9' || c == '.':
+		return p.parseNumber()
+	case exprIsIdent(c):
+		return p.parseFunction()
+	}
+	return nil, p.errorf(pos, "unexpected %q", c)
+}
+
+// parseNumber numeric constant, with optional time or size suffix
+func (p *exprParser) parseNumber() (*exprNode, error) {
+	start := p.pos
+	digits := 0
+	for p.pos < len(p.src) && (p.src[p.pos] >= '0' && p.src[p.pos] <= '9' || p.src[p.pos] == '.') {
+		if p.src[p.pos] != '.' {
+			digits++
+		}
+		p.pos++
+	}
+	if digits == 0 || strings.Count(p.src[start:p.pos], ".") > 1 {
+		return nil, p.errorf(start, "invalid number %q", p.src[start:p.pos])
+	}
+	if p.pos+1 < len(p.src) && (p.src[p.pos] == 'e' || p.src[p.pos] == 'E') {
+		e := p.pos + 1
+		if e < len(p.src) && (p.src[e] == '+' || p.src[e] == '-') {
+			e++
+		}
+		if e < len(p.src) && p.src[e] >= '0' && p.src[e] <= '9' {
+			for p.pos = e; p.pos < len(p.src) && p.src[p.pos] >= '0' && p.src[p.pos] <= '9'; p.pos++ {
+			}
+		}
+	}
+	if p.pos < len(p.src) && strings.IndexByte("smhdwKMGT", p.src[p.pos]) >= 0 {
+		p.pos++
+	}
+	if p.pos < len(p.src) && exprIsIdent(p.src[p.pos]) {
+		return nil, p.errorf(start, "invalid number %q", p.src[start:p.pos+1])
+	}
+	return &exprNode{Type: exprNumber, Pos: start, Value: p.src[start:p.pos]}, nil
+}
+
+// scanString double quoted string, returned with quotes and escapes intact
+func (p *exprParser) scanString() (string, error) {
+	start := p.pos
+	for p.pos++; p.pos < len(p.src); p.pos++ {
+		switch p.src[p.pos] {
+		case '\\':
+			p.pos++
+		case '"':
+			p.pos++
+			return p.src[start:p.pos], nil
+		}
+	}
+	return "", p.errorf(start, "unterminated string")
+}
+
+// scanMacro macro including nested macros and quoted contexts, ie {$M:"c"} or {{#M}.regsub("(.*)",\1)}
+func (p *exprParser) scanMacro() (string, error) {
+	start := p.pos
+	depth := 0
+	for ; p.pos < len(p.src); p.pos++ {
+		switch p.src[p.pos] {
+		case '"':
+			if _, err := p.scanString(); err != nil {
+				return "", err
+			}
+			p.pos--
+		case '{':
+			depth++
+		case '}':
+			depth--
+			if depth == 0 {
+				p.pos++
+				return p.src[start:p.pos], nil
+			}
+		}
+	}
+	return "", p.errorf(start, "unterminated macro")
+}
+
+// parseBrace macro or legacy {host:key.func(params)} reference
+func (p *exprParser) parseBrace() (*exprNode, error) {
+	start := p.pos
+	rest := p.src[start+1:]
+
+	// macro host of a legacy reference, ie {{HOST.HOST}:key.last()}
+	if strings.HasPrefix(rest, "{") && !strings.HasPrefix(rest, "{#") {
+		p.pos++
+		host, err := p.scanMacro()
+		if err != nil {
+			return nil, err
+		}
+		if p.pos < len(p.src) && p.src[p.pos] == ':' {
+			return p.parseLegacy(start, host)
+		}
+		p.pos = start
+	}
+
+	end := strings.IndexAny(rest, "}:")
+	isMacro := len(rest) > 0 && (rest[0] == '$' || rest[0] == '#' || rest[0] == '{') ||
+		end >= 0 && rest[end] == '}' && exprMacroName.MatchString(rest[:end])
+	if isMacro {
+		m, err := p.scanMacro()
+		if err != nil {
+			return nil, err
+		}
+		return &exprNode{Type: exprMacro, Pos: start, Value: m}, nil
+	}
+	if end < 0 || rest[end] != ':' {
+		return nil, p.errorf(start, "invalid macro or {host:key.func()} reference")
+	}
+
+	p.pos = start + 1 + end
+	return p.parseLegacy(start, rest[:end])
+}
+
+// parseLegacy remainder of a {host:key.func(params)} reference, positioned on the ':'
+func (p *exprParser) parseLegacy(start int, host string) (*exprNode, error) {
+	if p.version >= expressionVersion {
+		return nil, p.errorf(start, "{host:key.func()} syntax is not supported by zabbix 5.4+, use func(/host/key)")
+	}
+	if host == "" {
+		return nil, p.errorf(start, "missing host")
+	}
+	p.pos++
+
+	keyPos := p.pos
+	key, err := p.scanKey(true)
+	if err != nil {
+		return nil, err
+	}
+	if key == "" {
+		return nil, p.errorf(keyPos, "missing item key")
+	}
+	if p.pos >= len(p.src) || p.src[p.pos] != '.' {
+		return nil, p.errorf(p.pos, "expected .function() after item key")
+	}
+	p.pos++
+
+	funcPos := p.pos
+	for p.pos < len(p.src) && exprIsIdent(p.src[p.pos]) {
+		p.pos++
+	}
+	name := p.src[funcPos:p.pos]
+	if p.pos >= len(p.src) || p.src[p.pos] != '(' {
+		return nil, p.errorf(funcPos, "expected .function() after item key")
+	}
+
+	args, err := p.scanLegacyParams()
+	if err != nil {
+		return nil, err
+	}
+	if p.pos >= len(p.src) || p.src[p.pos] != '}' {
+		return nil, p.errorf(start, "missing closing }")
+	}
+	p.pos++
+
+	fn, ok := EXPRESSION_LEGACY_FUNCTIONS[name]
+	if !ok {
+		log.Warn("unknown function %s() in expression, passed to the server unchecked", name)
+	} else if err := exprCheckArgs(name, len(args), fn); err != nil {
+		return nil, p.errorf(funcPos, "%s", err)
+	}
+
+	return &exprNode{Type: exprFunc, Pos: start, Func: name, Legacy: true, Host: host, Key: key, Args: args}, nil
+}
+
+// scanLegacyParams unquoted or quoted parameters of a legacy function, positioned on the '('
+func (p *exprParser) scanLegacyParams() ([]*exprNode, error) {
+	open := p.pos
+	p.pos++
+	args := []*exprNode{}
+
+	p.skipSpace()
+	if p.pos < len(p.src) && p.src[p.pos] == ')' {
+		p.pos++
+		return args, nil
+	}
+
+	for {
+		p.skipSpace()
+		pos := p.pos
+		var arg *exprNode
+		if p.pos < len(p.src) && p.src[p.pos] == '"' {
+			s, err := p.scanString()
+			if err != nil {
+				return nil, err
+			}
+			arg = &exprNode{Type: exprString, Pos: pos, Value: s}
+			p.skipSpace()
+		} else {
+			for p.pos < len(p.src) && p.src[p.pos] != ',' && p.src[p.pos] != ')' {
+				p.pos++
+			}
+			arg = &exprNode{Type: exprParam, Pos: pos, Value: strings.TrimSpace(p.src[pos:p.pos])}
+		}
+		args = append(args, arg)
+
+		if p.pos >= len(p.src) {
+			return nil, p.errorf(open, "missing closing )")
+		}
+		switch p.src[p.pos] {
+		case ',':
+			p.pos++
+		case ')':
+			p.pos++
+			return args, nil
+		default:
+			return nil, p.errorf(p.pos, "unexpected %q in function parameters", p.src[p.pos])
+		}
+	}
+}
+
+// scanKey item key, bracketed parameters may contain anything but unbalanced brackets,
+// legacy keys end at the .function( and 5.4+ keys at a ',', ')' or '?' filter
+func (p *exprParser) scanKey(legacy bool) (string, error) {
+	start := p.pos
+	for p.pos < len(p.src) {
+		c := p.src[p.pos]
+		if c == '.' && legacy && exprIsCall(p.src[p.pos+1:]) {
+			break
+		}
+		if !exprIsIdent(c) && c != '.' && c != '-' {
+			break
+		}
+		p.pos++
+	}
+	if p.pos < len(p.src) && p.src[p.pos] == '[' {
+		if err := p.scanBrackets(); err != nil {
+			return "", err
+		}
+	}
+	return p.src[start:p.pos], nil
+}
+
+// scanBrackets balanced [...] with quoted strings, positioned on the '['
+func (p *exprParser) scanBrackets() error {
+	start := p.pos
+	depth := 0
+	for ; p.pos < len(p.src); p.pos++ {
+		switch p.src[p.pos] {
+		case '"':
+			if _, err := p.scanString(); err != nil {
+				return err
+			}
+			p.pos--
+		case '[':
+			depth++
+		case ']':
+			depth--
+			if depth == 0 {
+				p.pos++
+				return nil
+			}
+		}
+	}
+	return p.errorf(start, "missing closing ]")
+}
+
+// parseFunction 5.4+ function call, func(/host/key,params) or func(expression,...)
+func (p *exprParser) parseFunction() (*exprNode, error) {
+	start := p.pos
+	for p.pos < len(p.src) && exprIsIdent(p.src[p.pos]) {
+		p.pos++
+	}
+	name := p.src[start:p.pos]
+	if p.pos >= len(p.src) || p.src[p.pos] != '(' {
+		return nil, p.errorf(start, "unexpected %q", name)
+	}
+	if p.version > 0 && p.version < expressionVersion {
+		return nil, p.errorf(start, "function %s() syntax requires zabbix 5.4 or later, use {host:key.%s()}", name, name)
+	}
+	open := p.pos
+	p.pos++
+
+	args := []*exprNode{}
+	p.skipSpace()
+	if p.pos < len(p.src) && p.src[p.pos] == ')' {
+		p.pos++
+	} else {
+		for {
+			arg, err := p.parseArg()
+			if err != nil {
+				return nil, err
+			}
+			args = append(args, arg)
+
+			p.skipSpace()
+			if p.pos >= len(p.src) {
+				return nil, p.errorf(open, "missing closing )")
+			}
+			if p.src[p.pos] == ')' {
+				p.pos++
+				break
+			}
+			if p.src[p.pos] != ',' {
+				return nil, p.errorf(p.pos, "unexpected %q in function parameters", p.src[p.pos])
+			}
+			p.pos++
+		}
+	}
+
+	query := len(args) > 0 && args[0].Type == exprQuery
+	history, isHistory := EXPRESSION_HISTORY_FUNCTIONS[name]
+	math, isMath := EXPRESSION_FUNCTIONS[name]
+	switch {
+	case !isHistory && !isMath:
+		// newer server functions, the server validates them
+		log.Warn("unknown function %s() in expression, passed to the server unchecked", name)
+		return &exprNode{Type: exprFunc, Pos: start, Func: name, Args: args}, nil
+	case query && !isHistory:
+		return nil, p.errorf(args[0].Pos, "%s does not accept an item query", name)
+	case !query && !isMath:
+		return nil, p.errorf(start, "%s expects an item query /host/key as its first parameter", name)
+	case query:
+		math = history
+	}
+	for i := 1; i < len(args); i++ {
+		if args[i].Type == exprQuery {
+			return nil, p.errorf(args[i].Pos, "item query only allowed as the first parameter")
+		}
+	}
+	if err := exprCheckArgs(name, len(args), math); err != nil {
+		return nil, p.errorf(start, "%s", err)
+	}
+
+	return &exprNode{Type: exprFunc, Pos: start, Func: name, Args: args}, nil
+}
+
+// parseArg a 5.4+ function parameter, an item query, expression or period
+func (p *exprParser) parseArg() (*exprNode, error) {
+	p.skipSpace()
+	start := p.pos
+	if p.pos < len(p.src) && p.src[p.pos] == '/' {
+		return p.parseQuery()
+	}
+
+	end := p.scanArg()
+	raw := strings.TrimSpace(p.src[start:end])
+
+	// periods and shifts, ie #5, 1h:now/h-1d or {$PERIOD}:now/d
+	if raw == "" || exprPeriod.MatchString(raw) || strings.Contains(raw, ":now") && preprocessorMacroRef.MatchString(raw) {
+		p.pos = end
+		return &exprNode{Type: exprParam, Pos: start, Value: raw}, nil
+	}
+
+	sub := &exprParser{src: p.src[:end], pos: start, version: p.version}
+	n, err := sub.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	sub.skipSpace()
+	if sub.pos < end {
+		return nil, p.errorf(sub.pos, "unexpected %q in function parameters", p.src[sub.pos])
+	}
+	p.pos = end
+	return n, nil
+}
+
+// scanArg end of a function parameter, the next top level ',' or ')'
+func (p *exprParser) scanArg() int {
+	depth := 0
+	for i := p.pos; i < len(p.src); i++ {
+		switch p.src[i] {
+		case '"':
+			for i++; i < len(p.src) && p.src[i] != '"'; i++ {
+				if p.src[i] == '\\' {
+					i++
+				}
+			}
+		case '(', '[', '{':
+			depth++
+		case ']', '}':
+			depth--
+		case ')':
+			if depth == 0 {
+				return i
+			}
+			depth--
+		case ',':
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return len(p.src)
+}
+
+// parseQuery 5.4+ item query, /host/key with an optional ?[filter]
+func (p *exprParser) parseQuery() (*exprNode, error) {
+	start := p.pos
+	if p.version > 0 && p.version < expressionVersion {
+		return nil, p.errorf(start, "/host/key item queries require zabbix 5.4 or later")
+	}
+	p.pos++
+
+	end := strings.IndexAny(p.src[p.pos:], "/,)")
+	if end < 0 || p.src[p.pos+end] != '/' {
+		return nil, p.errorf(start, "expected /host/key item query")
+	}
+	host := p.src[p.pos : p.pos+end]
+	p.pos += end + 1
+
+	keyPos := p.pos
+	key, err := p.scanKey(false)
+	if err != nil {
+		return nil, err
+	}
+	if key == "" {
+		return nil, p.errorf(keyPos, "missing item key")
+	}
+
+	filter := ""
+	if strings.HasPrefix(p.src[p.pos:], "?[") {
+		p.pos++
+		filterPos := p.pos
+		if err := p.scanBrackets(); err != nil {
+			return nil, err
+		}
+		filter = p.src[filterPos+1 : p.pos-1]
+	}
+
+	p.skipSpace()
+	if p.pos < len(p.src) && p.src[p.pos] != ',' && p.src[p.pos] != ')' {
+		return nil, p.errorf(p.pos, "unexpected %q in item key", p.src[p.pos])
+	}
+
+	return &exprNode{Type: exprQuery, Pos: start, Host: host, Key: key, Filter: filter}, nil
+}
+
+// exprCheckArgs check a parameter count against the accepted range
+func exprCheckArgs(name string, n int, fn exprFunction) error {
+	if n >= fn.min && (fn.max < 0 || n <= fn.max) {
+		return nil
+	}
+	switch {
+	case fn.max < 0:
+		return fmt.Errorf("%s expects at least %d params, got %d", name, fn.min, n)
+	case fn.min == fn.max:
+		return fmt.Errorf("%s expects %d params, got %d", name, fn.min, n)
+	}
+	return fmt.Errorf("%s expects between %d and %d params, got %d", name, fn.min, fn.max, n)
+}
+
+func exprIsIdent(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '_'
+}
+
+// exprIsCall does the string start with a function call, ie last(
+func exprIsCall(s string) bool {
+	i := 0
+	for i < len(s) && exprIsIdent(s[i]) {
+		i++
+	}
+	return i > 0 && i < len(s) && s[i] == '('
+}
+
+// exprWalk visit every node of an expression tree
+func exprWalk(n *exprNode, f func(*exprNode)) {
+	if n == nil {
+		return
+	}
+	f(n)
+	for _, a := range n.Args {
+		exprWalk(a, f)
+	}
+}
+
+// validateTriggerExpression parse a trigger expression for the server version,
+// triggers must reference at least one item
+func validateTriggerExpression(src string, version int) error {
+	n, err := parseExpression(src, version)
+	if err != nil {
+		return err
+	}
+
+	items := false
+	exprWalk(n, func(n *exprNode) {
+		if n.Type == exprQuery || n.Legacy {
+			items = true
+		}
+	})
+	if !items {
+		return fmt.Errorf("expression must reference at least one item")
+	}
+	return nil
+}
+
+// renderExpression render an expression tree, spacing only around word operators
+func renderExpression(n *exprNode) string {
+	args := make([]string, len(n.Args))
+	for i, a := range n.Args {
+		args[i] = renderExpression(a)
+	}
+
+	switch n.Type {
+	case exprQuery:
+		if n.Filter != "" {
+			return "/" + n.Host + "/" + n.Key + "?[" + n.Filter + "]"
+		}
+		return "/" + n.Host + "/" + n.Key
+	case exprFunc:
+		if n.Legacy {
+			return "{" + n.Host + ":" + n.Key + "." + n.Func + "(" + strings.Join(args, ",") + ")}"
+		}
+		return n.Func + "(" + strings.Join(args, ",") + ")"
+	case exprUnary:
+		if n.Value == "not" {
+			return "not " + args[0]
+		}
+		return n.Value + args[0]
+	case exprBinary:
+		if n.Value == "and" || n.Value == "or" {
+			return args[0] + " " + n.Value + " " + args[1]
+		}
+		return args[0] + n.Value + args[1]
+	case exprGroup:
+		return "(" + args[0] + ")"
+	}
+	return n.Value
+}
+
+// convertExpression convert legacy {host:key.func()} references to the 5.4+ syntax,
+// returned in canonical form so equivalent expressions compare equal
+func convertExpression(src string) (string, error) {
+	n, err := parseExpression(src, 0)
+	if err != nil {
+		return "", err
+	}
+	n, err = exprConvert(n)
+	if err != nil {
+		return "", err
+	}
+	return renderExpression(n), nil
+}
+
+// exprConvert replace legacy function references within a tree
+func exprConvert(n *exprNode) (*exprNode, error) {
+	for i, a := range n.Args {
+		c, err := exprConvert(a)
+		if err != nil {
+			return nil, err
+		}
+		n.Args[i] = c
+	}
+	if n.Type != exprFunc || !n.Legacy {
+		return n, nil
+	}
+	return exprConvertLegacy(n)
+}
+
+// exprConvertLegacy 5.4+ equivalent of a legacy function, following the zabbix upgrade conversion
+func exprConvertLegacy(n *exprNode) (*exprNode, error) {
+	arg := func(i int) string {
+		if i >= len(n.Args) {
+			return ""
+		}
+		return exprUnquote(n.Args[i])
+	}
+	param := func(v string) *exprNode {
+		return &exprNode{Type: exprParam, Pos: n.Pos, Value: v}
+	}
+	str := func(v string) *exprNode {
+		return &exprNode{Type: exprString, Pos: n.Pos, Value: exprQuote(v)}
+	}
+	call := func(name string, args ...*exprNode) *exprNode {
+		return &exprNode{Type: exprFunc, Pos: n.Pos, Func: name, Args: args}
+	}
+	group := func(op string, left, right *exprNode) *exprNode {
+		bin := &exprNode{Type: exprBinary, Pos: n.Pos, Value: op, Args: []*exprNode{left, right}}
+		return &exprNode{Type: exprGroup, Pos: n.Pos, Args: []*exprNode{bin}}
+	}
+	query := func() *exprNode {
+		return &exprNode{Type: exprQuery, Pos: n.Pos, Host: n.Host, Key: n.Key}
+	}
+	// last value of the item, optionally the nth or time shifted
+	last := func(name string, period, shift string) *exprNode {
+		if p := exprLastPeriod(period, shift); p != "" {
+			return call(name, query(), param(p))
+		}
+		return call(name, query())
+	}
+
+	switch n.Func {
+	case "abschange":
+		return call("abs", call("change", query())), nil
+	case "avg", "max", "min", "sum":
+		return call(n.Func, query(), param(exprShiftPeriod(arg(0), arg(1)))), nil
+	case "band":
+		return call("bitand", last("last", arg(0), arg(2)), param(arg(1))), nil
+	case "change":
+		return call("change", query()), nil
+	case "count":
+		args := []*exprNode{query(), param(exprShiftPeriod(arg(0), arg(3)))}
+		if arg(1) != "" && arg(2) == "" {
+			// the legacy default operator depends on the item value type
+			return nil, &exprError{Pos: n.Pos, Msg: "count() with a pattern needs an explicit operator to be converted"}
+		}
+		if arg(2) != "" {
+			args = append(args, str(arg(2)))
+		}
+		if arg(1) != "" {
+			args = append(args, str(arg(1)))
+		}
+		return call("count", args...), nil
+	case "date", "dayofmonth", "dayofweek", "now", "time":
+		return call(n.Func), nil
+	case "delta":
+		period := exprShiftPeriod(arg(0), arg(1))
+		return group("-", call("max", query(), param(period)), call("min", query(), param(period))), nil
+	case "diff":
+		return group("<>", call("change", query()), &exprNode{Type: exprNumber, Pos: n.Pos, Value: "0"}), nil
+	case "forecast":
+		args := []*exprNode{query(), param(exprShiftPeriod(arg(0), arg(1))), param(arg(2))}
+		for i := 3; i < len(n.Args); i++ {
+			args = append(args, str(arg(i)))
+		}
+		return call("forecast", args...), nil
+	case "fuzzytime":
+		return call("fuzzytime", query(), param(arg(0))), nil
+	case "iregexp", "regexp", "str":
+		mode := n.Func
+		if mode == "str" {
+			mode = "like"
+		}
+		return call("find", query(), param(arg(1)), str(mode), str(arg(0))), nil
+	case "last":
+		return last("last", arg(0), arg(1)), nil
+	case "logeventid", "logsource":
+		return call(n.Func, query(), param(""), str(arg(0))), nil
+	case "logseverity":
+		return call("logseverity", query()), nil
+	case "nodata":
+		if arg(1) != "" {
+			return call("nodata", query(), param(arg(0)), str(arg(1))), nil
+		}
+		return call("nodata", query(), param(arg(0))), nil
+	case "percentile":
+		return call("percentile", query(), param(exprShiftPeriod(arg(0), arg(1))), param(arg(2))), nil
+	case "prev":
+		return call("last", query(), param("#2")), nil
+	case "strlen":
+		return call("length", last("last", arg(0), arg(1))), nil
+	case "timeleft":
+		args := []*exprNode{query(), param(exprShiftPeriod(arg(0), arg(1))), param(arg(2))}
+		if arg(3) != "" {
+			args = append(args, str(arg(3)))
+		}
+		return call("timeleft", args...), nil
+	case "trendavg", "trendcount", "trendmax", "trendmin", "trendsum":
+		return call(n.Func, query(), param(arg(0)+":"+arg(1))), nil
+	case "trenddelta":
+		period := param(arg(0) + ":" + arg(1))
+		return group("-", call("trendmax", query(), period), call("trendmin", query(), period)), nil
+	}
+	return nil, &exprError{Pos: n.Pos, Msg: fmt.Sprintf("no 5.4+ equivalent for %s()", n.Func)}
+}
+
+// exprShiftPeriod legacy period and time shift, ie 5m,1d to 5m:now-1d
+func exprShiftPeriod(period, shift string) string {
+	if shift == "" || shift == "0" {
+		return period
+	}
+	return period + ":now-" + shift
+}
+
+// exprLastPeriod legacy last() style parameters, seconds are ignored and #1 is the default
+func exprLastPeriod(period, shift string) string {
+	if !strings.HasPrefix(period, "#") || period == "#1" {
+		period = ""
+	}
+	if shift == "" || shift == "0" {
+		return period
+	}
+	if period == "" {
+		period = "#1"
+	}
+	return period + ":now-" + shift
+}
+
+// exprUnquote value of a legacy parameter, only \" is escaped within quotes
+func exprUnquote(n *exprNode) string {
+	if n.Type != exprString {
+		return n.Value
+	}
+	return strings.Replace(n.Value[1:len(n.Value)-1], `\"`, `"`, -1)
+}
+
+// exprQuote 5.4+ string parameter, escaping quotes and backslashes
+func exprQuote(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s) + `"`
+}
+
+// expressionDiffSuppress ignore formatting and legacy/5.4+ syntax differences between equivalent expressions
+func expressionDiffSuppress(k, old, new string, d *schema.ResourceData) bool {
+	if old == new {
+		return true
+	}
+	if old == "" || new == "" {
+		return false
+	}
+	o, err := convertExpression(old)
+	if err != nil {
+		return false
+	}
+	n, err := convertExpression(new)
+	return err == nil && o == n
+}
@@ -0,0 +1,248 @@
+package provider
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/terraform"
+	"github.com/tpretz/go-zabbix-api"
+)
+
+func TestParseExpression(t *testing.T) {
+	cases := []struct {
+		expression string
+		version    int
+		err        string
+	}{
+		// 5.4+ syntax
+		{`last(/Linux/system.cpu.load[percpu,avg1])>5`, 60000, ""},
+		{`avg(/Linux/net.if.in["eth0",bytes],5m) > {$IF.MAX:"eth0"} and last(/Linux/agent.ping)=1`, 60000, ""},
+		{`count(/host/log,1h,"regexp","^ERROR")>=3 or nodata(/host/key,{$NODATA})=1`, 60000, ""},
+		{`min(/host/key,#5:now-1h)<>0`, 60000, ""},
+		{`trendavg(/host/key,1M:now/M)>trendavg(/host/key,1M:now/M-1M)*1.1`, 60000, ""},
+		{`abs(change(/host/key))>10K`, 60000, ""},
+		{`not (last(/host/key)=0) and -last(/host/key)<-1`, 60000, ""},
+		{`avg(last_foreach(/*/vfs.fs.size[*,pused]?[group="Linux"]))>80`, 60000, ""},
+		{`last(/{#HOST}/key[{#IF}])>{{#THRESHOLD}.regsub("([0-9]+)",\1)}`, 60000, ""},
+		{`find(/host/key,,"like","a,b)")=1`, 60000, ""},
+		{`last(/host/key)>0 and time()>080000`, 60000, ""},
+		{`trendstl(/host/key,100h:now/h,10h,2h,2.1,"mad",7)=1`, 60000, ""},
+		{`trendstl(/host/key,100h:now/h,10h,2h)=1`, 60000, ""},
+		{`histogram_quantile(0.75,bucket_rate_foreach(//size[*]?[tag="t"],5m,1))>1`, 60000, ""},
+		{`jsonpath(last(/host/key),"$.a","x")="x"`, 60000, ""},
+
+		// legacy syntax
+		{`{Zabbix server:system.cpu.load[percpu,avg1].last()}>5`, 50000, ""},
+		{`{host:net.if.in[eth0,bytes].avg(5m)}>{$MAX} or {host:agent.ping.nodata(5m)}=1`, 50000, ""},
+		{`{host:log.str("error, fatal")}=1 and {TRIGGER.VALUE}=0`, 50000, ""},
+		{`{{HOST.HOST}:key.last(#3,1h)}<>0`, 50000, ""},
+		{`{h:k.timeleft(1h,,100,"polynomial2")}<1d`, 50000, ""},
+
+		// both accepted while the version is unknown
+		{`{host:key.last()}>0 or last(/host/key)>0`, 0, ""},
+
+		// version mismatch
+		{`{host:key.last()}>0`, 50400, "column 1: {host:key.func()} syntax is not supported by zabbix 5.4+"},
+		{`last(/host/key)>0`, 50200, "column 1: function last() syntax requires zabbix 5.4"},
+
+		// syntax errors
+		{`last(/host/key>0`, 60000, "column 15: unexpected '>' in item key"},
+		{`last(/host/key)>`, 60000, "column 17: unexpected end of expression"},
+		{`(last(/host/key)>0`, 60000, "column 1: missing closing )"},
+		{`{host:key.last()>0`, 50000, "column 1: missing closing }"},
+		{`last(/host/key[a,b)>0`, 60000, "column 15: missing closing ]"},
+		{`count(/host/key,1h,"eq)>0`, 60000, "column 20: unterminated string"},
+		{`last(/host/key)>5x`, 60000, "column 17: invalid number"},
+		{`last(/host/key) 5`, 60000, "column 17: unexpected '5'"},
+		{`last(/host/)>0`, 60000, "column 12: missing item key"},
+
+		// functions and params
+		{`lats(/host/key)>0`, 60000, ""},
+		{`newfunc(last(/host/key),1,2,3)>0`, 60000, ""},
+		{`{host:key.lats()}>0`, 50000, ""},
+		{`last(/host/key,#1,5m)>0`, 60000, "column 1: last expects between 1 and 2 params, got 3"},
+		{`avg(/host/key)>0`, 60000, "column 1: avg expects 2 params, got 1"},
+		{`count(/host/key)>0`, 60000, "column 1: count expects between 2 and 4 params, got 1"},
+		{`trendstl(/host/key,100h:now/h,10h)=1`, 60000, "column 1: trendstl expects between 4 and 7 params, got 3"},
+		{`avg(count_foreach(/*/key?[group="g"]))>0`, 60000, "column 5: count_foreach expects between 2 and 4 params, got 1"},
+		{`avg(avg_foreach(/*/key?[group="g"]))>0`, 60000, "column 5: avg_foreach expects 2 params, got 1"},
+		{`{host:key.avg()}>0`, 50000, "column 11: avg expects between 1 and 2 params, got 0"},
+		{`abs(/host/key)>0`, 60000, "column 5: abs does not accept an item query"},
+		{`nodata(5m)=1`, 60000, "column 1: nodata expects an item query /host/key as its first parameter"},
+		{`max(last(/host/a),/host/b)>0`, 60000, "column 19: item query only allowed as the first parameter"},
+	}
+
+	for _, tc := range cases {
+		_, err := parseExpression(tc.expression, tc.version)
+		switch {
+		case tc.err == "" && err != nil:
+			t.Errorf("%s: unexpected error %s", tc.expression, err)
+		case tc.err != "" && err == nil:
+			t.Errorf("%s: expected error %q", tc.expression, tc.err)
+		case tc.err != "" && !strings.Contains(err.Error(), tc.err):
+			t.Errorf("%s: expected error %q, got %q", tc.expression, tc.err, err)
+		}
+	}
+}
+
+func TestParseExpressionTree(t *testing.T) {
+	n, err := parseExpression(`{host:key[a,"b]"].avg(5m,"x")}>1 or last(/host2/key2?[tag="t"],#2)<2`, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	funcs := []*exprNode{}
+	exprWalk(n, func(n *exprNode) {
+		if n.Type == exprFunc {
+			funcs = append(funcs, n)
+		}
+	})
+	if len(funcs) != 2 {
+		t.Fatalf("expected 2 functions, got %d", len(funcs))
+	}
+
+	legacy := funcs[0]
+	if !legacy.Legacy || legacy.Host != "host" || legacy.Key != `key[a,"b]"]` || legacy.Func != "avg" || len(legacy.Args) != 2 || legacy.Args[1].Value != `"x"` {
+		t.Errorf("unexpected legacy function %+v", legacy)
+	}
+
+	query := funcs[1].Args[0]
+	if query.Type != exprQuery || query.Host != "host2" || query.Key != "key2" || query.Filter != `tag="t"` || funcs[1].Args[1].Value != "#2" {
+		t.Errorf("unexpected query %+v", query)
+	}
+
+	if n.Type != exprBinary || n.Value != "or" {
+		t.Errorf("expected or at the root, got %+v", n)
+	}
+}
+
+func TestValidateTriggerExpression(t *testing.T) {
+	if err := validateTriggerExpression(`{$A}>1`, 60000); err == nil || !strings.Contains(err.Error(), "at least one item") {
+		t.Errorf("expected item reference error, got %v", err)
+	}
+	if err := validateTriggerExpression(`last(/host/key)>{$A}`, 60000); err != nil {
+		t.Errorf("unexpected error %s", err)
+	}
+}
+
+func TestTriggerExpressionCustomizeDiff(t *testing.T) {
+	api52 := &zabbix.API{Config: zabbix.Config{Version: 50200}}
+	api60 := &zabbix.API{Config: zabbix.Config{Version: 60000}}
+
+	cases := []struct {
+		config map[string]interface{}
+		api    *zabbix.API
+		err    string
+	}{
+		{map[string]interface{}{"expression": "last(/host/key)>0"}, api60, ""},
+		{map[string]interface{}{"expression": "{host:key.last()}>0"}, api52, ""},
+		{map[string]interface{}{"expression": "{host:key.last()}>0"}, api60, "expression: column 1:"},
+		{map[string]interface{}{"expression": "last(/host/key)>0", "recovery_expression": "last(/host/key)<"}, api60, "recovery_expression: column 17:"},
+	}
+
+	for i, tc := range cases {
+		tc.config["name"] = "trigger"
+
+		_, err := resourceTrigger().Diff(nil, terraform.NewResourceConfigRaw(tc.config), tc.api)
+		switch {
+		case tc.err == "" && err != nil:
+			t.Errorf("case %d: unexpected error %s", i, err)
+		case tc.err != "" && err == nil:
+			t.Errorf("case %d: expected error %q", i, tc.err)
+		case tc.err != "" && !strings.Contains(err.Error(), tc.err):
+			t.Errorf("case %d: expected error %q, got %q", i, tc.err, err)
+		}
+	}
+}
+
+func TestCalculatedCustomizeDiff(t *testing.T) {
+	config := map[string]interface{}{"hostid": "1", "key": "calc", "name": "calc", "valuetype": "float", "formula": "last(/host/a)+"}
+
+	api60 := &zabbix.API{Config: zabbix.Config{Version: 60000}}
+	if _, err := resourceItemCalculated().Diff(nil, terraform.NewResourceConfigRaw(config), api60); err == nil || !strings.Contains(err.Error(), "formula: column 15") {
+		t.Errorf("expected formula error, got %v", err)
+	}
+
+	// pre 5.4 formulas use a different syntax, not checked
+	api50 := &zabbix.API{Config: zabbix.Config{Version: 50000}}
+	if _, err := resourceItemCalculated().Diff(nil, terraform.NewResourceConfigRaw(config), api50); err != nil {
+		t.Errorf("unexpected error %s", err)
+	}
+}
+
+func TestConvertLegacyExpression(t *testing.T) {
+	cases := []struct {
+		expression string
+		expected   string
+		err        string
+	}{
+		{`{host:key.last()}>5`, `last(/host/key)>5`, ""},
+		{`{host:key.last(0)} > 5`, `last(/host/key)>5`, ""},
+		{`{host:key.last(#3,1d)}<>0`, `last(/host/key,#3:now-1d)<>0`, ""},
+		{`{host:key.last(,1h)}=0`, `last(/host/key,#1:now-1h)=0`, ""},
+		{`{host:net.if.in[eth0,bytes].avg(5m)}>{$MAX}`, `avg(/host/net.if.in[eth0,bytes],5m)>{$MAX}`, ""},
+		{`{host:key.max(#5,1h)}>0`, `max(/host/key,#5:now-1h)>0`, ""},
+		{`{host:key.prev()}=1`, `last(/host/key,#2)=1`, ""},
+		{`{host:key.abschange()}>10`, `abs(change(/host/key))>10`, ""},
+		{`{host:key.diff()}=1`, `(change(/host/key)<>0)=1`, ""},
+		{`{host:key.delta(1h)}>5`, `(max(/host/key,1h)-min(/host/key,1h))>5`, ""},
+		{`{host:key.strlen()}>0`, `length(last(/host/key))>0`, ""},
+		{`{host:log.str(error)}=1`, `find(/host/log,,"like","error")=1`, ""},
+		{`{host:log.regexp("^ERR \"\d+",5m)}=1`, `find(/host/log,5m,"regexp","^ERR \"\\d+")=1`, ""},
+		{`{host:key.band(#1,12)}=8`, `bitand(last(/host/key),12)=8`, ""},
+		{`{host:key.count(10m,error,like)}>2`, `count(/host/key,10m,"like","error")>2`, ""},
+		{`{host:key.count(#10)}>2`, `count(/host/key,#10)>2`, ""},
+		{`{host:key.nodata(5m,strict)}=1`, `nodata(/host/key,5m,"strict")=1`, ""},
+		{`{host:key.trendavg(1h,now/h)}>0`, `trendavg(/host/key,1h:now/h)>0`, ""},
+		{`{host:key.forecast(1h,,30m,polynomial3,max)}>0`, `forecast(/host/key,1h,30m,"polynomial3","max")>0`, ""},
+		{`{host:key.logeventid(^4625$)}=1`, `logeventid(/host/key,,"^4625$")=1`, ""},
+		{`{h:k.timeleft(1h,,100,"polynomial2")}<1d`, `timeleft(/h/k,1h,100,"polynomial2")<1d`, ""},
+		{`{host:key.last()}>0 and {host:key.time()}>080000`, `last(/host/key)>0 and time()>080000`, ""},
+		{`{{HOST.HOST}:key.last()}=0`, `last(/{HOST.HOST}/key)=0`, ""},
+
+		// already 5.4+, only formatting changes
+		{`last( /host/key ) > 5  or  not nodata(/host/key,5m)=1`, `last(/host/key)>5 or not nodata(/host/key,5m)=1`, ""},
+
+		{`{host:key.count(10m,error)}>2`, "", "column 1: count() with a pattern needs an explicit operator"},
+		{`{host:key.last()>0`, "", "missing closing }"},
+	}
+
+	for _, tc := range cases {
+		c, err := convertExpression(tc.expression)
+		switch {
+		case tc.err == "" && err != nil:
+			t.Errorf("%s: unexpected error %s", tc.expression, err)
+		case tc.err != "" && err == nil:
+			t.Errorf("%s: expected error %q", tc.expression, tc.err)
+		case tc.err != "" && !strings.Contains(err.Error(), tc.err):
+			t.Errorf("%s: expected error %q, got %q", tc.expression, tc.err, err)
+		case tc.err == "" && c != tc.expected:
+			t.Errorf("%s: expected %s, got %s", tc.expression, tc.expected, c)
+		case tc.err == "":
+			if _, err := parseExpression(c, 60000); err != nil {
+				t.Errorf("%s: converted %s does not parse: %s", tc.expression, c, err)
+			}
+		}
+	}
+}
+
+func TestExpressionDiffSuppress(t *testing.T) {
+	cases := []struct {
+		old      string
+		new      string
+		suppress bool
+	}{
+		{`last(/host/key)>5`, `{host:key.last()}>5`, true},
+		{`last(/host/key)>5`, `last(/host/key) > 5`, true},
+		{`last(/host/key)>5`, `{host:key.last()}>6`, false},
+		{`last(/host/key)>5`, `{host:key.last(`, false},
+		{`last(/host/key)>5`, ``, false},
+		{``, ``, true},
+	}
+
+	for _, tc := range cases {
+		if expressionDiffSuppress("expression", tc.old, tc.new, nil) != tc.suppress {
+			t.Errorf("%s / %s: expected suppress %t", tc.old, tc.new, tc.suppress)
+		}
+	}
+}
@@ -1,6 +1,7 @@
 package provider
 
 import (
+	"encoding/json"
 	"errors"
 	"fmt"
 	"regexp"
@@ -34,6 +35,89 @@ var ITEM_VALUE_TYPES_ARR = []string{
 	"text",
 }
 
+// host inventory field ids, in zabbix column order
+var ITEM_INVENTORY_LINK = map[string]string{
+	"type":              "1",
+	"type_full":         "2",
+	"name":              "3",
+	"alias":             "4",
+	"os":                "5",
+	"os_full":           "6",
+	"os_short":          "7",
+	"serialno_a":        "8",
+	"serialno_b":        "9",
+	"tag":               "10",
+	"asset_tag":         "11",
+	"macaddress_a":      "12",
+	"macaddress_b":      "13",
+	"hardware":          "14",
+	"hardware_full":     "15",
+	"software":          "16",
+	"software_full":     "17",
+	"software_app_a":    "18",
+	"software_app_b":    "19",
+	"software_app_c":    "20",
+	"software_app_d":    "21",
+	"software_app_e":    "22",
+	"contact":           "23",
+	"location":          "24",
+	"location_lat":      "25",
+	"location_lon":      "26",
+	"notes":             "27",
+	"chassis":           "28",
+	"model":             "29",
+	"hw_arch":           "30",
+	"vendor":            "31",
+	"contract_number":   "32",
+	"installer_name":    "33",
+	"deployment_status": "34",
+	"url_a":             "35",
+	"url_b":             "36",
+	"url_c":             "37",
+	"host_networks":     "38",
+	"host_netmask":      "39",
+	"host_router":       "40",
+	"oob_ip":            "41",
+	"oob_netmask":       "42",
+	"oob_router":        "43",
+	"date_hw_purchase":  "44",
+	"date_hw_install":   "45",
+	"date_hw_expiry":    "46",
+	"date_hw_decomm":    "47",
+	"site_address_a":    "48",
+	"site_address_b":    "49",
+	"site_address_c":    "50",
+	"site_city":         "51",
+	"site_state":        "52",
+	"site_country":      "53",
+	"site_zip":          "54",
+	"site_rack":         "55",
+	"site_notes":        "56",
+	"poc_1_name":        "57",
+	"poc_1_email":       "58",
+	"poc_1_phone_a":     "59",
+	"poc_1_phone_b":     "60",
+	"poc_1_cell":        "61",
+	"poc_1_screen":      "62",
+	"poc_1_notes":       "63",
+	"poc_2_name":        "64",
+	"poc_2_email":       "65",
+	"poc_2_phone_a":     "66",
+	"poc_2_phone_b":     "67",
+	"poc_2_cell":        "68",
+	"poc_2_screen":      "69",
+	"poc_2_notes":       "70",
+}
+var ITEM_INVENTORY_LINK_REV = map[string]string{}
+
+// generate the above structures
+var _ = func() bool {
+	for k, v := range ITEM_INVENTORY_LINK {
+		ITEM_INVENTORY_LINK_REV[v] = k
+	}
+	return false
+}()
+
 // common schema elements for all item types
 var itemCommonSchema = map[string]*schema.Schema{
 	"hostid": &schema.Schema{
@@ -76,6 +160,39 @@ var itemCommonSchema = map[string]*schema.Schema{
 		Description:  "Item Value Type, one of: " + strings.Join(ITEM_VALUE_TYPES_ARR, ", "),
 		Required:     true,
 	},
+	"units": &schema.Schema{
+		Type:        schema.TypeString,
+		Optional:    true,
+		Description: "Item Units",
+	},
+	"description": &schema.Schema{
+		Type:        schema.TypeString,
+		Optional:    true,
+		Description: "Item Description",
+	},
+	"enabled": &schema.Schema{
+		Type:        schema.TypeBool,
+		Optional:    true,
+		Default:     true,
+		Description: "Item enabled",
+	},
+	"inventory_link": &schema.Schema{
+		Type:         schema.TypeString,
+		Optional:     true,
+		Description:  "Host inventory field to populate, not supported on prototypes, one of: " + strings.Join(INVENTORY_KEYS, ", "),
+		ValidateFunc: validation.StringInSlice(INVENTORY_KEYS, false),
+	},
+	"logtimefmt": &schema.Schema{
+		Type:        schema.TypeString,
+		Optional:    true,
+		Description: "Log entry time format, log items only",
+	},
+	"valuemapid": &schema.Schema{
+		Type:         schema.TypeString,
+		Optional:     true,
+		Description:  "Value Map ID",
+		ValidateFunc: validation.StringMatch(regexp.MustCompile("^[0-9]+$"), "must be numeric"),
+	},
 	"preprocessor": itemPreprocessorSchema,
 	"applications": &schema.Schema{
 		Type:        schema.TypeSet,
@@ -137,49 +254,28 @@ var itemPrototypeSchema = map[string]*schema.Schema{
 		ValidateFunc: validation.StringIsNotWhiteSpace,
 		Description:  "LLD Rule ID",
 	},
+	"discover": &schema.Schema{
+		Type:        schema.TypeBool,
+		Optional:    true,
+		Default:     true,
+		Description: "Discover items from this prototype, zabbix 5.0+",
+	},
 }
 
-// Schema for preprocessor blocks
-var itemPreprocessorSchema = &schema.Schema{
-	Type:     schema.TypeList,
-	Optional: true,
-	Elem: &schema.Resource{
-		Schema: map[string]*schema.Schema{
-			"id": &schema.Schema{
-				Type:     schema.TypeString,
-				Computed: true,
-			},
-			"type": &schema.Schema{
-				Type:         schema.TypeString,
-				Required:     true,
-				Description:  "Preprocessor type, zabbix identifier number",
-				ValidateFunc: validation.StringMatch(regexp.MustCompile("^[0-9]+$"), "must be numeric"),
-			},
-			"params": &schema.Schema{
-				Type: schema.TypeList,
-				Elem: &schema.Schema{
-					Type:         schema.TypeString,
-					ValidateFunc: validation.StringIsNotWhiteSpace,
-				},
-				Optional:    true,
-				Description: "Preprocessor parameters",
-			},
-			"error_handler": &schema.Schema{
-				Type:     schema.TypeString,
-				Optional: true,
-				Default:  "",
-			},
-			"error_handler_params": &schema.Schema{
-				Type:     schema.TypeString,
-				Optional: true,
-				Default:  "",
-			},
-		},
+// Timeout schema, per item timeouts from zabbix 7.0
+var itemTimeoutSchema = map[string]*schema.Schema{
+	"timeout": &schema.Schema{
+		Type:        schema.TypeString,
+		Optional:    true,
+		Description: "Item timeout, zabbix 7.0+, unset to use the proxy/global setting",
 	},
 }
 
+// Schema for preprocessor blocks
+var itemPreprocessorSchema = preprocessorSchema()
+
 // Function signature for context manipulation
-type ItemHandler func(*schema.ResourceData, interface{}, *zabbix.Item)
+type ItemHandler func(*schema.ResourceData, interface{}, *apiItem)
 
 // return a terraform CreateFunc
 func itemGetCreateWrapper(c ItemHandler, r ItemHandler) schema.CreateFunc {
@@ -219,7 +315,7 @@ func protoItemGetReadWrapper(r ItemHandler) schema.ReadFunc {
 
 // Create Item Resource Handler
 func resourceItemCreate(d *schema.ResourceData, m interface{}, c ItemHandler, r ItemHandler, prototype bool) error {
-	api := m.(*zabbix.API)
+	api := metaAPI(m)
 
 	item := buildItemObject(d, api, prototype)
 
@@ -228,15 +324,9 @@ func resourceItemCreate(d *schema.ResourceData, m interface{}, c ItemHandler, r
 
 	log.Trace("preparing item object for create/update: %#v", item)
 
-	items := []zabbix.Item{*item}
+	items := apiItems{*item}
 
-	var err error
-
-	if prototype {
-		err = api.ProtoItemsCreate(items)
-	} else {
-		err = api.ItemsCreate(items)
-	}
+	err := itemsCreate(api, items, prototype)
 
 	if err != nil {
 		return err
@@ -251,7 +341,7 @@ func resourceItemCreate(d *schema.ResourceData, m interface{}, c ItemHandler, r
 
 // Update Item Resource Handler
 func resourceItemUpdate(d *schema.ResourceData, m interface{}, c ItemHandler, r ItemHandler, prototype bool) error {
-	api := m.(*zabbix.API)
+	api := metaAPI(m)
 
 	item := buildItemObject(d, api, prototype)
 	item.ItemID = d.Id()
@@ -261,15 +351,9 @@ func resourceItemUpdate(d *schema.ResourceData, m interface{}, c ItemHandler, r
 
 	log.Trace("preparing item object for create/update: %#v", item)
 
-	items := []zabbix.Item{*item}
+	items := apiItems{*item}
 
-	var err error
-
-	if prototype {
-		err = api.ProtoItemsUpdate(items)
-	} else {
-		err = api.ItemsUpdate(items)
-	}
+	err := itemsUpdate(api, items, prototype)
 
 	if err != nil {
 		return err
@@ -280,13 +364,10 @@ func resourceItemUpdate(d *schema.ResourceData, m interface{}, c ItemHandler, r
 
 // Read Item Resource Handler
 func resourceItemRead(d *schema.ResourceData, m interface{}, r ItemHandler, prototype bool) error {
-	api := m.(*zabbix.API)
+	api := metaAPI(m)
 
 	log.Debug("Lookup of item with id %s", d.Id())
 
-	var items zabbix.Items
-	var err error
-
 	params := zabbix.Params{
 		"itemids":             []string{d.Id()},
 		"selectPreprocessing": "extend",
@@ -296,11 +377,10 @@ func resourceItemRead(d *schema.ResourceData, m interface{}, r ItemHandler, prot
 
 	if prototype {
 		params["selectDiscoveryRule"] = "extend"
-		items, err = api.ProtoItemsGet(params)
-	} else {
-		items, err = api.ItemsGet(params)
 	}
 
+	items, err := itemsGet(api, params, prototype)
+
 	if err != nil {
 		return err
 	}
@@ -323,10 +403,26 @@ func resourceItemRead(d *schema.ResourceData, m interface{}, r ItemHandler, prot
 	d.Set("history", item.History)
 	d.Set("trends", item.Trends)
 	d.Set("valuetype", ITEM_VALUE_TYPES_REV[item.ValueType])
-	d.Set("preprocessor", flattenItemPreprocessors(item))
+	d.Set("preprocessor", flattenItemPreprocessors(item.Item))
+	d.Set("units", item.Units)
+	d.Set("description", item.Description)
+	d.Set("enabled", item.Status != "1")
+	d.Set("inventory_link", ITEM_INVENTORY_LINK_REV[item.InventoryLink])
+	d.Set("logtimefmt", item.LogTimeFmt)
+	if item.ValueMapID != "0" {
+		d.Set("valuemapid", item.ValueMapID)
+	} else {
+		d.Set("valuemapid", "")
+	}
+	if api.Config.Version >= 70000 {
+		d.Set("timeout", derefString(item.Timeout))
+	}
 	if prototype && item.DiscoveryRule != nil {
 		d.Set("ruleid", item.DiscoveryRule.ItemID)
 	}
+	if prototype && api.Config.Version >= 50000 {
+		d.Set("discover", item.Discover != "1")
+	}
 
 	applicationSet := schema.NewSet(schema.HashString, []interface{}{})
 	for _, v := range item.Applications {
@@ -342,22 +438,40 @@ func resourceItemRead(d *schema.ResourceData, m interface{}, r ItemHandler, prot
 }
 
 // Build the base Item Object
-func buildItemObject(d *schema.ResourceData, api *zabbix.API, prototype bool) *zabbix.Item {
-	item := zabbix.Item{
+func buildItemObject(d *schema.ResourceData, api *zabbix.API, prototype bool) *apiItem {
+	item := apiItem{Item: zabbix.Item{
 		Key:       d.Get("key").(string),
 		HostID:    d.Get("hostid").(string),
 		Name:      d.Get("name").(string),
 		History:   d.Get("history").(string),
 		Trends:    d.Get("trends").(string),
 		ValueType: ITEM_VALUE_TYPES[d.Get("valuetype").(string)],
-	}
+	}}
 	item.Preprocessors = itemGeneratePreprocessors(d)
-	apps := d.Get("applications").(*schema.Set).List()
-	lst := []string{}
-	for _, a := range apps {
-		lst = append(lst, a.(string))
+	item.Description = d.Get("description").(string)
+	item.Units = d.Get("units").(string)
+	item.LogTimeFmt = d.Get("logtimefmt").(string)
+	item.Status = "0"
+	if !d.Get("enabled").(bool) {
+		item.Status = "1"
+	}
+	item.ValueMapID = "0"
+	if v, ok := d.GetOk("valuemapid"); ok {
+		item.ValueMapID = v.(string)
+	}
+	// timeouts were type specific before 7.0, from 7.0 empty resets to the proxy/global setting
+	if v, ok := d.Get("timeout").(string); ok && (v != "" || api.Config.Version >= 70000) {
+		item.Timeout = &v
+	}
+	// applications removed in 5.4
+	if api.Config.Version < 50400 {
+		apps := d.Get("applications").(*schema.Set).List()
+		lst := []string{}
+		for _, a := range apps {
+			lst = append(lst, a.(string))
+		}
+		item.Applications = lst
 	}
-	item.Applications = lst
 	item.Tags = tagGenerate(d)
 
 	if v, ok := d.GetOk("trends"); ok {
@@ -375,28 +489,34 @@ func buildItemObject(d *schema.ResourceData, api *zabbix.API, prototype bool) *z
 
 	if prototype {
 		item.RuleID = d.Get("ruleid").(string)
+		if api.Config.Version >= 50000 {
+			item.Discover = "0"
+			if !d.Get("discover").(bool) {
+				item.Discover = "1"
+			}
+		}
+	} else {
+		item.InventoryLink = "0"
+		if v, ok := d.GetOk("inventory_link"); ok {
+			item.InventoryLink = ITEM_INVENTORY_LINK[v.(string)]
+		}
 	}
 
 	return &item
 }
 
 // Generate preprocessor objects
-func itemGeneratePreprocessors(d *schema.ResourceData) (preprocessors zabbix.Preprocessors) {
+func itemGeneratePreprocessors(d resourceGetter) (preprocessors zabbix.Preprocessors) {
 	preprocessorCount := d.Get("preprocessor.#").(int)
 	preprocessors = make(zabbix.Preprocessors, preprocessorCount)
 
 	for i := 0; i < preprocessorCount; i++ {
 		prefix := fmt.Sprintf("preprocessor.%d.", i)
-		params := d.Get(prefix + "params").([]interface{})
-		pstrarr := make([]string, len(params))
-		for i := 0; i < len(params); i++ {
-			pstrarr[i] = params[i].(string)
-		}
 
 		preprocessors[i] = zabbix.Preprocessor{
-			Type:               d.Get(prefix + "type").(string),
-			Params:             strings.Join(pstrarr, "\n"),
-			ErrorHandler:       d.Get(prefix + "error_handler").(string),
+			Type:               preprocessorTypeId(d.Get(prefix + "type").(string)),
+			Params:             strings.Join(preprocessorStepParams(d, prefix), "\n"),
+			ErrorHandler:       preprocessorErrorHandlerId(d.Get(prefix + "error_handler").(string)),
 			ErrorHandlerParams: d.Get(prefix + "error_handler_params").(string),
 		}
 	}
@@ -410,8 +530,8 @@ func flattenItemPreprocessors(item zabbix.Item) []interface{} {
 	for i := 0; i < len(item.Preprocessors); i++ {
 		val[i] = map[string]interface{}{
 			//"id": host.Interfaces[i].InterfaceID,
-			"type":                 item.Preprocessors[i].Type,
-			"error_handler":        item.Preprocessors[i].ErrorHandler,
+			"type":                 preprocessorTypeName(item.Preprocessors[i].Type),
+			"error_handler":        preprocessorErrorHandlerName(item.Preprocessors[i].ErrorHandler),
 			"error_handler_params": item.Preprocessors[i].ErrorHandlerParams,
 		}
 		if item.Preprocessors[i].Params != "" {
@@ -421,12 +541,177 @@ func flattenItemPreprocessors(item zabbix.Item) []interface{} {
 	return val
 }
 
+// apiItem api item, extended with attributes not handled by the api library
+type apiItem struct {
+	zabbix.Item
+
+	IpmiSensor  string `json:"ipmi_sensor,omitempty"`
+	JmxEndpoint string `json:"jmx_endpoint,omitempty"`
+	PublicKey   string `json:"publickey,omitempty"`
+	PrivateKey  string `json:"privatekey,omitempty"`
+
+	Parameters    itemParameters  `json:"-"`
+	RawParameters json.RawMessage `json:"parameters,omitempty"`
+
+	// empty timeout sent to reset to the proxy/global setting
+	Timeout *string `json:"timeout,omitempty"`
+
+	Units         string `json:"units"`
+	Status        string `json:"status"`
+	InventoryLink string `json:"inventory_link,omitempty"`
+	LogTimeFmt    string `json:"logtimefmt"`
+	AllowTraps    string `json:"allow_traps,omitempty"`
+	ValueMapID    string `json:"valuemapid,omitempty"`
+	Discover      string `json:"discover,omitempty"`
+}
+
+type apiItems []apiItem
+
+// itemParameter named script item parameter
+type itemParameter struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+type itemParameters []itemParameter
+
+// itemsGet wrapper for item.get / itemprototype.get
+func itemsGet(api *zabbix.API, params zabbix.Params, prototype bool) (res apiItems, err error) {
+	method := "item.get"
+	if prototype {
+		method = "itemprototype.get"
+	}
+	if _, present := params["output"]; !present {
+		params["output"] = "extend"
+	}
+	err = api.CallWithErrorParse(method, params, &res)
+	if err != nil {
+		return
+	}
+
+	// unbox applications (objects on get), headers and parameters
+	for i := 0; i < len(res); i++ {
+		res[i].Headers = zabbix.HttpHeaders{}
+
+		if raw := string(res[i].RawApplications); raw != "" && raw != "[]" {
+			var applications zabbix.Applications
+			if err = json.Unmarshal(res[i].RawApplications, &applications); err != nil {
+				return
+			}
+			ids := []string{}
+			for _, a := range applications {
+				ids = append(ids, a.ApplicationID)
+			}
+			res[i].Applications = ids
+		}
+
+		if raw := string(res[i].RawHeaders); raw != "" && raw != "[]" {
+			if err = json.Unmarshal(res[i].RawHeaders, &res[i].Headers); err != nil {
+				return
+			}
+		}
+
+		if err = unboxItemParameters(res[i].RawParameters, &res[i].Parameters); err != nil {
+			return
+		}
+	}
+	return
+}
+
+// unboxItemParameters parse a returned parameters list
+func unboxItemParameters(raw json.RawMessage, params *itemParameters) error {
+	*params = itemParameters{}
+	if len(raw) == 0 || string(raw) == "null" {
+		return nil
+	}
+	return json.Unmarshal(raw, params)
+}
+
+// handle manual marshal of applications, headers and parameters
+func prepItems(items apiItems) {
+	for i := 0; i < len(items); i++ {
+		if items[i].Applications != nil {
+			asB, _ := json.Marshal(items[i].Applications)
+			items[i].RawApplications = json.RawMessage(asB)
+		}
+		if items[i].Headers != nil {
+			asB, _ := json.Marshal(items[i].Headers)
+			items[i].RawHeaders = json.RawMessage(asB)
+		}
+		if items[i].Parameters != nil {
+			asB, _ := json.Marshal(items[i].Parameters)
+			items[i].RawParameters = json.RawMessage(asB)
+		}
+	}
+}
+
+// itemsCreate wrapper for item.create / itemprototype.create
+func itemsCreate(api *zabbix.API, items apiItems, prototype bool) (err error) {
+	method := "item.create"
+	if prototype {
+		method = "itemprototype.create"
+	}
+	prepItems(items)
+	response, err := api.CallWithError(method, items)
+	if err != nil {
+		return
+	}
+
+	result := response.Result.(map[string]interface{})
+	itemids := result["itemids"].([]interface{})
+	for i, id := range itemids {
+		items[i].ItemID = id.(string)
+	}
+	return
+}
+
+// itemsUpdate wrapper for item.update / itemprototype.update
+func itemsUpdate(api *zabbix.API, items apiItems, prototype bool) (err error) {
+	method := "item.update"
+	if prototype {
+		method = "itemprototype.update"
+	}
+	prepItems(items)
+	_, err = api.CallWithError(method, items)
+	return
+}
+
 // Delete Item Resource Handler
 func resourceItemDelete(d *schema.ResourceData, m interface{}) error {
-	api := m.(*zabbix.API)
+	api := metaAPI(m)
 	return api.ItemsDeleteByIds([]string{d.Id()})
 }
 func resourceProtoItemDelete(d *schema.ResourceData, m interface{}) error {
-	api := m.(*zabbix.API)
+	api := metaAPI(m)
 	return api.ProtoItemsDeleteByIds([]string{d.Id()})
 }
+
+// itemCustomizeDiff plan time checks common to items and item prototypes
+func itemCustomizeDiff(d *schema.ResourceDiff, m interface{}) error {
+	api := metaAPI(m)
+
+	if err := preprocessorCustomizeDiff(d, m); err != nil {
+		return err
+	}
+
+	// ruleid only present on prototypes
+	if _, prototype := d.Get("ruleid").(string); prototype {
+		if d.Get("inventory_link").(string) != "" {
+			return errors.New("inventory_link is not supported on item prototypes")
+		}
+		if !d.Get("discover").(bool) && api.Config.Version < 50000 {
+			return errors.New("discover requires zabbix 5.0 or later")
+		}
+	}
+	return nil
+}
+
+// itemTimeoutCustomizeDiff per item timeouts only exist from 7.0
+func itemTimeoutCustomizeDiff(d *schema.ResourceDiff, m interface{}) error {
+	api := metaAPI(m)
+
+	if v, ok := d.GetOk("timeout"); ok && v.(string) != "" && api.Config.Version < 70000 {
+		return errors.New("timeout requires zabbix 7.0 or later")
+	}
+	return nil
+}
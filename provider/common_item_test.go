@@ -0,0 +1,99 @@
+package provider
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/terraform"
+	"github.com/tpretz/go-zabbix-api"
+)
+
+func TestItemInventoryLink(t *testing.T) {
+	if len(ITEM_INVENTORY_LINK) != len(INVENTORY_KEYS) {
+		t.Errorf("expected %d inventory links, got %d", len(INVENTORY_KEYS), len(ITEM_INVENTORY_LINK))
+	}
+	for _, k := range INVENTORY_KEYS {
+		if _, ok := ITEM_INVENTORY_LINK[k]; !ok {
+			t.Errorf("missing inventory link for %s", k)
+		}
+	}
+	if ITEM_INVENTORY_LINK["type"] != "1" || ITEM_INVENTORY_LINK["os_short"] != "7" || ITEM_INVENTORY_LINK["poc_2_notes"] != "70" {
+		t.Error("inventory link ids do not follow zabbix column order")
+	}
+}
+
+func TestItemApplications(t *testing.T) {
+	d := resourceItemTrapper().Data(nil)
+	d.Set("applications", []interface{}{"4567"})
+
+	cases := []struct {
+		version  int
+		expected string
+	}{
+		{40400, `"applications":["4567"]`},
+		{50400, ""},
+	}
+	for _, tc := range cases {
+		items := apiItems{*buildItemObject(d, &zabbix.API{Config: zabbix.Config{Version: tc.version}}, false)}
+		prepItems(items)
+		b, _ := json.Marshal(items[0])
+		if tc.expected == "" && strings.Contains(string(b), `"applications"`) {
+			t.Errorf("%d: unexpected applications in %s", tc.version, b)
+		}
+		if tc.expected != "" && !strings.Contains(string(b), tc.expected) {
+			t.Errorf("%d: expected %s in %s", tc.version, tc.expected, b)
+		}
+	}
+
+	// an empty set clears applications
+	d.Set("applications", []interface{}{})
+	items := apiItems{*buildItemObject(d, &zabbix.API{Config: zabbix.Config{Version: 40400}}, false)}
+	prepItems(items)
+	if b, _ := json.Marshal(items[0]); !strings.Contains(string(b), `"applications":[]`) {
+		t.Errorf("expected empty applications in %s", b)
+	}
+}
+
+func TestItemTimeout(t *testing.T) {
+	cases := []struct {
+		resource string
+		version  int
+		timeout  string
+		expected string
+	}{
+		{"agent", 70000, "10s", `"timeout":"10s"`},
+		{"agent", 70000, "", `"timeout":""`},
+		{"agent", 60000, "", ""},
+		{"http", 60000, "3s", `"timeout":"3s"`},
+		{"http", 70000, "", `"timeout":""`},
+	}
+	for _, tc := range cases {
+		d := resourceItemAgent().Data(nil)
+		if tc.resource == "http" {
+			d = resourceItemHttp().Data(nil)
+		}
+		d.Set("timeout", tc.timeout)
+		b, _ := json.Marshal(buildItemObject(d, &zabbix.API{Config: zabbix.Config{Version: tc.version}}, false))
+		if tc.expected == "" && strings.Contains(string(b), `"timeout"`) {
+			t.Errorf("%s %d: unexpected timeout in %s", tc.resource, tc.version, b)
+		}
+		if tc.expected != "" && !strings.Contains(string(b), tc.expected) {
+			t.Errorf("%s %d: expected %s in %s", tc.resource, tc.version, tc.expected, b)
+		}
+	}
+
+	// removing the timeout from config resets it
+	api := &zabbix.API{Config: zabbix.Config{Version: 70000}}
+	state := &terraform.InstanceState{ID: "1", Attributes: map[string]string{
+		"hostid": "1", "key": "k", "name": "item", "valuetype": "unsigned", "timeout": "10s",
+	}}
+	config := map[string]interface{}{"hostid": "1", "key": "k", "name": "item", "valuetype": "unsigned"}
+	diff, err := resourceItemAgent().Diff(state, terraform.NewResourceConfigRaw(config), api)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff == nil || diff.Attributes["timeout"] == nil || diff.Attributes["timeout"].New != "" {
+		t.Errorf("expected timeout to be reset, got %+v", diff)
+	}
+}
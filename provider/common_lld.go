@@ -1,6 +1,7 @@
 package provider
 
 import (
+	"encoding/json"
 	"errors"
 	"fmt"
 	"regexp"
@@ -33,8 +34,16 @@ var _ = func() bool {
 
 // operator
 var LLD_OPERATOR = map[string]zabbix.LLDOperatorType{
-	"match":    zabbix.LLDMatch,
-	"notmatch": zabbix.LLDNotMatch,
+	"match":     zabbix.LLDMatch,
+	"notmatch":  zabbix.LLDNotMatch,
+	"exists":    zabbix.LLDOperatorType("12"),
+	"notexists": zabbix.LLDOperatorType("13"),
+}
+
+// operators comparing against a value, exists / notexists take none
+var LLD_OPERATOR_VALUE = map[string]bool{
+	"match":    true,
+	"notmatch": true,
 }
 
 var LLD_OPERATOR_REV = map[zabbix.LLDOperatorType]string{}
@@ -49,6 +58,24 @@ var _ = func() bool {
 	return false
 }()
 
+// lifetime type, delete / disable lost resources, zabbix 7.0+
+var LLD_LIFETIME_TYPE = map[string]string{
+	"after":       "0",
+	"never":       "1",
+	"immediately": "2",
+}
+var LLD_LIFETIME_TYPE_REV = map[string]string{}
+var LLD_LIFETIME_TYPE_ARR = []string{}
+
+// generate the above structures
+var _ = func() bool {
+	for k, v := range LLD_LIFETIME_TYPE {
+		LLD_LIFETIME_TYPE_REV[v] = k
+		LLD_LIFETIME_TYPE_ARR = append(LLD_LIFETIME_TYPE_ARR, k)
+	}
+	return false
+}()
+
 // common schema elements for all lld types
 var lldCommonSchema = map[string]*schema.Schema{
 	"hostid": &schema.Schema{
@@ -72,6 +99,27 @@ var lldCommonSchema = map[string]*schema.Schema{
 		Default:      "30d",
 		Description:  "LLD Stale Item Lifetime",
 	},
+	"lifetime_type": &schema.Schema{
+		Type:         schema.TypeString,
+		Optional:     true,
+		ValidateFunc: validation.StringInSlice(LLD_LIFETIME_TYPE_ARR, false),
+		Default:      "after",
+		Description:  "Delete lost resources, one of: " + strings.Join(LLD_LIFETIME_TYPE_ARR, ", ") + " (zabbix 7.0+)",
+	},
+	"enabled_lifetime_type": &schema.Schema{
+		Type:         schema.TypeString,
+		Optional:     true,
+		ValidateFunc: validation.StringInSlice(LLD_LIFETIME_TYPE_ARR, false),
+		Default:      "never",
+		Description:  "Disable lost resources, one of: " + strings.Join(LLD_LIFETIME_TYPE_ARR, ", ") + " (zabbix 7.0+)",
+	},
+	"enabled_lifetime": &schema.Schema{
+		Type:         schema.TypeString,
+		Optional:     true,
+		ValidateFunc: validation.StringIsNotWhiteSpace,
+		Default:      "0",
+		Description:  "Period after which lost resources are disabled, with enabled_lifetime_type after (zabbix 7.0+)",
+	},
 	"key": &schema.Schema{
 		Type:         schema.TypeString,
 		Description:  "LLD KEY",
@@ -84,9 +132,21 @@ var lldCommonSchema = map[string]*schema.Schema{
 		ValidateFunc: validation.StringIsNotWhiteSpace,
 		Required:     true,
 	},
+	"description": &schema.Schema{
+		Type:        schema.TypeString,
+		Optional:    true,
+		Description: "LLD Description",
+	},
+	"enabled": &schema.Schema{
+		Type:        schema.TypeBool,
+		Optional:    true,
+		Default:     true,
+		Description: "LLD enabled",
+	},
 	"preprocessor": lldPreprocessorSchema,
 	"condition":    lldFilterConditionSchema,
 	"macro":        lldMacroPathSchema,
+	"override":     lldOverrideSchema,
 	"evaltype": &schema.Schema{
 		Type:         schema.TypeString,
 		Description:  "EvalType, one of: " + strings.Join(LLD_EVALTYPE_ARR, ", "),
@@ -102,6 +162,23 @@ var lldCommonSchema = map[string]*schema.Schema{
 	},
 }
 
+// Prototype schema, nested discovery from zabbix 7.4
+var lldPrototypeSchema = map[string]*schema.Schema{
+	"ruleid": &schema.Schema{
+		Type:         schema.TypeString,
+		Required:     true,
+		ForceNew:     true,
+		ValidateFunc: validation.StringIsNotWhiteSpace,
+		Description:  "Parent LLD Rule ID",
+	},
+	"discover": &schema.Schema{
+		Type:        schema.TypeBool,
+		Optional:    true,
+		Default:     true,
+		Description: "Discover LLD rules from this prototype",
+	},
+}
+
 // Interface schema
 var lldInterfaceSchema = map[string]*schema.Schema{
 	"interfaceid": &schema.Schema{
@@ -113,43 +190,7 @@ var lldInterfaceSchema = map[string]*schema.Schema{
 }
 
 // Schema for preprocessor blocks
-var lldPreprocessorSchema = &schema.Schema{
-	Type:     schema.TypeList,
-	Optional: true,
-	Elem: &schema.Resource{
-		Schema: map[string]*schema.Schema{
-			"id": &schema.Schema{
-				Type:     schema.TypeString,
-				Computed: true,
-			},
-			"type": &schema.Schema{
-				Type:         schema.TypeString,
-				Required:     true,
-				Description:  "Preprocessor type, zabbix identifier number",
-				ValidateFunc: validation.StringMatch(regexp.MustCompile("^[0-9]+$"), "must be numeric"),
-			},
-			"params": &schema.Schema{
-				Type: schema.TypeList,
-				Elem: &schema.Schema{
-					Type:         schema.TypeString,
-					ValidateFunc: validation.StringIsNotWhiteSpace,
-				},
-				Optional:    true,
-				Description: "Preprocessor parameters",
-			},
-			"error_handler": &schema.Schema{
-				Type:     schema.TypeString,
-				Optional: true,
-				Default:  "",
-			},
-			"error_handler_params": &schema.Schema{
-				Type:     schema.TypeString,
-				Optional: true,
-				Default:  "",
-			},
-		},
-	},
-}
+var lldPreprocessorSchema = preprocessorSchema()
 
 var lldValidationMacro = validation.StringMatch(regexp.MustCompile("^\\{#[A-Z][A-Z._]*\\}$"), "must be a LLD macro format")
 
@@ -184,6 +225,13 @@ var lldFilterConditionSchema = &schema.Schema{
 				Type:     schema.TypeString,
 				Computed: true,
 			},
+			"formulaid": &schema.Schema{
+				Type:         schema.TypeString,
+				Optional:     true,
+				Computed:     true,
+				Description:  "Condition ID referenced by a custom formula, assigned by zabbix otherwise",
+				ValidateFunc: validation.StringMatch(regexp.MustCompile("^[A-Z]+$"), "must be upper case letters"),
+			},
 			"macro": &schema.Schema{
 				Type:         schema.TypeString,
 				Required:     true,
@@ -191,10 +239,10 @@ var lldFilterConditionSchema = &schema.Schema{
 				ValidateFunc: lldValidationMacro,
 			},
 			"value": &schema.Schema{
-				Type:         schema.TypeString,
-				Required:     true,
-				Description:  "Filter Value",
-				ValidateFunc: validation.StringIsNotWhiteSpace,
+				Type:        schema.TypeString,
+				Optional:    true,
+				Default:     "",
+				Description: "Filter Value, required by match and notmatch",
 			},
 			"operator": &schema.Schema{
 				Type:         schema.TypeString,
@@ -208,43 +256,66 @@ var lldFilterConditionSchema = &schema.Schema{
 }
 
 // Function signature for context manipulation
-type LLDHandler func(*schema.ResourceData, interface{}, *zabbix.LLDRule)
+type LLDHandler func(*schema.ResourceData, interface{}, *apiLLDRule)
 
 // return a terraform CreateFunc
 func lldGetCreateWrapper(c LLDHandler, r LLDHandler) schema.CreateFunc {
 	return func(d *schema.ResourceData, m interface{}) error {
-		return resourceLLDCreate(d, m, c, r)
+		return resourceLLDCreate(d, m, c, r, false)
+	}
+}
+func protoLLDGetCreateWrapper(c LLDHandler, r LLDHandler) schema.CreateFunc {
+	return func(d *schema.ResourceData, m interface{}) error {
+		return resourceLLDCreate(d, m, c, r, true)
 	}
 }
 
 // return a terraform UpdateFunc
 func lldGetUpdateWrapper(c LLDHandler, r LLDHandler) schema.UpdateFunc {
 	return func(d *schema.ResourceData, m interface{}) error {
-		return resourceLLDUpdate(d, m, c, r)
+		return resourceLLDUpdate(d, m, c, r, false)
+	}
+}
+func protoLLDGetUpdateWrapper(c LLDHandler, r LLDHandler) schema.UpdateFunc {
+	return func(d *schema.ResourceData, m interface{}) error {
+		return resourceLLDUpdate(d, m, c, r, true)
 	}
 }
 
 // return a terraform ReadFunc
 func lldGetReadWrapper(r LLDHandler) schema.ReadFunc {
 	return func(d *schema.ResourceData, m interface{}) error {
-		return resourceLLDRead(d, m, r)
+		return resourceLLDRead(d, m, r, false)
+	}
+}
+func protoLLDGetReadWrapper(r LLDHandler) schema.ReadFunc {
+	return func(d *schema.ResourceData, m interface{}) error {
+		return resourceLLDRead(d, m, r, true)
 	}
 }
 
 // Create lld Resource Handler
-func resourceLLDCreate(d *schema.ResourceData, m interface{}, c LLDHandler, r LLDHandler) error {
-	api := m.(*zabbix.API)
+func resourceLLDCreate(d *schema.ResourceData, m interface{}, c LLDHandler, r LLDHandler, prototype bool) error {
+	api := metaAPI(m)
 
-	lld := buildLLDObject(d)
+	lld := buildLLDObject(d, prototype)
+
+	if api.Config.Version >= 50000 {
+		overrides := lldGenerateOverrides(d)
+		lld.Overrides = &overrides
+	}
+	if api.Config.Version >= 70000 {
+		lldGenerateLifetime(d, lld)
+	}
 
 	// run custom function
 	c(d, m, lld)
 
 	log.Trace("preparing lld object for create/update: %#v", lld)
 
-	llds := []zabbix.LLDRule{*lld}
+	llds := apiLLDRules{*lld}
 
-	err := api.LLDsCreate(llds)
+	err := lldsCreate(api, llds, prototype)
 
 	if err != nil {
 		return err
@@ -254,44 +325,60 @@ func resourceLLDCreate(d *schema.ResourceData, m interface{}, c LLDHandler, r LL
 
 	d.SetId(llds[0].ItemID)
 
-	return resourceLLDRead(d, m, r)
+	return resourceLLDRead(d, m, r, prototype)
 }
 
 // Update lld Resource Handler
-func resourceLLDUpdate(d *schema.ResourceData, m interface{}, c LLDHandler, r LLDHandler) error {
-	api := m.(*zabbix.API)
+func resourceLLDUpdate(d *schema.ResourceData, m interface{}, c LLDHandler, r LLDHandler, prototype bool) error {
+	api := metaAPI(m)
 
-	lld := buildLLDObject(d)
+	lld := buildLLDObject(d, prototype)
 	lld.ItemID = d.Id()
 
+	if api.Config.Version >= 50000 {
+		overrides := lldGenerateOverrides(d)
+		lld.Overrides = &overrides
+	}
+	if api.Config.Version >= 70000 {
+		lldGenerateLifetime(d, lld)
+	}
+
 	// run custom function
 	c(d, m, lld)
 
 	log.Trace("preparing lld object for create/update: %#v", lld)
 
-	llds := []zabbix.LLDRule{*lld}
+	llds := apiLLDRules{*lld}
 
-	err := api.LLDsUpdate(llds)
+	err := lldsUpdate(api, llds, prototype)
 
 	if err != nil {
 		return err
 	}
 
-	return resourceLLDRead(d, m, r)
+	return resourceLLDRead(d, m, r, prototype)
 }
 
 // Read lld Resource Handler
-func resourceLLDRead(d *schema.ResourceData, m interface{}, r LLDHandler) error {
-	api := m.(*zabbix.API)
+func resourceLLDRead(d *schema.ResourceData, m interface{}, r LLDHandler, prototype bool) error {
+	api := metaAPI(m)
 
 	log.Debug("Lookup of lld with id %s", d.Id())
 
-	llds, err := api.LLDsGet(zabbix.Params{
+	params := zabbix.Params{
 		"itemids":             []string{d.Id()},
 		"selectPreprocessing": "extend",
 		"selectLLDMacroPaths": "extend",
 		"selectFilter":        "extend",
-	})
+	}
+	if api.Config.Version >= 50000 {
+		params["selectOverrides"] = "extend"
+	}
+	if prototype {
+		params["selectDiscoveryRule"] = "extend"
+	}
+
+	llds, err := lldsGet(api, params, prototype)
 
 	if err != nil {
 		return err
@@ -312,13 +399,28 @@ func resourceLLDRead(d *schema.ResourceData, m interface{}, r LLDHandler) error
 	d.Set("hostid", lld.HostID)
 	d.Set("key", lld.Key)
 	d.Set("name", lld.Name)
+	d.Set("description", lld.Description)
+	d.Set("enabled", lld.Status != "1")
 	d.Set("delay", lld.Delay)
-	d.Set("lifetime", lld.LifeTime)
+	if api.Config.Version >= 70000 {
+		lldFlattenLifetime(d, lld)
+	} else {
+		d.Set("lifetime", lld.LifeTime)
+	}
 	d.Set("evaltype", LLD_EVALTYPE_REV[lld.Filter.EvalType])
 	d.Set("formula", lld.Filter.Formula)
-	d.Set("condition", flattenlldConditions(lld))
-	d.Set("preprocessor", flattenlldPreprocessors(lld))
-	d.Set("macro", flattenlldMacroPaths(lld))
+	d.Set("condition", flattenlldConditions(lld.LLDRule))
+	d.Set("preprocessor", flattenlldPreprocessors(lld.LLDRule))
+	d.Set("macro", flattenlldMacroPaths(lld.LLDRule))
+	if lld.Overrides != nil {
+		d.Set("override", flattenlldOverrides(*lld.Overrides))
+	}
+	if prototype && lld.DiscoveryRule != nil {
+		d.Set("ruleid", lld.DiscoveryRule.ItemID)
+	}
+	if prototype {
+		d.Set("discover", lld.Discover != "1")
+	}
 
 	// run custom
 	r(d, m, &lld)
@@ -327,13 +429,18 @@ func resourceLLDRead(d *schema.ResourceData, m interface{}, r LLDHandler) error
 }
 
 // Build the base lld Object
-func buildLLDObject(d *schema.ResourceData) *zabbix.LLDRule {
-	lld := zabbix.LLDRule{
-		Key:      d.Get("key").(string),
-		HostID:   d.Get("hostid").(string),
-		Name:     d.Get("name").(string),
-		Delay:    d.Get("delay").(string),
-		LifeTime: d.Get("lifetime").(string),
+func buildLLDObject(d *schema.ResourceData, prototype bool) *apiLLDRule {
+	lld := apiLLDRule{LLDRule: zabbix.LLDRule{
+		Key:         d.Get("key").(string),
+		HostID:      d.Get("hostid").(string),
+		Name:        d.Get("name").(string),
+		Description: d.Get("description").(string),
+		Delay:       d.Get("delay").(string),
+		LifeTime:    d.Get("lifetime").(string),
+	}}
+	lld.Status = "0"
+	if !d.Get("enabled").(bool) {
+		lld.Status = "1"
 	}
 
 	lld.Preprocessors = lldGeneratePreprocessors(d)
@@ -343,11 +450,77 @@ func buildLLDObject(d *schema.ResourceData) *zabbix.LLDRule {
 	lld.Filter.Formula = d.Get("formula").(string)
 	lld.Filter.Conditions = lldGenerateConditions(d)
 
+	if prototype {
+		lld.RuleID = d.Get("ruleid").(string)
+		lld.Discover = "0"
+		if !d.Get("discover").(bool) {
+			lld.Discover = "1"
+		}
+	}
+
 	return &lld
 }
 
+// lldGenerateLifetime set 7.0 lifetime fields, periods are only accepted
+// alongside an "after" type
+func lldGenerateLifetime(d *schema.ResourceData, lld *apiLLDRule) {
+	lifetimeType := d.Get("lifetime_type").(string)
+	enabledType := d.Get("enabled_lifetime_type").(string)
+
+	lld.LifeTimeType = LLD_LIFETIME_TYPE[lifetimeType]
+	if lifetimeType != "after" {
+		lld.LifeTime = ""
+	}
+	if lifetimeType == "immediately" {
+		return
+	}
+
+	lld.EnabledLifeTimeType = LLD_LIFETIME_TYPE[enabledType]
+	if enabledType == "after" {
+		lld.EnabledLifeTime = d.Get("enabled_lifetime").(string)
+	}
+}
+
+// lldFlattenLifetime read 7.0 lifetime fields, periods not in use are left as configured
+func lldFlattenLifetime(d *schema.ResourceData, lld apiLLDRule) {
+	lifetimeType := LLD_LIFETIME_TYPE_REV[lld.LifeTimeType]
+	enabledType := LLD_LIFETIME_TYPE_REV[lld.EnabledLifeTimeType]
+
+	d.Set("lifetime_type", lifetimeType)
+	if lifetimeType == "after" {
+		d.Set("lifetime", lld.LifeTime)
+	}
+	if lifetimeType == "immediately" {
+		return
+	}
+	d.Set("enabled_lifetime_type", enabledType)
+	if enabledType == "after" {
+		d.Set("enabled_lifetime", lld.EnabledLifeTime)
+	}
+}
+
+// lldLifetimeCustomizeDiff lifetime types exist from 7.0, and disabling
+// only applies to resources that are not deleted immediately
+func lldLifetimeCustomizeDiff(d *schema.ResourceDiff, m interface{}) error {
+	lifetimeType := d.Get("lifetime_type").(string)
+	enabledType := d.Get("enabled_lifetime_type").(string)
+
+	if api := metaAPI(m); api != nil && api.Config.Version > 0 && api.Config.Version < 70000 {
+		if lifetimeType != "after" || enabledType != "never" {
+			return fmt.Errorf("lifetime_type and enabled_lifetime_type require zabbix 7.0 or later")
+		}
+	}
+	if lifetimeType == "immediately" && enabledType != "never" {
+		return fmt.Errorf("enabled_lifetime_type must be never when lifetime_type is immediately")
+	}
+	if enabledType != "after" && d.Get("enabled_lifetime").(string) != "0" {
+		return fmt.Errorf("enabled_lifetime requires enabled_lifetime_type after")
+	}
+	return nil
+}
+
 // Generate preprocessor objects
-func lldGeneratePreprocessors(d *schema.ResourceData) (preprocessors zabbix.Preprocessors) {
+func lldGeneratePreprocessors(d resourceGetter) (preprocessors zabbix.Preprocessors) {
 	preprocessorCount := d.Get("preprocessor.#").(int)
 	preprocessors = make(zabbix.Preprocessors, preprocessorCount)
 
@@ -360,9 +533,9 @@ func lldGeneratePreprocessors(d *schema.ResourceData) (preprocessors zabbix.Prep
 		}
 
 		preprocessors[i] = zabbix.Preprocessor{
-			Type:               d.Get(prefix + "type").(string),
+			Type:               preprocessorTypeId(d.Get(prefix + "type").(string)),
 			Params:             strings.Join(pstrarr, "\n"),
-			ErrorHandler:       d.Get(prefix + "error_handler").(string),
+			ErrorHandler:       preprocessorErrorHandlerId(d.Get(prefix + "error_handler").(string)),
 			ErrorHandlerParams: d.Get(prefix + "error_handler_params").(string),
 		}
 	}
@@ -387,21 +560,25 @@ func lldGenerateMacroPaths(d *schema.ResourceData) (paths zabbix.LLDMacroPaths)
 }
 
 // Generate LLD Filter Conditions
-func lldGenerateConditions(d *schema.ResourceData) (conditions zabbix.LLDRuleFilterConditions) {
-	conditionsCount := d.Get("condition.#").(int)
-	conditions = make(zabbix.LLDRuleFilterConditions, conditionsCount)
+func lldGenerateConditions(d *schema.ResourceData) zabbix.LLDRuleFilterConditions {
+	return lldBuildConditions(d.Get("condition").([]interface{}))
+}
+
+// Build filter conditions from condition blocks, shared by filters and overrides
+func lldBuildConditions(list []interface{}) (conditions zabbix.LLDRuleFilterConditions) {
+	conditions = make(zabbix.LLDRuleFilterConditions, len(list))
 
-	for i := 0; i < conditionsCount; i++ {
-		prefix := fmt.Sprintf("condition.%d.", i)
+	for i := 0; i < len(list); i++ {
+		current := list[i].(map[string]interface{})
 
 		conditions[i] = zabbix.LLDRuleFilterCondition{
-			Macro:    d.Get(prefix + "macro").(string),
-			Value:    d.Get(prefix + "value").(string),
-			Operator: LLD_OPERATOR[d.Get(prefix+"operator").(string)],
+			Macro:     current["macro"].(string),
+			Value:     current["value"].(string),
+			Operator:  LLD_OPERATOR[current["operator"].(string)],
+			FormulaID: current["formulaid"].(string),
 		}
-		id := d.Get(prefix + "id").(string)
-		if id != "" {
-			conditions[i].FormulaID = id
+		if conditions[i].FormulaID == "" {
+			conditions[i].FormulaID = current["id"].(string)
 		}
 	}
 
@@ -412,14 +589,15 @@ func lldGenerateConditions(d *schema.ResourceData) (conditions zabbix.LLDRuleFil
 func flattenlldPreprocessors(lld zabbix.LLDRule) []interface{} {
 	val := make([]interface{}, len(lld.Preprocessors))
 	for i := 0; i < len(lld.Preprocessors); i++ {
-		parr := strings.Split(lld.Preprocessors[i].Params, "\n")
 		val[i] = map[string]interface{}{
 			//"id": host.Interfaces[i].InterfaceID,
-			"type":                 lld.Preprocessors[i].Type,
-			"params":               parr,
-			"error_handler":        lld.Preprocessors[i].ErrorHandler,
+			"type":                 preprocessorTypeName(lld.Preprocessors[i].Type),
+			"error_handler":        preprocessorErrorHandlerName(lld.Preprocessors[i].ErrorHandler),
 			"error_handler_params": lld.Preprocessors[i].ErrorHandlerParams,
 		}
+		if lld.Preprocessors[i].Params != "" {
+			val[i].(map[string]interface{})["params"] = strings.Split(lld.Preprocessors[i].Params, "\n")
+		}
 	}
 	return val
 }
@@ -440,20 +618,215 @@ func flattenlldMacroPaths(lld zabbix.LLDRule) *schema.Set {
 
 // Generate terraform flattened form of lld filter conditions
 func flattenlldConditions(lld zabbix.LLDRule) []interface{} {
-	val := make([]interface{}, len(lld.Filter.Conditions))
-	for i := 0; i < len(lld.Filter.Conditions); i++ {
+	return flattenlldFilterConditions(lld.Filter.Conditions)
+}
+
+// Generate terraform flattened form of filter conditions, shared by filters and overrides
+func flattenlldFilterConditions(conditions zabbix.LLDRuleFilterConditions) []interface{} {
+	val := make([]interface{}, len(conditions))
+	for i := 0; i < len(conditions); i++ {
 		val[i] = map[string]interface{}{
-			"id":       lld.Filter.Conditions[i].FormulaID,
-			"macro":    lld.Filter.Conditions[i].Macro,
-			"value":    lld.Filter.Conditions[i].Value,
-			"operator": LLD_OPERATOR_REV[lld.Filter.Conditions[i].Operator],
+			"id":        conditions[i].FormulaID,
+			"formulaid": conditions[i].FormulaID,
+			"macro":     conditions[i].Macro,
+			"value":     conditions[i].Value,
+			"operator":  LLD_OPERATOR_REV[conditions[i].Operator],
 		}
 	}
 	return val
 }
 
+// apiLLDRule api lld rule, extended with attributes not handled by the api library
+type apiLLDRule struct {
+	zabbix.LLDRule
+
+	JmxEndpoint string        `json:"jmx_endpoint,omitempty"`
+	Overrides   *lldOverrides `json:"overrides,omitempty"`
+
+	LifeTimeType        string `json:"lifetime_type,omitempty"`
+	EnabledLifeTimeType string `json:"enabled_lifetime_type,omitempty"`
+	EnabledLifeTime     string `json:"enabled_lifetime,omitempty"`
+
+	// prototypes only
+	RuleID        string          `json:"ruleid,omitempty"`
+	Discover      string          `json:"discover,omitempty"`
+	DiscoveryRule *zabbix.LLDRule `json:"discoveryRule,omitempty"`
+
+	Parameters    itemParameters  `json:"-"`
+	RawParameters json.RawMessage `json:"parameters,omitempty"`
+}
+
+type apiLLDRules []apiLLDRule
+
+// lldsGet wrapper for discoveryrule.get / discoveryruleprototype.get
+func lldsGet(api *zabbix.API, params zabbix.Params, prototype bool) (res apiLLDRules, err error) {
+	method := "discoveryrule.get"
+	if prototype {
+		method = "discoveryruleprototype.get"
+	}
+	if _, present := params["output"]; !present {
+		params["output"] = "extend"
+	}
+	err = api.CallWithErrorParse(method, params, &res)
+	if err != nil {
+		return
+	}
+
+	// unbox headers and parameters
+	for i := 0; i < len(res); i++ {
+		res[i].Headers = zabbix.HttpHeaders{}
+
+		if raw := string(res[i].RawHeaders); raw != "" && raw != "[]" {
+			if err = json.Unmarshal(res[i].RawHeaders, &res[i].Headers); err != nil {
+				return
+			}
+		}
+
+		if err = unboxItemParameters(res[i].RawParameters, &res[i].Parameters); err != nil {
+			return
+		}
+	}
+	return
+}
+
+// handle manual marshal of headers and parameters
+func prepLLDs(llds apiLLDRules) {
+	for i := 0; i < len(llds); i++ {
+		if llds[i].Headers != nil {
+			asB, _ := json.Marshal(llds[i].Headers)
+			llds[i].RawHeaders = json.RawMessage(asB)
+		}
+		if llds[i].Parameters != nil {
+			asB, _ := json.Marshal(llds[i].Parameters)
+			llds[i].RawParameters = json.RawMessage(asB)
+		}
+	}
+}
+
+// lldsCreate wrapper for discoveryrule.create / discoveryruleprototype.create
+func lldsCreate(api *zabbix.API, llds apiLLDRules, prototype bool) (err error) {
+	method := "discoveryrule.create"
+	if prototype {
+		method = "discoveryruleprototype.create"
+	}
+	prepLLDs(llds)
+	response, err := api.CallWithError(method, llds)
+	if err != nil {
+		return
+	}
+
+	result := response.Result.(map[string]interface{})
+	itemids := result["itemids"].([]interface{})
+	for i, id := range itemids {
+		llds[i].ItemID = id.(string)
+	}
+	return
+}
+
+// lldsUpdate wrapper for discoveryrule.update / discoveryruleprototype.update
+func lldsUpdate(api *zabbix.API, llds apiLLDRules, prototype bool) (err error) {
+	method := "discoveryrule.update"
+	if prototype {
+		method = "discoveryruleprototype.update"
+	}
+	prepLLDs(llds)
+	_, err = api.CallWithError(method, llds)
+	return
+}
+
+// lldCustomizeDiff plan time checks common to all lld rules
+func lldCustomizeDiff(d *schema.ResourceDiff, m interface{}) error {
+	if err := preprocessorCustomizeDiff(d, m); err != nil {
+		return err
+	}
+	if err := lldValidateFilter(d, ""); err != nil {
+		return err
+	}
+	if err := lldLifetimeCustomizeDiff(d, m); err != nil {
+		return err
+	}
+	if err := lldPrototypeCustomizeDiff(d, m); err != nil {
+		return err
+	}
+	return lldOverrideCustomizeDiff(d, m)
+}
+
+// lldPrototypeCustomizeDiff nested discovery only exists from 7.4
+func lldPrototypeCustomizeDiff(d *schema.ResourceDiff, m interface{}) error {
+	// ruleid only present on prototypes
+	if _, prototype := d.Get("ruleid").(string); !prototype {
+		return nil
+	}
+	if api := metaAPI(m); api != nil && api.Config.Version > 0 && api.Config.Version < 70400 {
+		return errors.New("lld rule prototypes require zabbix 7.4 or later")
+	}
+	return nil
+}
+
+var lldFormulaIDs = regexp.MustCompile("[A-Z]+")
+
+// lldValidateFilter check filter conditions have a value when their operator
+// needs one, and custom formulas reference exactly the defined condition ids
+func lldValidateFilter(d *schema.ResourceDiff, prefix string) error {
+	conditions := d.Get(prefix + "condition").([]interface{})
+
+	ids := map[string]bool{}
+	for i, v := range conditions {
+		current := v.(map[string]interface{})
+		p := fmt.Sprintf("%scondition.%d.", prefix, i)
+
+		if d.NewValueKnown(p+"value") && LLD_OPERATOR_VALUE[current["operator"].(string)] && current["value"].(string) == "" {
+			return fmt.Errorf("%scondition %d: operator %s requires a value", prefix, i, current["operator"])
+		}
+
+		id := current["formulaid"].(string)
+		if id == "" {
+			continue
+		}
+		if ids[id] {
+			return fmt.Errorf("%scondition %d: formulaid %s is used more than once", prefix, i, id)
+		}
+		ids[id] = true
+	}
+
+	if d.Get(prefix+"evaltype").(string) != "custom" || !d.NewValueKnown(prefix+"formula") {
+		return nil
+	}
+
+	formula := d.Get(prefix + "formula").(string)
+	if formula == "" {
+		return fmt.Errorf("%sformula is required with evaltype custom", prefix)
+	}
+	// unset formulaids are only known after apply, so must be configured
+	for i, v := range conditions {
+		if v.(map[string]interface{})["formulaid"].(string) == "" {
+			return fmt.Errorf("%scondition %d: formulaid is required with evaltype custom", prefix, i)
+		}
+	}
+
+	used := map[string]bool{}
+	for _, ref := range lldFormulaIDs.FindAllString(formula, -1) {
+		if !ids[ref] {
+			return fmt.Errorf("%sformula references undefined condition %s", prefix, ref)
+		}
+		used[ref] = true
+	}
+	for id := range ids {
+		if !used[id] {
+			return fmt.Errorf("%scondition %s is not used in formula", prefix, id)
+		}
+	}
+
+	return nil
+}
+
 // Delete lld Resource Handler
 func resourceLLDDelete(d *schema.ResourceData, m interface{}) error {
-	api := m.(*zabbix.API)
+	api := metaAPI(m)
 	return api.LLDDeleteByIds([]string{d.Id()})
 }
+func resourceProtoLLDDelete(d *schema.ResourceData, m interface{}) error {
+	api := metaAPI(m)
+	_, err := api.CallWithError("discoveryruleprototype.delete", []string{d.Id()})
+	return err
+}
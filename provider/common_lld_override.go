@@ -0,0 +1,512 @@
+package provider
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/validation"
+	"github.com/tpretz/go-zabbix-api"
+)
+
+// override operation object types
+var LLD_OVERRIDE_OBJECT = map[string]string{
+	"item":    "0",
+	"trigger": "1",
+	"graph":   "2",
+	"host":    "3",
+}
+var LLD_OVERRIDE_OBJECT_REV = map[string]string{}
+var LLD_OVERRIDE_OBJECT_ARR = []string{}
+
+// override operation name operators
+var LLD_OVERRIDE_OPERATOR = map[string]string{
+	"equals":      "0",
+	"notequals":   "1",
+	"contains":    "2",
+	"notcontains": "3",
+	"match":       "8",
+	"notmatch":    "9",
+}
+var LLD_OVERRIDE_OPERATOR_REV = map[string]string{}
+var LLD_OVERRIDE_OPERATOR_ARR = []string{}
+
+// override operation status
+var LLD_OVERRIDE_STATUS = map[string]string{
+	"enabled":  "0",
+	"disabled": "1",
+}
+var LLD_OVERRIDE_STATUS_REV = map[string]string{}
+var LLD_OVERRIDE_STATUS_ARR = []string{}
+
+// override operation discover
+var LLD_OVERRIDE_DISCOVER = map[string]string{
+	"yes": "0",
+	"no":  "1",
+}
+var LLD_OVERRIDE_DISCOVER_REV = map[string]string{}
+var LLD_OVERRIDE_DISCOVER_ARR = []string{}
+
+// generate the above structures
+var _ = func() bool {
+	for _, lookup := range []struct {
+		m   map[string]string
+		rev map[string]string
+		arr *[]string
+	}{
+		{LLD_OVERRIDE_OBJECT, LLD_OVERRIDE_OBJECT_REV, &LLD_OVERRIDE_OBJECT_ARR},
+		{LLD_OVERRIDE_OPERATOR, LLD_OVERRIDE_OPERATOR_REV, &LLD_OVERRIDE_OPERATOR_ARR},
+		{LLD_OVERRIDE_STATUS, LLD_OVERRIDE_STATUS_REV, &LLD_OVERRIDE_STATUS_ARR},
+		{LLD_OVERRIDE_DISCOVER, LLD_OVERRIDE_DISCOVER_REV, &LLD_OVERRIDE_DISCOVER_ARR},
+	} {
+		for k, v := range lookup.m {
+			lookup.rev[v] = k
+			*lookup.arr = append(*lookup.arr, k)
+		}
+		sort.Strings(*lookup.arr)
+	}
+	return false
+}()
+
+// severities and inventory modes, built here as the lookups they derive from
+// are generated in other files, which may initialise after this schema
+var LLD_OVERRIDE_SEVERITY_ARR = func() (arr []string) {
+	for k := range TRIGGER_PRIORITY {
+		arr = append(arr, k)
+	}
+	sort.Strings(arr)
+	return
+}()
+var LLD_OVERRIDE_INVENTORY_ARR = func() (arr []string) {
+	for k := range HINV_LOOKUP {
+		arr = append(arr, k)
+	}
+	sort.Strings(arr)
+	return
+}()
+
+// operation options applicable to each object type
+var lldOverrideOptions = map[string][]string{
+	"item":    {"status", "discover", "delay", "history", "trends", "tag"},
+	"trigger": {"status", "discover", "severity", "tag"},
+	"graph":   {"discover"},
+	"host":    {"status", "discover", "tag", "templateids", "inventory_mode"},
+}
+
+// Schema for override blocks
+var lldOverrideSchema = &schema.Schema{
+	Type:        schema.TypeList,
+	Optional:    true,
+	Description: "LLD overrides, zabbix 5.0+",
+	Elem: &schema.Resource{
+		Schema: map[string]*schema.Schema{
+			"name": &schema.Schema{
+				Type:         schema.TypeString,
+				Required:     true,
+				Description:  "Override name",
+				ValidateFunc: validation.StringIsNotWhiteSpace,
+			},
+			"step": &schema.Schema{
+				Type:         schema.TypeInt,
+				Required:     true,
+				Description:  "Order the override is processed in, unique per rule, overrides must be listed in ascending step order",
+				ValidateFunc: validation.IntAtLeast(1),
+			},
+			"stop": &schema.Schema{
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+				Description: "Stop processing further overrides when matched",
+			},
+			"evaltype": &schema.Schema{
+				Type:         schema.TypeString,
+				Description:  "EvalType, one of: " + strings.Join(LLD_EVALTYPE_ARR, ", "),
+				ValidateFunc: validation.StringInSlice(LLD_EVALTYPE_ARR, false),
+				Default:      "andor",
+				Optional:     true,
+			},
+			"formula": &schema.Schema{
+				Type:        schema.TypeString,
+				Description: "Formula",
+				Default:     "",
+				Optional:    true,
+			},
+			"condition": lldFilterConditionSchema,
+			"operation": &schema.Schema{
+				Type:        schema.TypeList,
+				Optional:    true,
+				Description: "Operations applied to matching discovered objects",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"object": &schema.Schema{
+							Type:         schema.TypeString,
+							Required:     true,
+							Description:  "Prototype object type, one of: " + strings.Join(LLD_OVERRIDE_OBJECT_ARR, ", "),
+							ValidateFunc: validation.StringInSlice(LLD_OVERRIDE_OBJECT_ARR, false),
+						},
+						"operator": &schema.Schema{
+							Type:         schema.TypeString,
+							Optional:     true,
+							Default:      "equals",
+							Description:  "Name / key operator, one of: " + strings.Join(LLD_OVERRIDE_OPERATOR_ARR, ", "),
+							ValidateFunc: validation.StringInSlice(LLD_OVERRIDE_OPERATOR_ARR, false),
+						},
+						"value": &schema.Schema{
+							Type:        schema.TypeString,
+							Optional:    true,
+							Description: "Value matched against the prototype name (key for items)",
+						},
+						"status": &schema.Schema{
+							Type:         schema.TypeString,
+							Optional:     true,
+							Description:  "Create discovered objects, one of: " + strings.Join(LLD_OVERRIDE_STATUS_ARR, ", "),
+							ValidateFunc: validation.StringInSlice(LLD_OVERRIDE_STATUS_ARR, false),
+						},
+						"discover": &schema.Schema{
+							Type:         schema.TypeString,
+							Optional:     true,
+							Description:  "Discover objects, one of: " + strings.Join(LLD_OVERRIDE_DISCOVER_ARR, ", "),
+							ValidateFunc: validation.StringInSlice(LLD_OVERRIDE_DISCOVER_ARR, false),
+						},
+						"delay": &schema.Schema{
+							Type:         schema.TypeString,
+							Optional:     true,
+							Description:  "Item update interval",
+							ValidateFunc: validation.StringIsNotWhiteSpace,
+						},
+						"history": &schema.Schema{
+							Type:         schema.TypeString,
+							Optional:     true,
+							Description:  "Item history storage period",
+							ValidateFunc: validation.StringIsNotWhiteSpace,
+						},
+						"trends": &schema.Schema{
+							Type:         schema.TypeString,
+							Optional:     true,
+							Description:  "Item trends storage period",
+							ValidateFunc: validation.StringIsNotWhiteSpace,
+						},
+						"severity": &schema.Schema{
+							Type:         schema.TypeString,
+							Optional:     true,
+							Description:  "Trigger severity, one of: " + strings.Join(LLD_OVERRIDE_SEVERITY_ARR, ", "),
+							ValidateFunc: validation.StringInSlice(LLD_OVERRIDE_SEVERITY_ARR, false),
+						},
+						"tag": &schema.Schema{
+							Type:     schema.TypeSet,
+							Optional: true,
+							Elem: &schema.Resource{
+								Schema: map[string]*schema.Schema{
+									"key": &schema.Schema{
+										Type:         schema.TypeString,
+										Required:     true,
+										ValidateFunc: validation.StringIsNotWhiteSpace,
+										Description:  "Tag Key",
+									},
+									"value": &schema.Schema{
+										Type:        schema.TypeString,
+										Optional:    true,
+										Description: "Tag Value",
+									},
+								},
+							},
+						},
+						"templateids": &schema.Schema{
+							Type:        schema.TypeSet,
+							Optional:    true,
+							Description: "Templates linked to discovered hosts",
+							Elem: &schema.Schema{
+								Type:         schema.TypeString,
+								ValidateFunc: validation.StringMatch(regexp.MustCompile("^[0-9]+$"), "must be a numeric string"),
+							},
+						},
+						"inventory_mode": &schema.Schema{
+							Type:         schema.TypeString,
+							Optional:     true,
+							Description:  "Discovered host inventory mode, one of: " + strings.Join(LLD_OVERRIDE_INVENTORY_ARR, ", "),
+							ValidateFunc: validation.StringInSlice(LLD_OVERRIDE_INVENTORY_ARR, false),
+						},
+					},
+				},
+			},
+		},
+	},
+}
+
+// lldOverrideOption single valued operation option, ie opstatus {"status": "1"}
+type lldOverrideOption map[string]string
+
+// UnmarshalJSON unset options may be returned as an empty array
+func (o *lldOverrideOption) UnmarshalJSON(b []byte) error {
+	if string(b) == "[]" {
+		return nil
+	}
+	return json.Unmarshal(b, (*map[string]string)(o))
+}
+
+type lldOverrideTag struct {
+	Tag   string `json:"tag"`
+	Value string `json:"value"`
+}
+
+type lldOverrideTemplate struct {
+	TemplateID string `json:"templateid"`
+}
+
+type lldOverrideOperation struct {
+	OperationObject string                `json:"operationobject"`
+	Operator        string                `json:"operator"`
+	Value           string                `json:"value"`
+	OpStatus        lldOverrideOption     `json:"opstatus,omitempty"`
+	OpDiscover      lldOverrideOption     `json:"opdiscover,omitempty"`
+	OpPeriod        lldOverrideOption     `json:"opperiod,omitempty"`
+	OpHistory       lldOverrideOption     `json:"ophistory,omitempty"`
+	OpTrends        lldOverrideOption     `json:"optrends,omitempty"`
+	OpSeverity      lldOverrideOption     `json:"opseverity,omitempty"`
+	OpInventory     lldOverrideOption     `json:"opinventory,omitempty"`
+	OpTag           []lldOverrideTag      `json:"optag,omitempty"`
+	OpTemplate      []lldOverrideTemplate `json:"optemplate,omitempty"`
+}
+
+// lldOverride api lld rule override
+type lldOverride struct {
+	Name       string                 `json:"name"`
+	Step       string                 `json:"step"`
+	Stop       string                 `json:"stop"`
+	Filter     *zabbix.LLDRuleFilter  `json:"filter,omitempty"`
+	Operations []lldOverrideOperation `json:"operations"`
+}
+
+type lldOverrides []lldOverride
+
+// lldGenerateOverrides build override objects from terraform inputs
+func lldGenerateOverrides(d resourceGetter) lldOverrides {
+	list := d.Get("override").([]interface{})
+	overrides := make(lldOverrides, len(list))
+
+	for i, v := range list {
+		current := v.(map[string]interface{})
+
+		overrides[i] = lldOverride{
+			Name:       current["name"].(string),
+			Step:       strconv.Itoa(current["step"].(int)),
+			Stop:       "0",
+			Operations: []lldOverrideOperation{},
+		}
+		if current["stop"].(bool) {
+			overrides[i].Stop = "1"
+		}
+
+		conditions := lldBuildConditions(current["condition"].([]interface{}))
+		if len(conditions) > 0 {
+			overrides[i].Filter = &zabbix.LLDRuleFilter{
+				EvalType:   LLD_EVALTYPE[current["evaltype"].(string)],
+				Formula:    current["formula"].(string),
+				Conditions: conditions,
+			}
+		}
+
+		for _, o := range current["operation"].([]interface{}) {
+			overrides[i].Operations = append(overrides[i].Operations, lldBuildOverrideOperation(o.(map[string]interface{})))
+		}
+	}
+
+	return overrides
+}
+
+// lldBuildOverrideOperation build a single override operation
+func lldBuildOverrideOperation(op map[string]interface{}) lldOverrideOperation {
+	res := lldOverrideOperation{
+		OperationObject: LLD_OVERRIDE_OBJECT[op["object"].(string)],
+		Operator:        LLD_OVERRIDE_OPERATOR[op["operator"].(string)],
+		Value:           op["value"].(string),
+	}
+
+	if v := op["status"].(string); v != "" {
+		res.OpStatus = lldOverrideOption{"status": LLD_OVERRIDE_STATUS[v]}
+	}
+	if v := op["discover"].(string); v != "" {
+		res.OpDiscover = lldOverrideOption{"discover": LLD_OVERRIDE_DISCOVER[v]}
+	}
+	if v := op["delay"].(string); v != "" {
+		res.OpPeriod = lldOverrideOption{"delay": v}
+	}
+	if v := op["history"].(string); v != "" {
+		res.OpHistory = lldOverrideOption{"history": v}
+	}
+	if v := op["trends"].(string); v != "" {
+		res.OpTrends = lldOverrideOption{"trends": v}
+	}
+	if v := op["severity"].(string); v != "" {
+		res.OpSeverity = lldOverrideOption{"severity": strconv.Itoa(int(TRIGGER_PRIORITY[v]))}
+	}
+	if v := op["inventory_mode"].(string); v != "" {
+		res.OpInventory = lldOverrideOption{"inventory_mode": strconv.Itoa(int(HINV_LOOKUP[v]))}
+	}
+	for _, t := range op["tag"].(*schema.Set).List() {
+		tag := t.(map[string]interface{})
+		res.OpTag = append(res.OpTag, lldOverrideTag{
+			Tag:   tag["key"].(string),
+			Value: tag["value"].(string),
+		})
+	}
+	for _, id := range op["templateids"].(*schema.Set).List() {
+		res.OpTemplate = append(res.OpTemplate, lldOverrideTemplate{TemplateID: id.(string)})
+	}
+
+	return res
+}
+
+// flattenlldOverrides convert api overrides to terraform inputs, in step order
+func flattenlldOverrides(overrides lldOverrides) []interface{} {
+	sort.SliceStable(overrides, func(i, j int) bool {
+		a, _ := strconv.Atoi(overrides[i].Step)
+		b, _ := strconv.Atoi(overrides[j].Step)
+		return a < b
+	})
+
+	val := make([]interface{}, len(overrides))
+	for i, o := range overrides {
+		step, _ := strconv.Atoi(o.Step)
+		current := map[string]interface{}{
+			"name":      o.Name,
+			"step":      step,
+			"stop":      o.Stop == "1",
+			"evaltype":  LLD_EVALTYPE_REV[zabbix.LLDEvalType("0")],
+			"formula":   "",
+			"condition": []interface{}{},
+		}
+		if o.Filter != nil {
+			current["evaltype"] = LLD_EVALTYPE_REV[o.Filter.EvalType]
+			current["formula"] = o.Filter.Formula
+			current["condition"] = flattenlldFilterConditions(o.Filter.Conditions)
+		}
+
+		operations := make([]interface{}, len(o.Operations))
+		for j, op := range o.Operations {
+			operations[j] = flattenlldOverrideOperation(op)
+		}
+		current["operation"] = operations
+
+		val[i] = current
+	}
+	return val
+}
+
+// flattenlldOverrideOperation convert a single api override operation
+func flattenlldOverrideOperation(op lldOverrideOperation) map[string]interface{} {
+	res := map[string]interface{}{
+		"object":         LLD_OVERRIDE_OBJECT_REV[op.OperationObject],
+		"operator":       LLD_OVERRIDE_OPERATOR_REV[op.Operator],
+		"value":          op.Value,
+		"status":         LLD_OVERRIDE_STATUS_REV[op.OpStatus["status"]],
+		"discover":       LLD_OVERRIDE_DISCOVER_REV[op.OpDiscover["discover"]],
+		"delay":          op.OpPeriod["delay"],
+		"history":        op.OpHistory["history"],
+		"trends":         op.OpTrends["trends"],
+		"severity":       "",
+		"inventory_mode": "",
+	}
+	if v, ok := op.OpSeverity["severity"]; ok {
+		severity, _ := strconv.Atoi(v)
+		res["severity"] = TRIGGER_PRIORITY_REV[zabbix.SeverityType(severity)]
+	}
+	if v, ok := op.OpInventory["inventory_mode"]; ok {
+		mode, _ := strconv.Atoi(v)
+		res["inventory_mode"] = HINV_LOOKUP_REV[zabbix.InventoryMode(mode)]
+	}
+
+	tags := make([]interface{}, len(op.OpTag))
+	for i, t := range op.OpTag {
+		tags[i] = map[string]interface{}{
+			"key":   t.Tag,
+			"value": t.Value,
+		}
+	}
+	res["tag"] = tags
+
+	templates := make([]interface{}, len(op.OpTemplate))
+	for i, t := range op.OpTemplate {
+		templates[i] = t.TemplateID
+	}
+	res["templateids"] = templates
+
+	return res
+}
+
+// lldOverrideCustomizeDiff validate overrides at plan time
+func lldOverrideCustomizeDiff(d *schema.ResourceDiff, m interface{}) error {
+	overrides := d.Get("override").([]interface{})
+	if len(overrides) < 1 {
+		return nil
+	}
+
+	if api := metaAPI(m); api != nil && api.Config.Version > 0 && api.Config.Version < 50000 {
+		return fmt.Errorf("override requires zabbix 5.0 or later")
+	}
+
+	// overrides are read back ordered by step
+	last := 0
+	for i, v := range overrides {
+		current := v.(map[string]interface{})
+
+		if err := lldValidateFilter(d, fmt.Sprintf("override.%d.", i)); err != nil {
+			return err
+		}
+
+		step := current["step"].(int)
+		if step <= last {
+			return fmt.Errorf("override %d: step %d must be greater than the previous override step %d, list overrides in step order", i, step, last)
+		}
+		last = step
+
+		for j, o := range current["operation"].([]interface{}) {
+			if err := lldValidateOverrideOperation(o.(map[string]interface{})); err != nil {
+				return fmt.Errorf("override %d operation %d: %s", i, j, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// lldValidateOverrideOperation check only options applicable to the object type are set
+func lldValidateOverrideOperation(op map[string]interface{}) error {
+	object := op["object"].(string)
+	allowed, ok := lldOverrideOptions[object]
+	if !ok {
+		// unknown object, only possible with values unknown at plan time
+		return nil
+	}
+
+	set := []string{}
+	for _, option := range []string{"status", "discover", "delay", "history", "trends", "severity", "inventory_mode"} {
+		if op[option].(string) != "" {
+			set = append(set, option)
+		}
+	}
+	if op["tag"].(*schema.Set).Len() > 0 {
+		set = append(set, "tag")
+	}
+	if op["templateids"].(*schema.Set).Len() > 0 {
+		set = append(set, "templateids")
+	}
+
+	for _, option := range set {
+		found := false
+		for _, a := range allowed {
+			if a == option {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return fmt.Errorf("%s is not applicable to %s prototypes, expected one of: %s", option, object, strings.Join(allowed, ", "))
+		}
+	}
+	return nil
+}
@@ -0,0 +1,167 @@
+package provider
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/terraform"
+	"github.com/tpretz/go-zabbix-api"
+)
+
+func TestLLDOverrideRoundTrip(t *testing.T) {
+	r := &schema.Resource{Schema: map[string]*schema.Schema{"override": lldOverrideSchema}}
+	d := r.Data(nil)
+
+	override := []interface{}{
+		map[string]interface{}{
+			"name":     "skip loopback",
+			"step":     1,
+			"stop":     true,
+			"evaltype": "and",
+			"formula":  "",
+			"condition": []interface{}{
+				map[string]interface{}{"id": "", "macro": "{#IFNAME}", "value": "^lo$", "operator": "match"},
+			},
+			"operation": []interface{}{
+				map[string]interface{}{
+					"object":   "item",
+					"operator": "contains",
+					"value":    "net.if",
+					"status":   "disabled",
+					"discover": "no",
+					"history":  "1d",
+					"tag":      []interface{}{map[string]interface{}{"key": "scope", "value": "loopback"}},
+				},
+				map[string]interface{}{
+					"object":   "trigger",
+					"operator": "equals",
+					"severity": "high",
+				},
+			},
+		},
+	}
+	if err := d.Set("override", override); err != nil {
+		t.Fatal(err)
+	}
+
+	overrides := lldGenerateOverrides(d)
+	if len(overrides) != 1 || overrides[0].Step != "1" || overrides[0].Stop != "1" {
+		t.Fatalf("unexpected overrides %+v", overrides)
+	}
+	ops := overrides[0].Operations
+	if ops[0].OperationObject != "0" || ops[0].Operator != "2" || ops[0].OpStatus["status"] != "1" || ops[0].OpDiscover["discover"] != "1" {
+		t.Errorf("unexpected item operation %+v", ops[0])
+	}
+	if ops[1].OperationObject != "1" || ops[1].OpSeverity["severity"] != "4" {
+		t.Errorf("unexpected trigger operation %+v", ops[1])
+	}
+
+	// round trip through the api representation
+	raw, err := json.Marshal(overrides)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded lldOverrides
+	if err := json.Unmarshal(raw, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if err := d.Set("override", flattenlldOverrides(decoded)); err != nil {
+		t.Fatal(err)
+	}
+	if regenerated := lldGenerateOverrides(d); !reflect.DeepEqual(regenerated, overrides) {
+		t.Errorf("round trip mismatch\nexpected %+v\ngot      %+v", overrides, regenerated)
+	}
+}
+
+func TestLLDOverrideOptionEmptyArray(t *testing.T) {
+	var op lldOverrideOperation
+	if err := json.Unmarshal([]byte(`{"operationobject":"2","operator":"0","value":"","opstatus":[],"opdiscover":{"discover":"1"}}`), &op); err != nil {
+		t.Fatal(err)
+	}
+	if len(op.OpStatus) != 0 || op.OpDiscover["discover"] != "1" {
+		t.Errorf("unexpected operation %+v", op)
+	}
+}
+
+func TestLLDValidateOverrideOperation(t *testing.T) {
+	op := func(object string, options map[string]interface{}) map[string]interface{} {
+		res := map[string]interface{}{
+			"object": object, "status": "", "discover": "", "delay": "", "history": "",
+			"trends": "", "severity": "", "inventory_mode": "",
+			"tag":         schema.NewSet(schema.HashString, []interface{}{}),
+			"templateids": schema.NewSet(schema.HashString, []interface{}{}),
+		}
+		for k, v := range options {
+			res[k] = v
+		}
+		return res
+	}
+
+	cases := []struct {
+		op    map[string]interface{}
+		valid bool
+	}{
+		{op("item", map[string]interface{}{"history": "1d", "status": "disabled"}), true},
+		{op("trigger", map[string]interface{}{"severity": "high"}), true},
+		{op("graph", map[string]interface{}{"discover": "no"}), true},
+		{op("host", map[string]interface{}{"inventory_mode": "automatic", "templateids": schema.NewSet(schema.HashString, []interface{}{"10001"})}), true},
+		{op("graph", map[string]interface{}{"status": "disabled"}), false},
+		{op("item", map[string]interface{}{"severity": "high"}), false},
+		{op("trigger", map[string]interface{}{"templateids": schema.NewSet(schema.HashString, []interface{}{"10001"})}), false},
+	}
+	for i, tc := range cases {
+		err := lldValidateOverrideOperation(tc.op)
+		if (err == nil) != tc.valid {
+			t.Errorf("case %d: expected valid=%v, got %v", i, tc.valid, err)
+		}
+	}
+}
+
+func TestLLDOverrideSchemaLookups(t *testing.T) {
+	operation := lldOverrideSchema.Elem.(*schema.Resource).Schema["operation"].Elem.(*schema.Resource).Schema
+	for attr, value := range map[string]string{
+		"severity":       "high",
+		"inventory_mode": "automatic",
+		"object":         "host",
+		"status":         "disabled",
+	} {
+		if _, errs := operation[attr].ValidateFunc(value, attr); len(errs) > 0 {
+			t.Errorf("%s: expected %q to be valid, got %v", attr, value, errs)
+		}
+	}
+}
+
+func TestLLDOverrideCustomizeDiff(t *testing.T) {
+	api := &zabbix.API{Config: zabbix.Config{Version: 60000}}
+	override := func(steps ...int) []interface{} {
+		list := []interface{}{}
+		for _, step := range steps {
+			list = append(list, map[string]interface{}{"name": fmt.Sprintf("step %d", step), "step": step})
+		}
+		return list
+	}
+
+	cases := []struct {
+		steps []int
+		err   string
+	}{
+		{[]int{1, 2, 5}, ""},
+		{[]int{2, 1}, "override 1: step 1 must be greater than the previous override step 2"},
+		{[]int{1, 1}, "override 1: step 1 must be greater than the previous override step 1"},
+	}
+
+	for i, tc := range cases {
+		config := map[string]interface{}{"hostid": "1", "key": "k", "name": "rule", "override": override(tc.steps...)}
+		_, err := resourceLLDAgent().Diff(nil, terraform.NewResourceConfigRaw(config), api)
+		switch {
+		case tc.err == "" && err != nil:
+			t.Errorf("case %d: unexpected error %s", i, err)
+		case tc.err != "" && (err == nil || !strings.Contains(err.Error(), tc.err)):
+			t.Errorf("case %d: expected error %q, got %v", i, tc.err, err)
+		}
+	}
+}
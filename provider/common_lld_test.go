@@ -0,0 +1,167 @@
+package provider
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/terraform"
+	"github.com/tpretz/go-zabbix-api"
+)
+
+func TestLLDValidateFilter(t *testing.T) {
+	condition := func(formulaid, operator, value string) map[string]interface{} {
+		c := map[string]interface{}{"macro": "{#NAME}", "operator": operator, "value": value}
+		if formulaid != "" {
+			c["formulaid"] = formulaid
+		}
+		return c
+	}
+
+	cases := []struct {
+		config map[string]interface{}
+		err    string
+	}{
+		{map[string]interface{}{
+			"condition": []interface{}{condition("", "exists", "")},
+		}, ""},
+		{map[string]interface{}{
+			"condition": []interface{}{condition("", "match", "")},
+		}, "operator match requires a value"},
+		{map[string]interface{}{
+			"evaltype":  "custom",
+			"formula":   "A or (B and not C)",
+			"condition": []interface{}{condition("A", "match", "^a"), condition("B", "notexists", ""), condition("C", "notmatch", "^c")},
+		}, ""},
+		{map[string]interface{}{
+			"evaltype":  "custom",
+			"formula":   "A or B",
+			"condition": []interface{}{condition("A", "match", "^a")},
+		}, "formula references undefined condition B"},
+		{map[string]interface{}{
+			"evaltype":  "custom",
+			"formula":   "A",
+			"condition": []interface{}{condition("A", "match", "^a"), condition("B", "match", "^b")},
+		}, "condition B is not used in formula"},
+		{map[string]interface{}{
+			"evaltype":  "custom",
+			"formula":   "A",
+			"condition": []interface{}{condition("", "match", "^a")},
+		}, "formulaid is required"},
+		{map[string]interface{}{
+			"condition": []interface{}{condition("A", "match", "^a"), condition("A", "match", "^b")},
+		}, "formulaid A is used more than once"},
+		{map[string]interface{}{
+			"override": []interface{}{map[string]interface{}{
+				"name":      "o",
+				"step":      1,
+				"evaltype":  "custom",
+				"formula":   "A and B",
+				"condition": []interface{}{condition("A", "match", "^a")},
+			}},
+		}, "override.0.formula references undefined condition B"},
+	}
+
+	for i, tc := range cases {
+		tc.config["hostid"] = "1"
+		tc.config["key"] = "trap.discovery"
+		tc.config["name"] = "Discovery"
+
+		_, err := resourceLLDTrapper().Diff(nil, terraform.NewResourceConfigRaw(tc.config), nil)
+		switch {
+		case tc.err == "" && err != nil:
+			t.Errorf("case %d: unexpected error %s", i, err)
+		case tc.err != "" && err == nil:
+			t.Errorf("case %d: expected error %q", i, tc.err)
+		case tc.err != "" && !strings.Contains(err.Error(), tc.err):
+			t.Errorf("case %d: expected error %q, got %q", i, tc.err, err)
+		}
+	}
+}
+
+func TestLLDLifetimeCustomizeDiff(t *testing.T) {
+	api60 := &zabbix.API{Config: zabbix.Config{Version: 60000}}
+	api70 := &zabbix.API{Config: zabbix.Config{Version: 70000}}
+
+	cases := []struct {
+		config map[string]interface{}
+		api    *zabbix.API
+		err    string
+	}{
+		{map[string]interface{}{}, api60, ""},
+		{map[string]interface{}{"lifetime_type": "never"}, api60, "require zabbix 7.0"},
+		{map[string]interface{}{"lifetime_type": "never"}, api70, ""},
+		{map[string]interface{}{"enabled_lifetime_type": "after", "enabled_lifetime": "7d"}, api70, ""},
+		{map[string]interface{}{"lifetime_type": "immediately", "enabled_lifetime_type": "after"}, api70, "must be never"},
+		{map[string]interface{}{"enabled_lifetime": "7d"}, api70, "requires enabled_lifetime_type after"},
+	}
+
+	for i, tc := range cases {
+		tc.config["hostid"] = "1"
+		tc.config["key"] = "trap.discovery"
+		tc.config["name"] = "Discovery"
+
+		_, err := resourceLLDTrapper().Diff(nil, terraform.NewResourceConfigRaw(tc.config), tc.api)
+		switch {
+		case tc.err == "" && err != nil:
+			t.Errorf("case %d: unexpected error %s", i, err)
+		case tc.err != "" && err == nil:
+			t.Errorf("case %d: expected error %q", i, tc.err)
+		case tc.err != "" && !strings.Contains(err.Error(), tc.err):
+			t.Errorf("case %d: expected error %q, got %q", i, tc.err, err)
+		}
+	}
+}
+
+func TestLLDGenerateLifetime(t *testing.T) {
+	d := resourceLLDTrapper().Data(nil)
+	d.Set("lifetime", "30d")
+	d.Set("lifetime_type", "after")
+	d.Set("enabled_lifetime_type", "after")
+	d.Set("enabled_lifetime", "7d")
+
+	lld := buildLLDObject(d, false)
+	lldGenerateLifetime(d, lld)
+	if lld.LifeTimeType != "0" || lld.LifeTime != "30d" || lld.EnabledLifeTimeType != "0" || lld.EnabledLifeTime != "7d" {
+		t.Errorf("unexpected lifetime fields %q %q %q %q", lld.LifeTimeType, lld.LifeTime, lld.EnabledLifeTimeType, lld.EnabledLifeTime)
+	}
+
+	// periods are only sent with an after type
+	d.Set("lifetime_type", "immediately")
+	d.Set("enabled_lifetime_type", "never")
+	lld = buildLLDObject(d, false)
+	lldGenerateLifetime(d, lld)
+	if lld.LifeTimeType != "2" || lld.LifeTime != "" || lld.EnabledLifeTimeType != "" || lld.EnabledLifeTime != "" {
+		t.Errorf("unexpected lifetime fields %q %q %q %q", lld.LifeTimeType, lld.LifeTime, lld.EnabledLifeTimeType, lld.EnabledLifeTime)
+	}
+}
+
+func TestLLDPrototype(t *testing.T) {
+	d := resourceProtoLLDTrapper().Data(nil)
+	d.Set("ruleid", "100")
+	d.Set("discover", false)
+	d.Set("enabled", false)
+	d.Set("description", "nested")
+
+	lld := buildLLDObject(d, true)
+	if lld.RuleID != "100" || lld.Discover != "1" || lld.Status != "1" || lld.Description != "nested" {
+		t.Errorf("unexpected prototype fields %q %q %q %q", lld.RuleID, lld.Discover, lld.Status, lld.Description)
+	}
+
+	// rule id and discover are only sent on prototypes
+	lld = buildLLDObject(d, false)
+	if lld.RuleID != "" || lld.Discover != "" {
+		t.Errorf("unexpected rule fields %q %q", lld.RuleID, lld.Discover)
+	}
+
+	config := map[string]interface{}{"hostid": "1", "ruleid": "100", "key": "trap.discovery[{#NAME}]", "name": "Discovery {#NAME}"}
+	for _, version := range []int{60000, 70000, 70200} {
+		api := &zabbix.API{Config: zabbix.Config{Version: version}}
+		if _, err := resourceProtoLLDTrapper().Diff(nil, terraform.NewResourceConfigRaw(config), api); err == nil || !strings.Contains(err.Error(), "require zabbix 7.4") {
+			t.Errorf("%d: expected version error, got %v", version, err)
+		}
+	}
+	api74 := &zabbix.API{Config: zabbix.Config{Version: 70400}}
+	if _, err := resourceProtoLLDTrapper().Diff(nil, terraform.NewResourceConfigRaw(config), api74); err != nil {
+		t.Errorf("unexpected error %s", err)
+	}
+}
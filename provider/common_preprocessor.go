@@ -0,0 +1,281 @@
+package provider
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/validation"
+)
+
+// preprocessor step types
+var PREPROCESSOR_TYPES = map[string]string{
+	"multiplier":                  "1",
+	"rtrim":                       "2",
+	"ltrim":                       "3",
+	"trim":                        "4",
+	"regex":                       "5",
+	"bool_to_decimal":             "6",
+	"octal_to_decimal":            "7",
+	"hex_to_decimal":              "8",
+	"simple_change":               "9",
+	"change_per_second":           "10",
+	"xmlpath":                     "11",
+	"jsonpath":                    "12",
+	"in_range":                    "13",
+	"matches_regex":               "14",
+	"not_matches_regex":           "15",
+	"check_json_error":            "16",
+	"check_xml_error":             "17",
+	"check_regex_error":           "18",
+	"discard_unchanged":           "19",
+	"discard_unchanged_heartbeat": "20",
+	"javascript":                  "21",
+	"prometheus_pattern":          "22",
+	"prometheus_to_json":          "23",
+	"csv_to_json":                 "24",
+	"str_replace":                 "25",
+	"check_unsupported":           "26",
+	"xml_to_json":                 "27",
+	"snmp_walk_value":             "28",
+	"snmp_walk_to_json":           "29",
+	"snmp_get_value":              "30",
+}
+var PREPROCESSOR_TYPES_REV = map[string]string{}
+var PREPROCESSOR_TYPES_ARR = []string{}
+
+// preprocessor error handlers
+var PREPROCESSOR_ERROR_HANDLERS = map[string]string{
+	"default":   "0",
+	"discard":   "1",
+	"set_value": "2",
+	"set_error": "3",
+}
+var PREPROCESSOR_ERROR_HANDLERS_REV = map[string]string{}
+var PREPROCESSOR_ERROR_HANDLERS_ARR = []string{}
+
+// preprocessorParamCount accepted params per step type, max -1 for unbounded
+type preprocessorParamCount struct {
+	min, max, step int
+}
+
+var PREPROCESSOR_PARAMS = map[string]preprocessorParamCount{
+	"multiplier":                  {1, 1, 1},
+	"rtrim":                       {1, 1, 1},
+	"ltrim":                       {1, 1, 1},
+	"trim":                        {1, 1, 1},
+	"regex":                       {2, 2, 1},
+	"bool_to_decimal":             {0, 0, 1},
+	"octal_to_decimal":            {0, 0, 1},
+	"hex_to_decimal":              {0, 0, 1},
+	"simple_change":               {0, 0, 1},
+	"change_per_second":           {0, 0, 1},
+	"xmlpath":                     {1, 1, 1},
+	"jsonpath":                    {1, 1, 1},
+	"in_range":                    {2, 2, 1},
+	"matches_regex":               {1, 1, 1},
+	"not_matches_regex":           {1, 1, 1},
+	"check_json_error":            {1, 1, 1},
+	"check_xml_error":             {1, 1, 1},
+	"check_regex_error":           {2, 2, 1},
+	"discard_unchanged":           {0, 0, 1},
+	"discard_unchanged_heartbeat": {1, 1, 1},
+	"javascript":                  {1, 1, 1},
+	"prometheus_pattern":          {2, 3, 1},
+	"prometheus_to_json":          {1, 1, 1},
+	"csv_to_json":                 {3, 3, 1},
+	"str_replace":                 {2, 2, 1},
+	"check_unsupported":           {0, 2, 1},
+	"xml_to_json":                 {0, 0, 1},
+	"snmp_walk_value":             {2, 2, 1},
+	"snmp_walk_to_json":           {3, -1, 3},
+	"snmp_get_value":              {1, 1, 1},
+}
+
+// param positions that may be left empty, all others must be set
+var PREPROCESSOR_OPTIONAL_PARAMS = map[string][]int{
+	"in_range":           {0, 1},
+	"prometheus_pattern": {2},
+	"str_replace":        {1},
+}
+
+// generate the above structures
+var _ = func() bool {
+	for k, v := range PREPROCESSOR_TYPES {
+		PREPROCESSOR_TYPES_REV[v] = k
+		PREPROCESSOR_TYPES_ARR = append(PREPROCESSOR_TYPES_ARR, k)
+	}
+	for k, v := range PREPROCESSOR_ERROR_HANDLERS {
+		PREPROCESSOR_ERROR_HANDLERS_REV[v] = k
+		PREPROCESSOR_ERROR_HANDLERS_ARR = append(PREPROCESSOR_ERROR_HANDLERS_ARR, k)
+	}
+	sort.Strings(PREPROCESSOR_TYPES_ARR)
+	sort.Strings(PREPROCESSOR_ERROR_HANDLERS_ARR)
+	return false
+}()
+
+var preprocessorNumeric = regexp.MustCompile("^[0-9]+$")
+
+// preprocessorSchema shared schema for item and lld preprocessor blocks
+func preprocessorSchema() *schema.Schema {
+	return &schema.Schema{
+		Type:     schema.TypeList,
+		Optional: true,
+		Elem: &schema.Resource{
+			Schema: map[string]*schema.Schema{
+				"id": &schema.Schema{
+					Type:     schema.TypeString,
+					Computed: true,
+				},
+				"type": &schema.Schema{
+					Type:             schema.TypeString,
+					Required:         true,
+					Description:      "Preprocessor type, zabbix identifier number or one of: " + strings.Join(PREPROCESSOR_TYPES_ARR, ", "),
+					ValidateFunc:     validateNamedOrNumeric(PREPROCESSOR_TYPES_ARR),
+					DiffSuppressFunc: preprocessorTypeDiffSuppress,
+				},
+				"params": &schema.Schema{
+					Type: schema.TypeList,
+					Elem: &schema.Schema{
+						Type: schema.TypeString,
+					},
+					Optional:    true,
+					Description: "Preprocessor parameters",
+				},
+				"error_handler": &schema.Schema{
+					Type:             schema.TypeString,
+					Optional:         true,
+					Default:          "",
+					Description:      "Error handler, zabbix identifier number or one of: " + strings.Join(PREPROCESSOR_ERROR_HANDLERS_ARR, ", "),
+					ValidateFunc:     validateNamedOrNumeric(PREPROCESSOR_ERROR_HANDLERS_ARR),
+					DiffSuppressFunc: preprocessorErrorHandlerDiffSuppress,
+				},
+				"error_handler_params": &schema.Schema{
+					Type:     schema.TypeString,
+					Optional: true,
+					Default:  "",
+				},
+			},
+		},
+	}
+}
+
+// validateNamedOrNumeric accept a known name, or a raw zabbix identifier
+func validateNamedOrNumeric(names []string) schema.SchemaValidateFunc {
+	return validation.Any(
+		validation.StringInSlice(names, false),
+		validation.StringMatch(preprocessorNumeric, "must be numeric"),
+	)
+}
+
+// preprocessorTypeId resolve a step type name to its zabbix identifier
+func preprocessorTypeId(v string) string {
+	if id, ok := PREPROCESSOR_TYPES[v]; ok {
+		return id
+	}
+	return v
+}
+
+// preprocessorTypeName resolve a zabbix identifier to its step type name
+func preprocessorTypeName(v string) string {
+	if name, ok := PREPROCESSOR_TYPES_REV[v]; ok {
+		return name
+	}
+	return v
+}
+
+// preprocessorErrorHandlerId resolve an error handler name to its zabbix identifier
+func preprocessorErrorHandlerId(v string) string {
+	if id, ok := PREPROCESSOR_ERROR_HANDLERS[v]; ok {
+		return id
+	}
+	return v
+}
+
+// preprocessorErrorHandlerName resolve a zabbix identifier to its error handler name
+func preprocessorErrorHandlerName(v string) string {
+	if name, ok := PREPROCESSOR_ERROR_HANDLERS_REV[v]; ok {
+		return name
+	}
+	return v
+}
+
+// named and numeric forms are equivalent
+func preprocessorTypeDiffSuppress(k, old, new string, d *schema.ResourceData) bool {
+	return preprocessorTypeId(old) == preprocessorTypeId(new)
+}
+
+// named and numeric forms are equivalent, unset matches default
+func preprocessorErrorHandlerDiffSuppress(k, old, new string, d *schema.ResourceData) bool {
+	normalise := func(v string) string {
+		if v == "" {
+			return PREPROCESSOR_ERROR_HANDLERS["default"]
+		}
+		return preprocessorErrorHandlerId(v)
+	}
+	return normalise(old) == normalise(new)
+}
+
+// resourceGetter attribute access shared by ResourceData and ResourceDiff
+type resourceGetter interface {
+	Get(string) interface{}
+}
+
+// preprocessorStepParams params of a step as configured, before joining
+func preprocessorStepParams(d resourceGetter, prefix string) []string {
+	params := d.Get(prefix + "params").([]interface{})
+	lst := make([]string, len(params))
+	for i := 0; i < len(params); i++ {
+		lst[i] = params[i].(string)
+	}
+	return lst
+}
+
+// preprocessorCustomizeDiff validate preprocessor steps at plan time
+func preprocessorCustomizeDiff(d *schema.ResourceDiff, m interface{}) error {
+	// item and lld preprocessor schemas are identical
+	steps := itemGeneratePreprocessors(d)
+
+	for i := range steps {
+		prefix := fmt.Sprintf("preprocessor.%d.", i)
+		if !preprocessorStepKnown(d, prefix) {
+			continue
+		}
+		if err := validatePreprocessorStep(steps[i], preprocessorStepParams(d, prefix)); err != nil {
+			return fmt.Errorf("preprocessor %d: %s", i, err)
+		}
+	}
+	return validatePreprocessorCombination(steps)
+}
+
+// preprocessorStepKnown skip validation of steps with values known only at apply
+func preprocessorStepKnown(d *schema.ResourceDiff, prefix string) bool {
+	for _, k := range []string{"type", "params", "error_handler", "error_handler_params"} {
+		if !d.NewValueKnown(prefix + k) {
+			return false
+		}
+	}
+	for j := 0; j < d.Get(prefix+"params.#").(int); j++ {
+		if !d.NewValueKnown(fmt.Sprintf("%sparams.%d", prefix, j)) {
+			return false
+		}
+	}
+	return true
+}
+
+// preprocessorCheckParams check a params count against the expected range
+func preprocessorCheckParams(name string, n int, expected preprocessorParamCount) error {
+	if n < expected.min || (expected.max >= 0 && n > expected.max) || (n-expected.min)%expected.step != 0 {
+		switch {
+		case expected.max < 0:
+			return fmt.Errorf("%s expects a multiple of %d params, got %d", name, expected.step, n)
+		case expected.min == expected.max:
+			return fmt.Errorf("%s expects %d params, got %d", name, expected.min, n)
+		default:
+			return fmt.Errorf("%s expects between %d and %d params, got %d", name, expected.min, expected.max, n)
+		}
+	}
+	return nil
+}
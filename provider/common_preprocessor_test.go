@@ -0,0 +1,95 @@
+package provider
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/terraform"
+	"github.com/tpretz/go-zabbix-api"
+)
+
+func TestPreprocessorCheckParams(t *testing.T) {
+	cases := []struct {
+		name  string
+		count int
+		valid bool
+	}{
+		{"jsonpath", 1, true},
+		{"jsonpath", 0, false},
+		{"regex", 2, true},
+		{"regex", 1, false},
+		{"simple_change", 0, true},
+		{"simple_change", 1, false},
+		{"prometheus_pattern", 2, true},
+		{"prometheus_pattern", 3, true},
+		{"prometheus_pattern", 4, false},
+		{"snmp_walk_to_json", 3, true},
+		{"snmp_walk_to_json", 6, true},
+		{"snmp_walk_to_json", 4, false},
+		{"snmp_walk_to_json", 0, false},
+	}
+
+	for _, c := range cases {
+		err := preprocessorCheckParams(c.name, c.count, PREPROCESSOR_PARAMS[c.name])
+		if c.valid && err != nil {
+			t.Errorf("expected %s with %d params to be valid, got %s", c.name, c.count, err)
+		}
+		if !c.valid && err == nil {
+			t.Errorf("expected %s with %d params to be invalid", c.name, c.count)
+		}
+	}
+}
+
+func TestPreprocessorNames(t *testing.T) {
+	if id := preprocessorTypeId("jsonpath"); id != "12" {
+		t.Errorf("expected jsonpath to map to 12, got %s", id)
+	}
+	if id := preprocessorTypeId("12"); id != "12" {
+		t.Errorf("expected numeric type to pass through, got %s", id)
+	}
+	if name := preprocessorTypeName("20"); name != "discard_unchanged_heartbeat" {
+		t.Errorf("expected 20 to map to discard_unchanged_heartbeat, got %s", name)
+	}
+	if !preprocessorErrorHandlerDiffSuppress("", "", "default", nil) {
+		t.Error("expected unset error handler to match default")
+	}
+	if preprocessorErrorHandlerDiffSuppress("", "discard", "3", nil) {
+		t.Error("expected discard and set_error to differ")
+	}
+	for name := range PREPROCESSOR_TYPES {
+		if _, ok := PREPROCESSOR_PARAMS[name]; !ok {
+			t.Errorf("missing params definition for %s", name)
+		}
+	}
+}
+
+func TestPreprocessorCustomizeDiff(t *testing.T) {
+	api := &zabbix.API{Config: zabbix.Config{Version: 60000}}
+	cases := []struct {
+		step   string
+		params []interface{}
+		err    string
+	}{
+		{"javascript", []interface{}{"var a = 1;\nreturn a;"}, ""},
+		{"javascript", []interface{}{"if (value) {\n  return 1;\n}\nreturn 0;"}, ""},
+		{"javascript", []interface{}{"if (value) {", "  return 1;", "}", "return 0;"}, ""},
+		{"javascript", []interface{}{"return (value;"}, "preprocessor 0: javascript"},
+		{"str_replace", []interface{}{"a", ""}, ""},
+		{"in_range", []interface{}{"", "10"}, ""},
+		{"str_replace", []interface{}{"", "b"}, "preprocessor 0: str_replace: param 1 must not be empty"},
+	}
+
+	for i, tc := range cases {
+		config := map[string]interface{}{
+			"hostid": "1", "key": "k", "name": "item", "valuetype": "text",
+			"preprocessor": []interface{}{map[string]interface{}{"type": tc.step, "params": tc.params}},
+		}
+		_, err := resourceItemTrapper().Diff(nil, terraform.NewResourceConfigRaw(config), api)
+		switch {
+		case tc.err == "" && err != nil:
+			t.Errorf("case %d: unexpected error %s", i, err)
+		case tc.err != "" && (err == nil || !strings.Contains(err.Error(), tc.err)):
+			t.Errorf("case %d: expected error %q, got %v", i, tc.err, err)
+		}
+	}
+}
@@ -0,0 +1,458 @@
+package provider
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"regexp/syntax"
+	"strconv"
+	"strings"
+
+	"github.com/tpretz/go-zabbix-api"
+)
+
+// user, lld and expression macros are expanded server side, skip content checks
+var preprocessorMacroRef = regexp.MustCompile(`\{[$#?][^}]*\}`)
+
+var preprocessorHeartbeat = regexp.MustCompile(`^[0-9]+[smhdw]?$`)
+var preprocessorSnmpFormat = regexp.MustCompile(`^[0-3]$`)
+
+// step types of which only one may be present
+var preprocessorExclusiveGroups = [][]string{
+	{"discard_unchanged", "discard_unchanged_heartbeat"},
+	{"simple_change", "change_per_second"},
+}
+
+// prometheus pattern grammar, metric{label="value",...} == value
+var prometheusPattern = func() *regexp.Regexp {
+	label := `[a-zA-Z_][a-zA-Z0-9_]*\s*(?:=~|!~|!=|=)\s*"(?:[^"\\]|\\.)*"`
+	value := `(?:[-+]?(?:[0-9]+(?:\.[0-9]*)?|\.[0-9]+)(?:[eE][-+]?[0-9]+)?|NaN|[-+]?Inf)`
+	return regexp.MustCompile(`^\s*([a-zA-Z_:][a-zA-Z0-9_:]*)?\s*(\{\s*(?:` + label + `(?:\s*,\s*` + label + `)*\s*,?)?\s*\})?\s*(?:==\s*` + value + `)?\s*$`)
+}()
+var prometheusLabelName = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
+var prometheusFunctions = []string{"avg", "count", "max", "min", "sum"}
+
+// validatePreprocessorStep check a single step, params as configured and error handling
+func validatePreprocessorStep(step zabbix.Preprocessor, params []string) error {
+	name := preprocessorTypeName(step.Type)
+
+	// scripts were written one element per line to match the split state, join them back
+	if name == "javascript" && len(params) > 1 {
+		params = []string{strings.Join(params, "\n")}
+	}
+
+	if expected, ok := PREPROCESSOR_PARAMS[name]; ok {
+		if err := preprocessorCheckParams(name, len(params), expected); err != nil {
+			return err
+		}
+	}
+
+	for i, v := range params {
+		if strings.TrimSpace(v) == "" && !preprocessorParamOptional(name, i) {
+			return fmt.Errorf("%s: param %d must not be empty", name, i+1)
+		}
+	}
+
+	if err := validatePreprocessorParams(name, params); err != nil {
+		return fmt.Errorf("%s: %s", name, err)
+	}
+
+	switch preprocessorErrorHandlerName(step.ErrorHandler) {
+	case "", "default", "discard":
+		if step.ErrorHandlerParams != "" {
+			return fmt.Errorf("%s: error_handler_params only apply to the set_value and set_error handlers", name)
+		}
+	case "set_error":
+		if step.ErrorHandlerParams == "" {
+			return fmt.Errorf("%s: set_error handler requires error_handler_params", name)
+		}
+	}
+	return nil
+}
+
+// preprocessorParamOptional param position may be left empty
+func preprocessorParamOptional(name string, i int) bool {
+	for _, j := range PREPROCESSOR_OPTIONAL_PARAMS[name] {
+		if i == j {
+			return true
+		}
+	}
+	return false
+}
+
+// validatePreprocessorParams type specific parameter checks
+func validatePreprocessorParams(name string, params []string) error {
+	// skip checks on anything expanded by the server
+	check := func(i int, f func(string) error) error {
+		if i >= len(params) || params[i] == "" || preprocessorMacroRef.MatchString(params[i]) {
+			return nil
+		}
+		if err := f(params[i]); err != nil {
+			return fmt.Errorf("param %d: %s", i+1, err)
+		}
+		return nil
+	}
+
+	switch name {
+	case "multiplier":
+		return check(0, validatePreprocessorNumber)
+	case "in_range":
+		if len(params) == 2 && params[0] == "" && params[1] == "" {
+			return errors.New("at least one of min and max must be set")
+		}
+		if err := check(0, validatePreprocessorNumber); err != nil {
+			return err
+		}
+		return check(1, validatePreprocessorNumber)
+	case "discard_unchanged_heartbeat":
+		return check(0, func(v string) error {
+			if !preprocessorHeartbeat.MatchString(v) {
+				return fmt.Errorf("%q is not a valid heartbeat, expected seconds or a time suffix", v)
+			}
+			return nil
+		})
+	case "regex", "matches_regex", "not_matches_regex", "check_regex_error":
+		return check(0, validatePreprocessorRegex)
+	case "jsonpath", "check_json_error":
+		return check(0, validateJSONPath)
+	case "javascript":
+		return check(0, validateJavaScript)
+	case "prometheus_pattern":
+		return validatePrometheusParams(params, check)
+	case "check_unsupported":
+		if len(params) == 2 {
+			if err := check(0, func(v string) error {
+				if v != "-1" && v != "0" && v != "1" {
+					return fmt.Errorf("%q is not a valid match scope, one of: -1, 0, 1", v)
+				}
+				return nil
+			}); err != nil {
+				return err
+			}
+			return check(1, validatePreprocessorRegex)
+		}
+	case "snmp_walk_value":
+		return check(1, validateSnmpFormat)
+	case "snmp_walk_to_json":
+		for i := 2; i < len(params); i += 3 {
+			if err := check(i, validateSnmpFormat); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// validatePreprocessorCombination reject step combinations the server forbids
+func validatePreprocessorCombination(steps zabbix.Preprocessors) error {
+	for _, group := range preprocessorExclusiveGroups {
+		found := []string{}
+		for _, step := range steps {
+			name := preprocessorTypeName(step.Type)
+			for _, g := range group {
+				if name == g {
+					found = append(found, name)
+				}
+			}
+		}
+		if len(found) > 1 {
+			return fmt.Errorf("only one of %s may be used, found: %s", strings.Join(group, ", "), strings.Join(found, ", "))
+		}
+	}
+	return nil
+}
+
+func validatePreprocessorNumber(v string) error {
+	if _, err := strconv.ParseFloat(v, 64); err != nil {
+		return fmt.Errorf("%q is not numeric", v)
+	}
+	return nil
+}
+
+func validateSnmpFormat(v string) error {
+	if !preprocessorSnmpFormat.MatchString(v) {
+		return fmt.Errorf("%q is not a valid format, expected 0-3", v)
+	}
+	return nil
+}
+
+// validatePreprocessorRegex compile a regex, zabbix uses PCRE so only
+// structural errors are reported, not RE2 unsupported syntax
+func validatePreprocessorRegex(v string) error {
+	_, err := syntax.Parse(v, syntax.Perl)
+	if err == nil {
+		return nil
+	}
+	if serr, ok := err.(*syntax.Error); ok {
+		switch serr.Code {
+		case syntax.ErrMissingBracket, syntax.ErrMissingParen, syntax.ErrUnexpectedParen,
+			syntax.ErrMissingRepeatArgument, syntax.ErrInvalidCharRange, syntax.ErrTrailingBackslash:
+			return fmt.Errorf("invalid regex: %s", serr)
+		}
+	}
+	return nil
+}
+
+// validatePrometheusParams pattern, and legacy output label or mode / output pair
+func validatePrometheusParams(params []string, check func(int, func(string) error) error) error {
+	err := check(0, func(v string) error {
+		m := prometheusPattern.FindStringSubmatch(v)
+		if m == nil || (m[1] == "" && m[2] == "") {
+			return fmt.Errorf("%q is not a valid prometheus pattern", v)
+		}
+		return nil
+	})
+	if err != nil || len(params) < 3 {
+		return err
+	}
+
+	switch params[1] {
+	case "value":
+		return nil
+	case "label":
+		return check(2, func(v string) error {
+			if !prometheusLabelName.MatchString(v) {
+				return fmt.Errorf("%q is not a valid label name", v)
+			}
+			return nil
+		})
+	case "function":
+		return check(2, func(v string) error {
+			for _, f := range prometheusFunctions {
+				if v == f {
+					return nil
+				}
+			}
+			return fmt.Errorf("%q is not a valid function, one of: %s", v, strings.Join(prometheusFunctions, ", "))
+		})
+	}
+	return fmt.Errorf("param 2: %q is not a valid output mode, one of: value, label, function", params[1])
+}
+
+var jsonPathFunctions = map[string]bool{
+	"avg": true, "first": true, "keys": true, "length": true, "max": true, "min": true, "sum": true,
+}
+var jsonPathIndexes = regexp.MustCompile(`^\s*-?[0-9]+\s*(?:,\s*-?[0-9]+\s*)*$`)
+var jsonPathSlice = regexp.MustCompile(`^\s*-?[0-9]*\s*:\s*-?[0-9]*\s*(?::\s*-?[0-9]*\s*)?$`)
+var jsonPathNames = regexp.MustCompile(`^\s*(?:'(?:[^'\\]|\\.)*'|"(?:[^"\\]|\\.)*")\s*(?:,\s*(?:'(?:[^'\\]|\\.)*'|"(?:[^"\\]|\\.)*")\s*)*$`)
+
+// validateJSONPath parse a zabbix JSONPath expression
+func validateJSONPath(path string) error {
+	if !strings.HasPrefix(path, "$") {
+		return errors.New("jsonpath must start with $")
+	}
+
+	for i := 1; i < len(path); {
+		switch path[i] {
+		case '.':
+			i++
+			if i < len(path) && path[i] == '.' {
+				i++
+			}
+			if i >= len(path) {
+				return fmt.Errorf("jsonpath ends with '.' at position %d", i)
+			}
+			if path[i] == '[' {
+				continue
+			}
+			if path[i] == '*' {
+				i++
+				continue
+			}
+
+			start := i
+			for i < len(path) && !strings.ContainsRune(".[]()'\" \t*", rune(path[i])) {
+				i++
+			}
+			if i == start {
+				return fmt.Errorf("jsonpath expected member name at position %d", i+1)
+			}
+			if i < len(path) && path[i] == '(' {
+				if !jsonPathFunctions[path[start:i]] {
+					return fmt.Errorf("jsonpath unknown function %q at position %d", path[start:i], start+1)
+				}
+				if !strings.HasPrefix(path[i:], "()") || i+2 != len(path) {
+					return fmt.Errorf("jsonpath function %q must be the final segment", path[start:i])
+				}
+				i += 2
+			}
+		case '[':
+			end, err := jsonPathBracketEnd(path, i)
+			if err != nil {
+				return err
+			}
+			inner := path[i+1 : end]
+			switch {
+			case strings.TrimSpace(inner) == "*":
+			case strings.HasPrefix(inner, "?(") && strings.HasSuffix(inner, ")") && len(inner) > 3:
+			case jsonPathNames.MatchString(inner), jsonPathIndexes.MatchString(inner), jsonPathSlice.MatchString(inner):
+			default:
+				return fmt.Errorf("jsonpath invalid bracket expression %q at position %d", inner, i+1)
+			}
+			i = end + 1
+		default:
+			return fmt.Errorf("jsonpath unexpected %q at position %d", path[i], i+1)
+		}
+	}
+	return nil
+}
+
+// jsonPathBracketEnd locate the bracket closing the one at start
+func jsonPathBracketEnd(path string, start int) (int, error) {
+	depth := 0
+	var quote byte
+	for i := start; i < len(path); i++ {
+		c := path[i]
+		switch {
+		case quote != 0:
+			if c == '\\' {
+				i++
+			} else if c == quote {
+				quote = 0
+			}
+		case c == '\'' || c == '"':
+			quote = c
+		case c == '[' || c == '(':
+			depth++
+		case c == ']' || c == ')':
+			depth--
+			if depth == 0 {
+				if c != ']' {
+					return 0, fmt.Errorf("jsonpath unbalanced ')' at position %d", i+1)
+				}
+				return i, nil
+			}
+		}
+	}
+	return 0, fmt.Errorf("jsonpath unterminated '[' at position %d", start+1)
+}
+
+var jsRegexKeywords = map[string]bool{
+	"case": true, "delete": true, "do": true, "else": true, "in": true, "instanceof": true,
+	"new": true, "of": true, "return": true, "throw": true, "typeof": true, "void": true,
+}
+
+// validateJavaScript structural syntax check, balanced brackets and
+// terminated strings, template literals, comments and regex literals
+func validateJavaScript(script string) error {
+	err := scanJavaScript(script, true)
+	if err == nil {
+		return nil
+	}
+	// telling a regex literal from a division needs a full parser, a script only
+	// failing when slashes may start a regex is treated as unknown rather than invalid
+	if scanJavaScript(script, false) == nil {
+		return nil
+	}
+	return err
+}
+
+// scanJavaScript single pass of the structural check, regex literals are
+// guessed from the preceding token when enabled, otherwise '/' is always division
+func scanJavaScript(script string, regex bool) error {
+	pairs := map[byte]byte{')': '(', ']': '[', '}': '{'}
+	type open struct {
+		c    byte
+		line int
+	}
+	stack := []open{}
+	line := 1
+	var prev byte
+	prevWord := ""
+
+	isIdent := func(c byte) bool {
+		return c == '_' || c == '$' || c >= 0x80 ||
+			(c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9')
+	}
+
+	for i := 0; i < len(script); i++ {
+		c := script[i]
+		next := byte(0)
+		if i+1 < len(script) {
+			next = script[i+1]
+		}
+
+		switch {
+		case c == '\n':
+			line++
+			continue
+		case c == ' ' || c == '\t' || c == '\r':
+			continue
+		case c == '/' && next == '/':
+			for i < len(script) && script[i] != '\n' {
+				i++
+			}
+			i--
+			continue
+		case c == '/' && next == '*':
+			end := strings.Index(script[i+2:], "*/")
+			if end < 0 {
+				return fmt.Errorf("javascript unterminated comment on line %d", line)
+			}
+			line += strings.Count(script[i:i+2+end], "\n")
+			i += end + 3
+			continue
+		case c == '\'' || c == '"' || c == '`':
+			start := line
+			i++
+			for ; i < len(script) && script[i] != c; i++ {
+				if script[i] == '\\' {
+					i++
+				} else if script[i] == '\n' {
+					if c != '`' {
+						return fmt.Errorf("javascript unterminated string on line %d", start)
+					}
+					line++
+				}
+			}
+			if i >= len(script) {
+				return fmt.Errorf("javascript unterminated string on line %d", start)
+			}
+		case c == '/' && regex && (prev == 0 || strings.IndexByte("(,=:[!&|?{};+-*%<>~^", prev) >= 0 || jsRegexKeywords[prevWord]):
+			class := false
+			i++
+			for ; i < len(script); i++ {
+				if script[i] == '\\' {
+					i++
+				} else if script[i] == '\n' {
+					break
+				} else if script[i] == '[' {
+					class = true
+				} else if script[i] == ']' {
+					class = false
+				} else if script[i] == '/' && !class {
+					break
+				}
+			}
+			if i >= len(script) || script[i] != '/' {
+				return fmt.Errorf("javascript unterminated regex on line %d", line)
+			}
+			for i+1 < len(script) && isIdent(script[i+1]) {
+				i++
+			}
+		case c == '(' || c == '[' || c == '{':
+			stack = append(stack, open{c, line})
+		case c == ')' || c == ']' || c == '}':
+			if len(stack) == 0 || stack[len(stack)-1].c != pairs[c] {
+				return fmt.Errorf("javascript unexpected '%c' on line %d", c, line)
+			}
+			stack = stack[:len(stack)-1]
+		case isIdent(c):
+			start := i
+			for i+1 < len(script) && isIdent(script[i+1]) {
+				i++
+			}
+			prevWord = script[start : i+1]
+			prev = 'a'
+			continue
+		}
+		prev = c
+		prevWord = ""
+	}
+
+	if len(stack) > 0 {
+		last := stack[len(stack)-1]
+		return fmt.Errorf("javascript unclosed '%c' from line %d", last.c, last.line)
+	}
+	return nil
+}
@@ -0,0 +1,134 @@
+package provider
+
+import (
+	"testing"
+
+	"github.com/tpretz/go-zabbix-api"
+)
+
+func TestValidateJSONPath(t *testing.T) {
+	cases := map[string]bool{
+		"$":                              true,
+		"$.data":                         true,
+		"$..name":                        true,
+		"$.data[0].value":                true,
+		"$.data[*]":                      true,
+		"$['a b']['c']":                  true,
+		"$.data[0,2]":                    true,
+		"$.data[1:3]":                    true,
+		"$.data[?(@.name == 'x[1]')].id": true,
+		"$.data.length()":                true,
+		"$[\"quoted\"].first()":          true,
+		"data":                           false,
+		"$.":                             false,
+		"$.data[":                        false,
+		"$.data[abc]":                    false,
+		"$.data.nope()":                  false,
+		"$.data.length().x":              false,
+		"$ data":                         false,
+	}
+
+	for v, valid := range cases {
+		err := validateJSONPath(v)
+		if valid && err != nil {
+			t.Errorf("expected %q to be valid, got %s", v, err)
+		}
+		if !valid && err == nil {
+			t.Errorf("expected %q to be invalid", v)
+		}
+	}
+}
+
+func TestValidateJavaScript(t *testing.T) {
+	cases := map[string]bool{
+		"return value * 2;":                                 true,
+		"var o = JSON.parse(value);\nreturn o['a'].b;":      true,
+		"// comment (\nreturn value.replace(/[)]+/g, '');":  true,
+		"/* { */ var a = `multi\nline ${value}`; return a;": true,
+		"var x = a / b / c; return x;":                      true,
+		"var a = 10, b = 2; return a++ / b;":                true,
+		"var a = 10; return a-- / (a / 2);":                 true,
+		"return [1, 2] / 3 + (value / 4);":                  true,
+		"return value.replace(/[(]/g, '') + (1);":           true,
+		"if (value) { return 1;":                            false,
+		"return value);":                                    false,
+		"return 'unterminated;":                             false,
+		"return [1, 2);":                                    false,
+		"/* never closed":                                   false,
+		"return (value / 2;":                                false,
+	}
+
+	for v, valid := range cases {
+		err := validateJavaScript(v)
+		if valid && err != nil {
+			t.Errorf("expected %q to be valid, got %s", v, err)
+		}
+		if !valid && err == nil {
+			t.Errorf("expected %q to be invalid", v)
+		}
+	}
+}
+
+func TestValidatePreprocessorStep(t *testing.T) {
+	cases := []struct {
+		step   zabbix.Preprocessor
+		params []string
+		valid  bool
+	}{
+		{zabbix.Preprocessor{Type: "1"}, []string{"0.001"}, true},
+		{zabbix.Preprocessor{Type: "1"}, []string{"{$MULT}"}, true},
+		{zabbix.Preprocessor{Type: "1"}, []string{"ten"}, false},
+		{zabbix.Preprocessor{Type: "20"}, []string{"1h"}, true},
+		{zabbix.Preprocessor{Type: "20"}, []string{"1 hour"}, false},
+		{zabbix.Preprocessor{Type: "5"}, []string{"^(\\w+)", "\\1"}, true},
+		{zabbix.Preprocessor{Type: "5"}, []string{"(?<=a)b", "\\0"}, true},
+		{zabbix.Preprocessor{Type: "5"}, []string{"^(\\w+", "\\1"}, false},
+		{zabbix.Preprocessor{Type: "12"}, []string{"$.a"}, true},
+		{zabbix.Preprocessor{Type: "12"}, []string{"a.b"}, false},
+		{zabbix.Preprocessor{Type: "22"}, []string{"cpu_seconds{mode=\"idle\"} == 1", "value", ""}, true},
+		{zabbix.Preprocessor{Type: "22"}, []string{"cpu_seconds{mode=\"idle\"}", "function", "sum"}, true},
+		{zabbix.Preprocessor{Type: "22"}, []string{"{mode=idle}", "value", ""}, false},
+		{zabbix.Preprocessor{Type: "22"}, []string{"cpu_seconds", "function", "median"}, false},
+		{zabbix.Preprocessor{Type: "21"}, []string{"var a = 1;\nreturn a;"}, true},
+		{zabbix.Preprocessor{Type: "21"}, []string{"if (value) {\n return 1;\n}"}, true},
+		{zabbix.Preprocessor{Type: "21"}, []string{"if (value) {\n return 1;\n"}, false},
+		{zabbix.Preprocessor{Type: "21"}, []string{"var a = 1;", "return a;"}, true},
+		{zabbix.Preprocessor{Type: "21"}, []string{"if (value) {", " return 1;"}, false},
+		{zabbix.Preprocessor{Type: "25"}, []string{"a", ""}, true},
+		{zabbix.Preprocessor{Type: "25"}, []string{"", "b"}, false},
+		{zabbix.Preprocessor{Type: "1"}, []string{" "}, false},
+		{zabbix.Preprocessor{Type: "13"}, []string{"", "10"}, true},
+		{zabbix.Preprocessor{Type: "13"}, []string{"-10", ""}, true},
+		{zabbix.Preprocessor{Type: "13"}, []string{"", ""}, false},
+		{zabbix.Preprocessor{Type: "13"}, []string{"-10", "10.5"}, true},
+		{zabbix.Preprocessor{Type: "13"}, []string{"low", "10"}, false},
+		{zabbix.Preprocessor{Type: "12", ErrorHandler: "3"}, []string{"$.a"}, false},
+		{zabbix.Preprocessor{Type: "12", ErrorHandler: "3", ErrorHandlerParams: "bad"}, []string{"$.a"}, true},
+		{zabbix.Preprocessor{Type: "12", ErrorHandler: "1", ErrorHandlerParams: "bad"}, []string{"$.a"}, false},
+	}
+
+	for _, c := range cases {
+		err := validatePreprocessorStep(c.step, c.params)
+		if c.valid && err != nil {
+			t.Errorf("expected %+v %q to be valid, got %s", c.step, c.params, err)
+		}
+		if !c.valid && err == nil {
+			t.Errorf("expected %+v %q to be invalid", c.step, c.params)
+		}
+	}
+}
+
+func TestValidatePreprocessorCombination(t *testing.T) {
+	ok := zabbix.Preprocessors{{Type: "12"}, {Type: "19"}, {Type: "10"}}
+	if err := validatePreprocessorCombination(ok); err != nil {
+		t.Errorf("expected valid combination, got %s", err)
+	}
+	throttle := zabbix.Preprocessors{{Type: "19"}, {Type: "discard_unchanged_heartbeat"}}
+	if err := validatePreprocessorCombination(throttle); err == nil {
+		t.Error("expected two throttling steps to be rejected")
+	}
+	change := zabbix.Preprocessors{{Type: "9"}, {Type: "10"}}
+	if err := validatePreprocessorCombination(change); err == nil {
+		t.Error("expected simple change and change per second to be rejected")
+	}
+}
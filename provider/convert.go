@@ -0,0 +1,1021 @@
+package provider
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+	"github.com/tpretz/go-zabbix-api"
+)
+
+// export file constants to api identifiers, exports before 4.4 use the
+// identifiers directly and pass through as is
+var CONVERT_ITEM_TYPES = map[string]string{
+	"ZABBIX_PASSIVE": "0",
+	"SNMPV1":         "1",
+	"TRAP":           "2",
+	"SIMPLE":         "3",
+	"SNMPV2":         "4",
+	"INTERNAL":       "5",
+	"SNMPV3":         "6",
+	"ZABBIX_ACTIVE":  "7",
+	"AGGREGATE":      "8",
+	"HTTP_TEST":      "9",
+	"EXTERNAL":       "10",
+	"ODBC":           "11",
+	"IPMI":           "12",
+	"SSH":            "13",
+	"TELNET":         "14",
+	"CALCULATED":     "15",
+	"JMX":            "16",
+	"SNMP_TRAP":      "17",
+	"DEPENDENT":      "18",
+	"HTTP_AGENT":     "19",
+	"SNMP_AGENT":     "20",
+	"SCRIPT":         "21",
+	"BROWSER":        "22",
+}
+
+var CONVERT_VALUE_TYPES = map[string]string{
+	"FLOAT":    "0",
+	"CHAR":     "1",
+	"LOG":      "2",
+	"UNSIGNED": "3",
+	"TEXT":     "4",
+	"BINARY":   "5",
+}
+
+var CONVERT_PRIORITIES = map[string]string{
+	"NOT_CLASSIFIED": "0",
+	"INFO":           "1",
+	"WARNING":        "2",
+	"AVERAGE":        "3",
+	"HIGH":           "4",
+	"DISASTER":       "5",
+}
+
+var CONVERT_STATUS = map[string]string{
+	"ENABLED":  "0",
+	"DISABLED": "1",
+}
+
+var CONVERT_DISCOVER = map[string]string{
+	"DISCOVER":    "0",
+	"NO_DISCOVER": "1",
+}
+
+var CONVERT_YES_NO = map[string]string{
+	"NO":  "0",
+	"YES": "1",
+}
+
+var CONVERT_RECOVERY_MODES = map[string]string{
+	"EXPRESSION":          "0",
+	"RECOVERY_EXPRESSION": "1",
+	"NONE":                "2",
+}
+
+var CONVERT_TRIGGER_TYPES = map[string]string{
+	"SINGLE":   "0",
+	"MULTIPLE": "1",
+}
+
+var CONVERT_CORRELATION_MODES = map[string]string{
+	"DISABLED":  "0",
+	"TAG_VALUE": "1",
+}
+
+var CONVERT_EVALTYPES = map[string]string{
+	"AND_OR":  "0",
+	"AND":     "1",
+	"OR":      "2",
+	"FORMULA": "3",
+}
+
+var CONVERT_OPERATORS = map[string]string{
+	"MATCHES_REGEX":     "8",
+	"NOT_MATCHES_REGEX": "9",
+	"EXISTS":            "12",
+	"NOT_EXISTS":        "13",
+}
+
+var CONVERT_GRAPH_TYPES = map[string]string{
+	"NORMAL":   "0",
+	"STACKED":  "1",
+	"PIE":      "2",
+	"EXPLODED": "3",
+}
+
+var CONVERT_GRAPH_AXIS = map[string]string{
+	"CALCULATED": "0",
+	"FIXED":      "1",
+	"ITEM":       "2",
+}
+
+var CONVERT_GRAPH_FUNCS = map[string]string{
+	"MIN":  "1",
+	"AVG":  "2",
+	"MAX":  "4",
+	"ALL":  "7",
+	"LAST": "9",
+}
+
+var CONVERT_GRAPH_DRAW = map[string]string{
+	"SINGLE_LINE":   "0",
+	"FILLED_REGION": "1",
+	"BOLD_LINE":     "2",
+	"DOTTED_LINE":   "3",
+	"DASHED_LINE":   "4",
+	"GRADIENT_LINE": "5",
+}
+
+var CONVERT_GRAPH_ITYPES = map[string]string{
+	"SIMPLE":    "0",
+	"GRAPH_SUM": "2",
+}
+
+var CONVERT_GRAPH_SIDES = map[string]string{
+	"LEFT":  "0",
+	"RIGHT": "1",
+}
+
+var CONVERT_HTTP_METHODS = map[string]string{
+	"GET":  "0",
+	"POST": "1",
+	"PUT":  "2",
+	"HEAD": "3",
+}
+
+var CONVERT_HTTP_POSTTYPES = map[string]string{
+	"RAW":  "0",
+	"JSON": "2",
+	"XML":  "3",
+}
+
+var CONVERT_HTTP_RETRIEVEMODES = map[string]string{
+	"BODY":    "0",
+	"HEADERS": "1",
+	"BOTH":    "2",
+}
+
+var CONVERT_HTTP_AUTHTYPES = map[string]string{
+	"NONE":     "0",
+	"BASIC":    "1",
+	"NTLM":     "2",
+	"KERBEROS": "3",
+}
+
+var CONVERT_SSH_AUTHTYPES = map[string]string{
+	"PASSWORD":   "0",
+	"PUBLIC_KEY": "1",
+}
+
+var CONVERT_SNMP_SECLEVELS = map[string]string{
+	"NOAUTHNOPRIV": "0",
+	"AUTHNOPRIV":   "1",
+	"AUTHPRIV":     "2",
+}
+
+var CONVERT_SNMP_AUTHPROTOCOLS = map[string]string{
+	"MD5":    "0",
+	"SHA":    "1",
+	"SHA1":   "1",
+	"SHA224": "2",
+	"SHA256": "3",
+	"SHA384": "4",
+	"SHA512": "5",
+}
+
+var CONVERT_SNMP_PRIVPROTOCOLS = map[string]string{
+	"DES":     "0",
+	"AES":     "1",
+	"AES128":  "1",
+	"AES192":  "2",
+	"AES256":  "3",
+	"AES192C": "4",
+	"AES256C": "5",
+}
+
+var CONVERT_PREPROCESSOR_TYPES = map[string]string{
+	"CHECK_NOT_SUPPORTED": PREPROCESSOR_TYPES["check_unsupported"],
+}
+
+var CONVERT_PREPROCESSOR_ERROR_HANDLERS = map[string]string{
+	"ORIGINAL_ERROR": "0",
+	"DISCARD_VALUE":  "1",
+	"CUSTOM_VALUE":   "2",
+	"CUSTOM_ERROR":   "3",
+}
+
+var CONVERT_LIFETIME_TYPES = map[string]string{
+	"DELETE_AFTER":       "0",
+	"DELETE_NEVER":       "1",
+	"DELETE_IMMEDIATELY": "2",
+}
+
+var CONVERT_ENABLED_LIFETIME_TYPES = map[string]string{
+	"DISABLE_AFTER":       "0",
+	"DISABLE_NEVER":       "1",
+	"DISABLE_IMMEDIATELY": "2",
+}
+
+// generate the above structures
+var _ = func() bool {
+	// remaining step type constants match the provider names
+	for k, v := range PREPROCESSOR_TYPES {
+		CONVERT_PREPROCESSOR_TYPES[strings.ToUpper(k)] = v
+	}
+	return false
+}()
+
+// export fields copied as is, when the target resource supports them
+var convertItemFields = map[string]string{
+	"delay":                "delay",
+	"history":              "history",
+	"trends":               "trends",
+	"units":                "units",
+	"description":          "description",
+	"logtimefmt":           "logtimefmt",
+	"lifetime":             "lifetime",
+	"enabled_lifetime":     "enabled_lifetime",
+	"timeout":              "timeout",
+	"snmp_oid":             "snmp_oid",
+	"snmp_community":       "snmp_community",
+	"snmp3_securityname":   "snmpv3_securityname",
+	"snmp3_contextname":    "snmpv3_contextname",
+	"snmp3_authpassphrase": "snmpv3_authpassphrase",
+	"snmp3_privpassphrase": "snmpv3_privpassphrase",
+	"url":                  "url",
+	"posts":                "posts",
+	"status_codes":         "status_codes",
+	"proxy":                "http_proxy",
+	"username":             "username",
+	"password":             "password",
+	"publickey":            "publickey",
+	"privatekey":           "privatekey",
+	"ipmi_sensor":          "ipmi_sensor",
+	"jmx_endpoint":         "jmx_endpoint",
+}
+
+// attributes populated from the export params field, per resource
+var convertItemParams = []string{"formula", "script", "sql"}
+
+// ConvertOptions convert subcommand parameters
+type ConvertOptions struct {
+	Format      string
+	Prefix      string
+	SnmpVersion string
+}
+
+// converter state of a single export file conversion
+type converter struct {
+	opts      ConvertOptions
+	resources map[string]*schema.Resource
+	sources   map[string]*schema.Resource
+	namer     *hclNamer
+
+	data    []*hclResource
+	out     []*hclResource
+	skipped []string
+	dropped map[*hclResource]bool
+
+	// cross reference lookups, resolved once everything is known
+	templates    map[string]*hclResource
+	groups       map[string]*hclResource
+	linked       map[string]*hclResource
+	applications map[string]map[string]*hclResource
+	items        map[string]map[string]*hclResource
+	triggers     map[string]*hclResource
+	fixups       []func()
+}
+
+// ConvertTemplate render the templates of a zabbix export (xml, json or yaml) as HCL
+func ConvertTemplate(in []byte, opts ConvertOptions, w io.Writer) error {
+	if opts.SnmpVersion == "" {
+		opts.SnmpVersion = "2"
+	}
+	if _, ok := SNMP_LOOKUP[opts.SnmpVersion]; !ok {
+		return fmt.Errorf("invalid snmp version %q, expected one of: %s", opts.SnmpVersion, strings.Join(SNMP_LOOKUP_ARR, ", "))
+	}
+
+	export, err := convertParse(in, opts.Format)
+	if err != nil {
+		return err
+	}
+
+	p := Provider()
+	c := &converter{
+		opts:         opts,
+		resources:    p.ResourcesMap,
+		sources:      p.DataSourcesMap,
+		namer:        newHclNamer(opts.Prefix),
+		dropped:      map[*hclResource]bool{},
+		templates:    map[string]*hclResource{},
+		groups:       map[string]*hclResource{},
+		linked:       map[string]*hclResource{},
+		applications: map[string]map[string]*hclResource{},
+		items:        map[string]map[string]*hclResource{},
+		triggers:     map[string]*hclResource{},
+	}
+
+	templates := convertList(export, "templates")
+	if len(templates) < 1 {
+		return fmt.Errorf("no templates found in export")
+	}
+	for _, t := range templates {
+		c.template(t)
+	}
+
+	// pre 5.4 exports, and triggers spanning multiple items, live at the top level
+	for _, t := range convertList(export, "triggers") {
+		c.trigger("zabbix_trigger", t)
+	}
+	for _, g := range convertList(export, "graphs") {
+		c.graph("zabbix_graph", g)
+	}
+
+	for _, f := range c.fixups {
+		f()
+	}
+
+	for _, s := range c.skipped {
+		if _, err := fmt.Fprintf(w, "# skipped %s\n", s); err != nil {
+			return err
+		}
+	}
+	if len(c.skipped) > 0 {
+		if _, err := io.WriteString(w, "\n"); err != nil {
+			return err
+		}
+	}
+
+	resources := c.data
+	for _, r := range c.out {
+		if !c.dropped[r] {
+			resources = append(resources, r)
+		}
+	}
+	return hclRender(w, resources)
+}
+
+// add a new resource
+func (c *converter) add(resourceType, name string) *hclResource {
+	r := &hclResource{
+		Type:   resourceType,
+		Name:   c.namer.Name(resourceType, name),
+		Schema: c.resources[resourceType].Schema,
+		Attrs:  map[string]interface{}{},
+	}
+	c.out = append(c.out, r)
+	return r
+}
+
+// addData add a new data source
+func (c *converter) addData(sourceType, name string) *hclResource {
+	r := &hclResource{
+		Type:   sourceType,
+		Name:   c.namer.Name("data."+sourceType, name),
+		Data:   true,
+		Schema: c.sources[sourceType].Schema,
+		Attrs:  map[string]interface{}{},
+	}
+	c.data = append(c.data, r)
+	return r
+}
+
+// skip record an object that could not be converted
+func (c *converter) skip(format string, args ...interface{}) {
+	c.skipped = append(c.skipped, fmt.Sprintf(format, args...))
+}
+
+// set a string attribute, if supported by the resource and not empty
+func (c *converter) set(r *hclResource, attr, value string) {
+	if _, ok := r.Schema[attr]; ok && value != "" {
+		r.Attrs[attr] = value
+	}
+}
+
+// setBool set a boolean attribute, if supported by the resource
+func (c *converter) setBool(r *hclResource, attr string, value bool) {
+	if _, ok := r.Schema[attr]; ok {
+		r.Attrs[attr] = value
+	}
+}
+
+// template convert a template and everything it contains
+func (c *converter) template(t map[string]interface{}) {
+	host := convertStr(t, "template")
+	r := c.add("zabbix_template", host)
+	c.templates[host] = r
+
+	r.Attrs["host"] = host
+	if name := convertStr(t, "name"); name != host {
+		c.set(r, "name", name)
+	}
+	c.set(r, "description", convertStr(t, "description"))
+
+	groups := []interface{}{}
+	for _, g := range convertList(t, "groups") {
+		groups = append(groups, c.group(convertStr(g, "name")).Ref("id"))
+	}
+	r.Attrs["groups"] = groups
+
+	macros := []interface{}{}
+	for _, m := range convertList(t, "macros") {
+		if convertStr(m, "value") == "" {
+			c.skip("macro %s on %s, no value", convertStr(m, "macro"), host)
+			continue
+		}
+		macros = append(macros, map[string]interface{}{
+			"name":  convertStr(m, "macro"),
+			"value": convertStr(m, "value"),
+		})
+	}
+	r.Attrs["macro"] = macros
+
+	linked := convertList(t, "templates")
+	c.fixups = append(c.fixups, func() {
+		ids := []interface{}{}
+		for _, l := range linked {
+			ids = append(ids, c.templateRef(convertStr(l, "name")))
+		}
+		r.Attrs["templates"] = ids
+	})
+
+	c.applications[host] = map[string]*hclResource{}
+	for _, a := range convertList(t, "applications") {
+		name := convertStr(a, "name")
+		app := c.add("zabbix_application", name)
+		app.Attrs["hostid"] = r.Ref("id")
+		app.Attrs["name"] = name
+		c.applications[host][name] = app
+	}
+
+	c.items[host] = map[string]*hclResource{}
+	for _, i := range convertList(t, "items") {
+		c.item(host, r, nil, "zabbix_item_", i)
+		for _, trigger := range convertList(i, "triggers") {
+			c.trigger("zabbix_trigger", trigger)
+		}
+	}
+
+	for _, l := range convertList(t, "discovery_rules") {
+		rule := c.item(host, r, nil, "zabbix_lld_", l)
+		if rule == nil {
+			continue
+		}
+		for _, i := range convertList(l, "item_prototypes") {
+			c.item(host, r, rule, "zabbix_proto_item_", i)
+			for _, trigger := range convertList(i, "trigger_prototypes") {
+				c.trigger("zabbix_proto_trigger", trigger)
+			}
+		}
+		for _, trigger := range convertList(l, "trigger_prototypes") {
+			c.trigger("zabbix_proto_trigger", trigger)
+		}
+		for _, g := range convertList(l, "graph_prototypes") {
+			c.graph("zabbix_proto_graph", g)
+		}
+	}
+}
+
+// group data source for a host group, by name
+func (c *converter) group(name string) *hclResource {
+	if g, ok := c.groups[name]; ok {
+		return g
+	}
+	g := c.addData("zabbix_hostgroup", name)
+	g.Attrs["name"] = name
+	c.groups[name] = g
+	return g
+}
+
+// templateRef reference a template, a data source lookup when not part of this export
+func (c *converter) templateRef(host string) hclExpr {
+	if t, ok := c.templates[host]; ok {
+		return t.Ref("id")
+	}
+	if t, ok := c.linked[host]; ok {
+		return t.Ref("id")
+	}
+	t := c.addData("zabbix_template", host)
+	t.Attrs["host"] = host
+	c.linked[host] = t
+	return t.Ref("id")
+}
+
+// item convert an item, lld rule or item prototype, resource picked by item type
+func (c *converter) item(host string, template, rule *hclResource, prefix string, i map[string]interface{}) *hclResource {
+	key := convertStr(i, "key")
+	itemType := convertEnum(convertStr(i, "type"), CONVERT_ITEM_TYPES, "0")
+
+	typeId, _ := strconv.Atoi(itemType)
+	suffix, ok := EXPORT_ITEM_TYPES[zabbix.ItemType(typeId)]
+	if !ok || c.resources[prefix+suffix] == nil {
+		c.skip("%s* %q on %s, unsupported item type %s", prefix, key, host, convertStr(i, "type"))
+		return nil
+	}
+
+	name := key
+	if prefix == "zabbix_lld_" {
+		name = convertStr(i, "name")
+	}
+	r := c.add(prefix+suffix, name)
+	if prefix != "zabbix_lld_" {
+		c.items[host][key] = r
+	}
+
+	r.Attrs["hostid"] = template.Ref("id")
+	if rule != nil {
+		r.Attrs["ruleid"] = rule.Ref("id")
+	}
+	r.Attrs["name"] = convertStr(i, "name")
+	r.Attrs["key"] = key
+
+	if _, ok := r.Schema["valuetype"]; ok {
+		valueType, _ := strconv.Atoi(convertEnum(convertStr(i, "value_type"), CONVERT_VALUE_TYPES, "3"))
+		r.Attrs["valuetype"] = ITEM_VALUE_TYPES_REV[zabbix.ValueType(valueType)]
+	}
+
+	for attr, field := range convertItemFields {
+		c.set(r, attr, convertStr(i, field))
+	}
+	for _, attr := range convertItemParams {
+		c.set(r, attr, convertStr(i, "params"))
+	}
+	c.snmp(r, i, zabbix.ItemType(typeId))
+
+	c.setBool(r, "enabled", convertEnum(convertStr(i, "status"), CONVERT_STATUS, "0") == "0")
+	c.setBool(r, "discover", convertEnum(convertStr(i, "discover"), CONVERT_DISCOVER, "0") == "0")
+	c.setBool(r, "allow_traps", convertEnum(convertStr(i, "allow_traps"), CONVERT_YES_NO, "0") == "1")
+	c.setBool(r, "verify_host", convertEnum(convertStr(i, "verify_host"), CONVERT_YES_NO, "0") == "1")
+	c.setBool(r, "verify_peer", convertEnum(convertStr(i, "verify_peer"), CONVERT_YES_NO, "0") == "1")
+	c.setBool(r, "follow_redirects", convertEnum(convertStr(i, "follow_redirects"), CONVERT_YES_NO, "1") == "1")
+	c.setBool(r, "active", zabbix.ItemType(typeId) == zabbix.ZabbixAgentActive)
+
+	c.set(r, "request_method", HTTP_METHODS_REV[convertEnum(convertStr(i, "request_method"), CONVERT_HTTP_METHODS, "")])
+	c.set(r, "post_type", HTTP_POSTTYPE_REV[convertEnum(convertStr(i, "post_type"), CONVERT_HTTP_POSTTYPES, "")])
+	c.set(r, "retrieve_mode", HTTP_RETRIEVEMODE_REV[convertEnum(convertStr(i, "retrieve_mode"), CONVERT_HTTP_RETRIEVEMODES, "")])
+	c.set(r, "lifetime_type", LLD_LIFETIME_TYPE_REV[convertEnum(convertStr(i, "lifetime_type"), CONVERT_LIFETIME_TYPES, "")])
+	c.set(r, "enabled_lifetime_type", LLD_LIFETIME_TYPE_REV[convertEnum(convertStr(i, "enabled_lifetime_type"), CONVERT_ENABLED_LIFETIME_TYPES, "")])
+	if zabbix.ItemType(typeId) == zabbix.SSHAgent {
+		c.set(r, "auth_type", SSH_AUTHTYPE_REV[convertEnum(convertStr(i, "authtype"), CONVERT_SSH_AUTHTYPES, "")])
+	} else {
+		c.set(r, "auth_type", HTTP_AUTHTYPE_REV[convertEnum(convertStr(i, "authtype"), CONVERT_HTTP_AUTHTYPES, "")])
+	}
+
+	if _, ok := r.Schema["headers"]; ok {
+		headers := map[string]interface{}{}
+		for _, h := range convertList(i, "headers") {
+			headers[convertStr(h, "name")] = convertStr(h, "value")
+		}
+		r.Attrs["headers"] = headers
+	}
+	if _, ok := r.Schema["parameter"]; ok {
+		params := []interface{}{}
+		for _, p := range convertList(i, "parameters") {
+			params = append(params, map[string]interface{}{
+				"name":  convertStr(p, "name"),
+				"value": convertStr(p, "value"),
+			})
+		}
+		r.Attrs["parameter"] = params
+	}
+
+	if rule == nil {
+		link := convertStr(i, "inventory_link")
+		if _, err := strconv.Atoi(link); err == nil {
+			c.set(r, "inventory_link", ITEM_INVENTORY_LINK_REV[link])
+		} else if _, ok := ITEM_INVENTORY_LINK[strings.ToLower(link)]; ok {
+			c.set(r, "inventory_link", strings.ToLower(link))
+		}
+	}
+
+	r.Attrs["preprocessor"] = convertPreprocessing(i)
+	r.Attrs["tag"] = convertTags(i)
+
+	if _, ok := r.Schema["evaltype"]; ok {
+		c.filter(r, convertMap(i, "filter"))
+	}
+	if _, ok := r.Schema["macro"]; ok {
+		paths := []interface{}{}
+		for _, p := range convertList(i, "lld_macro_paths") {
+			paths = append(paths, map[string]interface{}{
+				"macro": convertStr(p, "lld_macro"),
+				"path":  convertStr(p, "path"),
+			})
+		}
+		r.Attrs["macro"] = paths
+	}
+
+	applications := convertList(i, "applications")
+	master := convertStr(convertMap(i, "master_item"), "key")
+	c.fixups = append(c.fixups, func() {
+		if _, ok := r.Schema["applications"]; ok {
+			ids := []interface{}{}
+			for _, a := range applications {
+				name := convertStr(a, "name")
+				if app, ok := c.applications[host][name]; ok {
+					ids = append(ids, app.Ref("id"))
+				} else {
+					c.skip("application %q of %q on %s, not found", name, key, host)
+				}
+			}
+			r.Attrs["applications"] = ids
+		}
+
+		if _, ok := r.Schema["master_itemid"]; ok {
+			m, ok := c.items[host][master]
+			if !ok {
+				c.skip("%s %q on %s, master item %q not found", r.Type, key, host, master)
+				c.dropped[r] = true
+				return
+			}
+			r.Attrs["master_itemid"] = m.Ref("id")
+		}
+	})
+
+	return r
+}
+
+// snmp convert snmp version and credentials, pre 5.0 exports carry them per item
+func (c *converter) snmp(r *hclResource, i map[string]interface{}, itemType zabbix.ItemType) {
+	if _, ok := r.Schema["snmp_version"]; !ok {
+		return
+	}
+
+	version, ok := SNMP_LOOKUP_REV[itemType]
+	if !ok {
+		version = c.opts.SnmpVersion
+	}
+	r.Attrs["snmp_version"] = version
+
+	// only relevant to the matching version, 4.x exports populate all
+	if version == "3" {
+		delete(r.Attrs, "snmp_community")
+		c.set(r, "snmp3_securitylevel", SNMP_SECLEVEL_REV[convertEnum(convertStr(i, "snmpv3_securitylevel"), CONVERT_SNMP_SECLEVELS, "")])
+		c.set(r, "snmp3_authprotocol", SNMP_AUTHPROTO_REV[convertEnum(convertStr(i, "snmpv3_authprotocol"), CONVERT_SNMP_AUTHPROTOCOLS, "")])
+		c.set(r, "snmp3_privprotocol", SNMP_PRIVPROTO_REV[convertEnum(convertStr(i, "snmpv3_privprotocol"), CONVERT_SNMP_PRIVPROTOCOLS, "")])
+		return
+	}
+	for attr := range r.Attrs {
+		if strings.HasPrefix(attr, "snmp3_") {
+			delete(r.Attrs, attr)
+		}
+	}
+}
+
+// filter convert an lld rule filter
+func (c *converter) filter(r *hclResource, f map[string]interface{}) {
+	c.set(r, "evaltype", LLD_EVALTYPE_REV[zabbix.LLDEvalType(convertEnum(convertStr(f, "evaltype"), CONVERT_EVALTYPES, "0"))])
+	c.set(r, "formula", convertStr(f, "formula"))
+
+	conditions := []interface{}{}
+	for _, cond := range convertList(f, "conditions") {
+		op := convertEnum(convertStr(cond, "operator"), CONVERT_OPERATORS, "8")
+		operator, ok := LLD_OPERATOR_REV[zabbix.LLDOperatorType(op)]
+		if !ok {
+			c.skip("filter condition on %s of %q, unsupported operator %s", convertStr(cond, "macro"), r.Attrs["key"], convertStr(cond, "operator"))
+			continue
+		}
+		condition := map[string]interface{}{
+			"macro":    convertStr(cond, "macro"),
+			"value":    convertStr(cond, "value"),
+			"operator": operator,
+		}
+		// only meaningful to custom formulas, otherwise assigned by zabbix
+		if r.Attrs["evaltype"] == "custom" {
+			condition["formulaid"] = convertStr(cond, "formulaid")
+		}
+		conditions = append(conditions, condition)
+	}
+	r.Attrs["condition"] = conditions
+}
+
+// convertPreprocessing convert preprocessing steps to preprocessor blocks
+func convertPreprocessing(i map[string]interface{}) []interface{} {
+	steps := []interface{}{}
+	for _, s := range convertList(i, "preprocessing") {
+		// 5.4+ exports params as a list, earlier as newline separated
+		params := []interface{}{}
+		if _, ok := s["parameters"]; ok {
+			for _, p := range convertStrList(s, "parameters") {
+				params = append(params, p)
+			}
+		} else if v := convertStr(s, "params"); v != "" {
+			for _, p := range strings.Split(v, "\n") {
+				params = append(params, p)
+			}
+		}
+
+		handler := convertEnum(convertStr(s, "error_handler"), CONVERT_PREPROCESSOR_ERROR_HANDLERS, "0")
+		if handler == "0" {
+			handler = ""
+		} else {
+			handler = preprocessorErrorHandlerName(handler)
+		}
+
+		steps = append(steps, map[string]interface{}{
+			"type":                 preprocessorTypeName(convertEnum(convertStr(s, "type"), CONVERT_PREPROCESSOR_TYPES, "")),
+			"params":               params,
+			"error_handler":        handler,
+			"error_handler_params": convertStr(s, "error_handler_params"),
+		})
+	}
+	return steps
+}
+
+// convertTags convert tags to tag blocks
+func convertTags(i map[string]interface{}) []interface{} {
+	tags := []interface{}{}
+	for _, t := range convertList(i, "tags") {
+		tags = append(tags, map[string]interface{}{
+			"key":   convertStr(t, "tag"),
+			"value": convertStr(t, "value"),
+		})
+	}
+	return tags
+}
+
+// trigger convert a trigger or trigger prototype
+func (c *converter) trigger(resourceType string, t map[string]interface{}) {
+	name := convertStr(t, "name")
+	expression := convertStr(t, "expression")
+	recovery := convertStr(t, "recovery_expression")
+
+	r := c.add(resourceType, name)
+	c.triggers[name+"\n"+expression] = r
+
+	priority, _ := strconv.Atoi(convertEnum(convertStr(t, "priority"), CONVERT_PRIORITIES, "0"))
+
+	r.Attrs["name"] = name
+	r.Attrs["expression"] = expression
+	r.Attrs["priority"] = TRIGGER_PRIORITY_REV[zabbix.SeverityType(priority)]
+	c.set(r, "comments", convertStr(t, "description"))
+	c.set(r, "event_name", convertStr(t, "event_name"))
+	c.set(r, "opdata", convertStr(t, "opdata"))
+	c.set(r, "url", convertStr(t, "url"))
+	c.set(r, "url_name", convertStr(t, "url_name"))
+	c.setBool(r, "enabled", convertEnum(convertStr(t, "status"), CONVERT_STATUS, "0") == "0")
+	c.setBool(r, "multiple", convertEnum(convertStr(t, "type"), CONVERT_TRIGGER_TYPES, "0") == "1")
+	c.setBool(r, "manual_close", convertEnum(convertStr(t, "manual_close"), CONVERT_YES_NO, "0") == "1")
+	c.setBool(r, "discover", convertEnum(convertStr(t, "discover"), CONVERT_DISCOVER, "0") == "0")
+
+	mode := convertEnum(convertStr(t, "recovery_mode"), CONVERT_RECOVERY_MODES, "0")
+	c.setBool(r, "recovery_none", mode == "2")
+	if convertEnum(convertStr(t, "correlation_mode"), CONVERT_CORRELATION_MODES, "0") == "1" {
+		c.set(r, "correlation_tag", convertStr(t, "correlation_tag"))
+	}
+	r.Attrs["tag"] = convertTags(t)
+
+	dependencies := convertList(t, "dependencies")
+	c.fixups = append(c.fixups, func() {
+		r.Attrs["expression"] = c.expression(expression)
+		if mode == "1" {
+			r.Attrs["recovery_expression"] = c.expression(recovery)
+		}
+
+		ids := []interface{}{}
+		for _, d := range dependencies {
+			dep, ok := c.triggers[convertStr(d, "name")+"\n"+convertStr(d, "expression")]
+			if !ok {
+				c.skip("dependency of %q on %q, trigger not found", name, convertStr(d, "name"))
+				continue
+			}
+			ids = append(ids, dep.Ref("id"))
+		}
+		r.Attrs["dependencies"] = ids
+	})
+}
+
+// graph convert a graph or graph prototype
+func (c *converter) graph(resourceType string, g map[string]interface{}) {
+	name := convertStr(g, "name")
+	r := c.add(resourceType, name)
+
+	r.Attrs["name"] = name
+	r.Attrs["height"] = convertDefault(convertStr(g, "height"), "200")
+	r.Attrs["width"] = convertDefault(convertStr(g, "width"), "900")
+	c.set(r, "type", GRAPH_TYPE_LOOKUP_REV[zabbix.GraphType(convertEnum(convertStr(g, "type"), CONVERT_GRAPH_TYPES, "0"))])
+	c.set(r, "percent_left", convertNumber(convertStr(g, "percent_left")))
+	c.set(r, "percent_right", convertNumber(convertStr(g, "percent_right")))
+	c.set(r, "ymin", convertNumber(convertStr(g, "yaxismin")))
+	c.set(r, "ymax", convertNumber(convertStr(g, "yaxismax")))
+	c.set(r, "ymin_type", GRAPH_AXIS_LOOKUP_REV[zabbix.GraphAxis(convertEnum(convertStr(g, "ymin_type_1"), CONVERT_GRAPH_AXIS, "0"))])
+	c.set(r, "ymax_type", GRAPH_AXIS_LOOKUP_REV[zabbix.GraphAxis(convertEnum(convertStr(g, "ymax_type_1"), CONVERT_GRAPH_AXIS, "0"))])
+	c.setBool(r, "do3d", convertEnum(convertStr(g, "show_3d"), CONVERT_YES_NO, "0") == "1")
+	c.setBool(r, "legend", convertEnum(convertStr(g, "show_legend"), CONVERT_YES_NO, "1") == "1")
+	c.setBool(r, "work_period", convertEnum(convertStr(g, "show_work_period"), CONVERT_YES_NO, "1") == "1")
+
+	items := []interface{}{}
+	refs := []map[string]interface{}{}
+	for _, i := range convertList(g, "graph_items") {
+		items = append(items, map[string]interface{}{
+			"color":      convertStr(i, "color"),
+			"sortorder":  convertDefault(convertStr(i, "sortorder"), "0"),
+			"function":   GRAPH_FUNC_LOOKUP_REV[zabbix.GraphItemFunc(convertEnum(convertStr(i, "calc_fnc"), CONVERT_GRAPH_FUNCS, "2"))],
+			"drawtype":   GRAPH_DRAW_LOOKUP_REV[zabbix.GraphItemDraw(convertEnum(convertStr(i, "drawtype"), CONVERT_GRAPH_DRAW, "0"))],
+			"type":       GRAPH_ITYPE_LOOKUP_REV[zabbix.GraphItemType(convertEnum(convertStr(i, "type"), CONVERT_GRAPH_ITYPES, "0"))],
+			"yaxis_side": GRAPH_SIDE_LOOKUP_REV[zabbix.GraphItemSide(convertEnum(convertStr(i, "yaxisside"), CONVERT_GRAPH_SIDES, "0"))],
+		})
+		refs = append(refs, convertMap(i, "item"))
+	}
+	r.Attrs["item"] = items
+
+	ymin := convertMap(g, "ymin_item_1")
+	ymax := convertMap(g, "ymax_item_1")
+	c.fixups = append(c.fixups, func() {
+		for idx, ref := range refs {
+			item, ok := c.items[convertStr(ref, "host")][convertStr(ref, "key")]
+			if !ok {
+				c.skip("%s %q, item %s:%s not found", resourceType, name, convertStr(ref, "host"), convertStr(ref, "key"))
+				c.dropped[r] = true
+				return
+			}
+			items[idx].(map[string]interface{})["itemid"] = item.Ref("id")
+		}
+		if item, ok := c.items[convertStr(ymin, "host")][convertStr(ymin, "key")]; ok {
+			r.Attrs["ymin_itemid"] = item.Ref("id")
+		}
+		if item, ok := c.items[convertStr(ymax, "host")][convertStr(ymax, "key")]; ok {
+			r.Attrs["ymax_itemid"] = item.Ref("id")
+		}
+	})
+}
+
+// expression rewrite a trigger expression, referencing exported templates and
+// items so terraform orders their creation, both legacy {host:key.func()}
+// and 5.4+ func(/host/key) syntax are understood
+func (c *converter) expression(ex string) interface{} {
+	var t hclTemplate
+	last := 0
+
+	for i := 0; i < len(ex); i++ {
+		switch ex[i] {
+		case '"':
+			// skip string constants
+			for i++; i < len(ex) && ex[i] != '"'; i++ {
+				if ex[i] == '\\' {
+					i++
+				}
+			}
+		case '{':
+			// legacy {host:key.func(...)}
+			end := strings.IndexAny(ex[i+1:], "{}:")
+			if end < 0 || ex[i+1+end] != ':' {
+				continue
+			}
+			host := ex[i+1 : i+1+end]
+			keyStart := i + 2 + end
+			keyEnd := convertKeyEnd(ex, keyStart, true)
+			if c.expressionRef(&t, ex[last:i+1], host, ex[keyStart:keyEnd], ":") {
+				last = keyEnd
+				i = keyEnd - 1
+			}
+		case '/':
+			// 5.4+ func(/host/key,...), '/' is division unless opening an argument
+			j := strings.LastIndexFunc(ex[:i], func(r rune) bool { return r != ' ' })
+			if j < 0 || (ex[j] != '(' && ex[j] != ',') {
+				continue
+			}
+			end := strings.IndexByte(ex[i+1:], '/')
+			if end < 0 {
+				continue
+			}
+			host := ex[i+1 : i+1+end]
+			keyStart := i + 2 + end
+			keyEnd := convertKeyEnd(ex, keyStart, false)
+			if c.expressionRef(&t, ex[last:i+1], host, ex[keyStart:keyEnd], "/") {
+				last = keyEnd
+				i = keyEnd - 1
+			}
+		}
+	}
+	t.Literal(ex[last:])
+
+	return t.Value(ex)
+}
+
+// expressionRef interpolate a template and item reference, if both are known
+func (c *converter) expressionRef(t *hclTemplate, prefix, host, key, sep string) bool {
+	template, ok := c.templates[host]
+	if !ok {
+		return false
+	}
+	item, ok := c.items[host][key]
+	if !ok {
+		return false
+	}
+
+	t.Literal(prefix)
+	t.Interpolate(template.Ref("host"))
+	t.Literal(sep)
+	t.Interpolate(item.Ref("key"))
+	return true
+}
+
+// convertKeyEnd find the end of an item key, brackets may contain any
+// character, legacy keys end at .func( and new style keys at , or )
+func convertKeyEnd(ex string, start int, legacy bool) int {
+	depth := 0
+	for i := start; i < len(ex); i++ {
+		switch ex[i] {
+		case '[':
+			depth++
+		case ']':
+			depth--
+		case '"':
+			if depth > 0 {
+				for i++; i < len(ex) && ex[i] != '"'; i++ {
+					if ex[i] == '\\' {
+						i++
+					}
+				}
+			}
+		case '.':
+			if legacy && depth == 0 && convertIsFunc(ex[i+1:]) {
+				return i
+			}
+		case ',', ')':
+			if !legacy && depth == 0 {
+				return i
+			}
+		}
+	}
+	return len(ex)
+}
+
+// convertIsFunc does the string start with a function call, ie last(
+func convertIsFunc(s string) bool {
+	i := 0
+	for i < len(s) && (s[i] >= 'a' && s[i] <= 'z' || s[i] >= 'A' && s[i] <= 'Z') {
+		i++
+	}
+	return i > 0 && i < len(s) && s[i] == '('
+}
+
+// convertEnum resolve an export constant to its api identifier, using def when missing
+func convertEnum(v string, table map[string]string, def string) string {
+	if v == "" {
+		return def
+	}
+	if _, err := strconv.Atoi(v); err == nil {
+		return v
+	}
+	return table[v]
+}
+
+// convertDefault value, or def when empty
+func convertDefault(v, def string) string {
+	if v == "" {
+		return def
+	}
+	return v
+}
+
+// convertNumber normalise decimals, 4.x exports use 100.0000 style values
+func convertNumber(v string) string {
+	f, err := strconv.ParseFloat(v, 64)
+	if err != nil {
+		return v
+	}
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
+
+// convertStr string value of a field
+func convertStr(m map[string]interface{}, key string) string {
+	v, _ := m[key].(string)
+	return v
+}
+
+// convertMap object value of a field
+func convertMap(m map[string]interface{}, key string) map[string]interface{} {
+	v, _ := m[key].(map[string]interface{})
+	return v
+}
+
+// convertList list of objects value of a field
+func convertList(m map[string]interface{}, key string) []map[string]interface{} {
+	res := []map[string]interface{}{}
+	list, _ := m[key].([]interface{})
+	for _, e := range list {
+		if obj, ok := e.(map[string]interface{}); ok {
+			res = append(res, obj)
+		}
+	}
+	return res
+}
+
+// convertStrList list of strings value of a field
+func convertStrList(m map[string]interface{}, key string) []string {
+	res := []string{}
+	list, _ := m[key].([]interface{})
+	for _, e := range list {
+		if s, ok := e.(string); ok {
+			res = append(res, s)
+		}
+	}
+	return res
+}
@@ -0,0 +1,173 @@
+package provider
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+)
+
+// supported export formats
+var CONVERT_FORMATS = []string{"xml", "json", "yaml"}
+
+// convertParse decode a zabbix export into a generic tree of
+// map[string]interface{}, []interface{} and string values
+func convertParse(in []byte, format string) (map[string]interface{}, error) {
+	var tree interface{}
+
+	switch format {
+	case "xml":
+		root, err := convertParseXml(in)
+		if err != nil {
+			return nil, err
+		}
+		tree = root
+	case "json":
+		d := json.NewDecoder(bytes.NewReader(in))
+		d.UseNumber()
+		if err := d.Decode(&tree); err != nil {
+			return nil, err
+		}
+	case "yaml":
+		if err := yaml.Unmarshal(in, &tree); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("unsupported format %q, expected one of: %s", format, strings.Join(CONVERT_FORMATS, ", "))
+	}
+
+	root, ok := convertNormalise(tree).(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("unexpected %s document, not an object", format)
+	}
+	export, ok := root["zabbix_export"].(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("no zabbix_export found in %s document", format)
+	}
+	return export, nil
+}
+
+// convertNormalise reduce decoded json / yaml to string keyed maps and string scalars
+func convertNormalise(v interface{}) interface{} {
+	switch val := v.(type) {
+	case map[string]interface{}:
+		res := map[string]interface{}{}
+		for k, e := range val {
+			res[k] = convertNormalise(e)
+		}
+		return res
+	case map[interface{}]interface{}:
+		res := map[string]interface{}{}
+		for k, e := range val {
+			res[fmt.Sprintf("%v", k)] = convertNormalise(e)
+		}
+		return res
+	case []interface{}:
+		res := make([]interface{}, len(val))
+		for i, e := range val {
+			res[i] = convertNormalise(e)
+		}
+		return res
+	case nil:
+		return ""
+	case string:
+		return val
+	}
+	return fmt.Sprintf("%v", v)
+}
+
+// xml element, prior to list detection
+type convertXmlNode struct {
+	name     string
+	text     strings.Builder
+	children []*convertXmlNode
+}
+
+// convertParseXml decode an xml export, wrapping it like the json and yaml formats
+func convertParseXml(in []byte) (map[string]interface{}, error) {
+	d := xml.NewDecoder(bytes.NewReader(in))
+
+	var stack []*convertXmlNode
+	var root *convertXmlNode
+
+	for {
+		tok, err := d.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		switch t := tok.(type) {
+		case xml.StartElement:
+			node := &convertXmlNode{name: t.Name.Local}
+			if len(stack) > 0 {
+				parent := stack[len(stack)-1]
+				parent.children = append(parent.children, node)
+			} else if root == nil {
+				root = node
+			}
+			stack = append(stack, node)
+		case xml.EndElement:
+			stack = stack[:len(stack)-1]
+		case xml.CharData:
+			if len(stack) > 0 {
+				stack[len(stack)-1].text.Write(t)
+			}
+		}
+	}
+
+	if root == nil {
+		return nil, fmt.Errorf("empty xml document")
+	}
+
+	return map[string]interface{}{
+		root.name: convertXmlValue(root),
+	}, nil
+}
+
+// convertXmlValue convert an element, wrapper elements (<items><item/></items>) become lists
+func convertXmlValue(n *convertXmlNode) interface{} {
+	if len(n.children) == 0 {
+		return n.text.String()
+	}
+
+	if convertXmlIsList(n) {
+		res := make([]interface{}, len(n.children))
+		for i, c := range n.children {
+			res[i] = convertXmlValue(c)
+		}
+		return res
+	}
+
+	res := map[string]interface{}{}
+	for _, c := range n.children {
+		res[c.name] = convertXmlValue(c)
+	}
+	return res
+}
+
+// convertXmlIsList is this element a list wrapper, ie items/item, dependencies/dependency
+func convertXmlIsList(n *convertXmlNode) bool {
+	child := n.children[0].name
+	for _, c := range n.children {
+		if c.name != child {
+			return false
+		}
+	}
+
+	switch {
+	case n.name == "preprocessing" && child == "step":
+		return true
+	case n.name == child+"s":
+		return true
+	case strings.HasSuffix(child, "y") && n.name == strings.TrimSuffix(child, "y")+"ies":
+		return true
+	}
+	return false
+}
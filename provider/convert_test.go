@@ -0,0 +1,77 @@
+package provider
+
+import (
+	"bytes"
+	"flag"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+)
+
+var updateGolden = flag.Bool("update", false, "rewrite testdata golden files")
+
+func TestConvertTemplate(t *testing.T) {
+	for _, file := range []string{"template.xml", "template.json", "template.yaml"} {
+		t.Run(file, func(t *testing.T) {
+			path := filepath.Join("testdata", "convert", file)
+			in, err := ioutil.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var out bytes.Buffer
+			opts := ConvertOptions{Format: filepath.Ext(file)[1:]}
+			if err := ConvertTemplate(in, opts, &out); err != nil {
+				t.Fatal(err)
+			}
+
+			golden := path + ".golden"
+			if *updateGolden {
+				if err := ioutil.WriteFile(golden, out.Bytes(), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			expected, err := ioutil.ReadFile(golden)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if out.String() != string(expected) {
+				t.Errorf("output differs from %s, got:\n%s", golden, out.String())
+			}
+		})
+	}
+}
+
+func TestConvertExpression(t *testing.T) {
+	c := &converter{
+		templates: map[string]*hclResource{
+			"Tmpl": {Type: "zabbix_template", Name: "tmpl"},
+		},
+		items: map[string]map[string]*hclResource{
+			"Tmpl": {
+				"key[a,b]": {Type: "zabbix_item_agent", Name: "key"},
+			},
+		},
+	}
+
+	cases := []struct {
+		in       string
+		expected interface{}
+	}{
+		{"{Tmpl:key[a,b].last()}=0", hclExpr(`"{${zabbix_template.tmpl.host}:${zabbix_item_agent.key.key}.last()}=0"`)},
+		{"last(/Tmpl/key[a,b])=0", hclExpr(`"last(/${zabbix_template.tmpl.host}/${zabbix_item_agent.key.key})=0"`)},
+		{"last(/Other/key[a,b])=0", "last(/Other/key[a,b])=0"},
+		{"{$MACRO}>1", "{$MACRO}>1"},
+	}
+	for _, tc := range cases {
+		if got := c.expression(tc.in); got != tc.expected {
+			t.Errorf("expression(%q) = %#v, expected %#v", tc.in, got, tc.expected)
+		}
+	}
+}
+
+func TestConvertUnsupportedFormat(t *testing.T) {
+	if err := ConvertTemplate([]byte("{}"), ConvertOptions{Format: "toml"}, &bytes.Buffer{}); err == nil {
+		t.Error("expected error for unsupported format")
+	}
+}
@@ -0,0 +1,328 @@
+package provider
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+	"github.com/tpretz/go-zabbix-api"
+)
+
+// item type to resource name suffix, ie zabbix_item_<suffix>
+var EXPORT_ITEM_TYPES = map[zabbix.ItemType]string{
+	zabbix.ZabbixAgent:       "agent",
+	zabbix.ZabbixAgentActive: "agent",
+	zabbix.SNMPv1Agent:       "snmp",
+	zabbix.SNMPv2Agent:       "snmp",
+	zabbix.SNMPv3Agent:       "snmp",
+	zabbix.SNMPAgent:         "snmp",
+	zabbix.ZabbixTrapper:     "trapper",
+	zabbix.SimpleCheck:       "simple",
+	zabbix.ZabbixInternal:    "internal",
+	zabbix.ZabbixAggregate:   "aggregate",
+	zabbix.ExternalCheck:     "external",
+	zabbix.DatabaseMonitor:   "odbc",
+	zabbix.IPMIAgent:         "ipmi",
+	zabbix.SSHAgent:          "ssh",
+	zabbix.TELNETAgent:       "telnet",
+	zabbix.Calculated:        "calculated",
+	zabbix.JMXAgent:          "jmx",
+	zabbix.SNMPTrap:          "snmptrap",
+	zabbix.Dependent:         "dependent",
+	zabbix.HTTPAgent:         "http",
+	ScriptItem:               "script",
+	BrowserItem:              "browser",
+}
+
+// attributes holding ids of other exported objects, by object kind
+var exportRefFields = map[string]string{
+	"hostid":        "template",
+	"ruleid":        "lld",
+	"master_itemid": "item",
+	"itemid":        "item",
+	"ymax_itemid":   "item",
+	"ymin_itemid":   "item",
+	"dependencies":  "trigger",
+}
+
+// ExportOptions export subcommand parameters
+type ExportOptions struct {
+	Template string
+	Prefix   string
+}
+
+// exporter state of a single template export
+type exporter struct {
+	api       *zabbix.API
+	resources map[string]*schema.Resource
+	namer     *hclNamer
+	out       []*hclResource
+	refs      map[string]map[string]string
+	skipped   []string
+	variables []string
+}
+
+// ExportTemplate render a template, its items, lld rules, prototypes, triggers
+// and graphs as HCL, with import blocks to adopt the existing objects
+func ExportTemplate(api *zabbix.API, opts ExportOptions, w io.Writer) error {
+	templateid, err := importFind(api, "template.get", "templateid", zabbix.Params{
+		"filter": map[string]interface{}{"host": opts.Template},
+	}, opts.Template)
+	if err != nil {
+		return err
+	}
+
+	e := &exporter{
+		api:       api,
+		resources: Provider().ResourcesMap,
+		namer:     newHclNamer(opts.Prefix),
+		refs:      map[string]map[string]string{},
+	}
+
+	if err := e.export(templateid, opts.Template); err != nil {
+		return err
+	}
+	e.resolveRefs()
+
+	for _, s := range e.skipped {
+		if _, err := fmt.Fprintf(w, "# skipped %s\n", s); err != nil {
+			return err
+		}
+	}
+	if len(e.skipped) > 0 {
+		if _, err := io.WriteString(w, "\n"); err != nil {
+			return err
+		}
+	}
+	for _, v := range e.variables {
+		if _, err := io.WriteString(w, hclVariable(v)); err != nil {
+			return err
+		}
+	}
+
+	return hclRender(w, e.out)
+}
+
+func (e *exporter) export(templateid, host string) error {
+	if err := e.add("zabbix_template", "template", templateid, host); err != nil {
+		return err
+	}
+
+	items, err := e.list("item.get", "itemid", zabbix.Params{"templateids": templateid})
+	if err != nil {
+		return err
+	}
+	if err := e.addItems("zabbix_item_", items); err != nil {
+		return err
+	}
+
+	llds, err := e.list("discoveryrule.get", "itemid", zabbix.Params{"templateids": templateid})
+	if err != nil {
+		return err
+	}
+	if err := e.addItems("zabbix_lld_", llds); err != nil {
+		return err
+	}
+
+	for _, lld := range llds {
+		protos, err := e.list("itemprototype.get", "itemid", zabbix.Params{"discoveryids": lld["itemid"]})
+		if err != nil {
+			return err
+		}
+		if err := e.addItems("zabbix_proto_item_", protos); err != nil {
+			return err
+		}
+	}
+
+	for _, lld := range llds {
+		triggers, err := e.list("triggerprototype.get", "triggerid", zabbix.Params{"discoveryids": lld["itemid"]})
+		if err != nil {
+			return err
+		}
+		if err := e.addNamed("zabbix_proto_trigger", "trigger", "triggerid", "description", triggers); err != nil {
+			return err
+		}
+	}
+
+	triggers, err := e.list("trigger.get", "triggerid", zabbix.Params{"templateids": templateid})
+	if err != nil {
+		return err
+	}
+	if err := e.addNamed("zabbix_trigger", "trigger", "triggerid", "description", triggers); err != nil {
+		return err
+	}
+
+	for _, lld := range llds {
+		graphs, err := e.list("graphprototype.get", "graphid", zabbix.Params{"discoveryids": lld["itemid"]})
+		if err != nil {
+			return err
+		}
+		if err := e.addNamed("zabbix_proto_graph", "graph", "graphid", "name", graphs); err != nil {
+			return err
+		}
+	}
+
+	graphs, err := e.list("graph.get", "graphid", zabbix.Params{"templateids": templateid})
+	if err != nil {
+		return err
+	}
+	return e.addNamed("zabbix_graph", "graph", "graphid", "name", graphs)
+}
+
+// list fetch objects directly owned by the template, not inherited ones
+func (e *exporter) list(method, idField string, params zabbix.Params) (res []map[string]interface{}, err error) {
+	params["output"] = "extend"
+	params["inherited"] = false
+	params["sortfield"] = idField
+
+	err = e.api.CallWithErrorParse(method, params, &res)
+	return
+}
+
+// addItems add items, lld rules or prototypes, resource picked by item type
+func (e *exporter) addItems(prefix string, items []map[string]interface{}) error {
+	kind := "item"
+	if prefix == "zabbix_lld_" {
+		kind = "lld"
+	}
+
+	for _, item := range items {
+		id, _ := item["itemid"].(string)
+		key, _ := item["key_"].(string)
+		t, _ := item["type"].(string)
+
+		typeId, _ := strconv.Atoi(t)
+		suffix, ok := EXPORT_ITEM_TYPES[zabbix.ItemType(typeId)]
+		if !ok || e.resources[prefix+suffix] == nil {
+			e.skipped = append(e.skipped, fmt.Sprintf("%s %q (id %s), unsupported item type %s", kind, key, id, t))
+			continue
+		}
+
+		if err := e.add(prefix+suffix, kind, id, key); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// addNamed add triggers or graphs
+func (e *exporter) addNamed(resourceType, kind, idField, nameField string, objects []map[string]interface{}) error {
+	for _, obj := range objects {
+		id, _ := obj[idField].(string)
+		name, _ := obj[nameField].(string)
+
+		if err := e.add(resourceType, kind, id, name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// add read an object through its resource read function, capturing its state
+func (e *exporter) add(resourceType, kind, id, name string) error {
+	r := e.resources[resourceType]
+
+	d := r.Data(nil)
+	d.SetId(id)
+	if err := r.Read(d, e.api); err != nil {
+		return fmt.Errorf("%s %s: %s", resourceType, id, err)
+	}
+	if d.Id() == "" {
+		return fmt.Errorf("%s %s: not found", resourceType, id)
+	}
+
+	attrs := map[string]interface{}{}
+	for k := range r.Schema {
+		attrs[k] = exportValue(d.Get(k))
+	}
+
+	res := &hclResource{
+		Type:     resourceType,
+		Name:     e.namer.Name(resourceType, name),
+		ImportID: id,
+		Schema:   r.Schema,
+		Attrs:    attrs,
+	}
+	e.out = append(e.out, res)
+
+	// never write secrets into the config, reference a variable instead
+	for _, k := range hclSortedKeys(r.Schema) {
+		if !r.Schema[k].Sensitive || hclSkip(r.Schema[k], attrs[k]) {
+			continue
+		}
+		v := strings.ReplaceAll(strings.TrimPrefix(resourceType, "zabbix_")+"_"+res.Name+"_"+k, "-", "_")
+		attrs[k] = hclExpr("var." + v)
+		e.variables = append(e.variables, v)
+		e.skipped = append(e.skipped, fmt.Sprintf("%s %s value (sensitive), set var.%s instead", res.Address(), k, v))
+	}
+
+	if e.refs[kind] == nil {
+		e.refs[kind] = map[string]string{}
+	}
+	e.refs[kind][id] = res.Address()
+
+	return nil
+}
+
+// exportValue convert sets to plain lists, so values can be rewritten in place
+func exportValue(v interface{}) interface{} {
+	switch val := v.(type) {
+	case map[string]interface{}:
+		res := map[string]interface{}{}
+		for k, e := range val {
+			res[k] = exportValue(e)
+		}
+		return res
+	case *schema.Set, []interface{}:
+		list := hclListValue(val)
+		res := make([]interface{}, len(list))
+		for i, e := range list {
+			res[i] = exportValue(e)
+		}
+		return res
+	}
+	return v
+}
+
+// resolveRefs replace ids of exported objects with resource references
+func (e *exporter) resolveRefs() {
+	for _, r := range e.out {
+		e.resolveAttrs(r.Attrs)
+	}
+}
+
+func (e *exporter) resolveAttrs(attrs map[string]interface{}) {
+	for k, v := range attrs {
+		if kind, ok := exportRefFields[k]; ok {
+			attrs[k] = e.resolveValue(kind, v)
+			continue
+		}
+		for _, elem := range hclListValue(v) {
+			if m, ok := elem.(map[string]interface{}); ok {
+				e.resolveAttrs(m)
+			}
+		}
+	}
+}
+
+func (e *exporter) resolveValue(kind string, v interface{}) interface{} {
+	switch val := v.(type) {
+	case string:
+		if addr, ok := e.refs[kind][val]; ok {
+			return hclExpr(addr + ".id")
+		}
+		return val
+	}
+
+	list := hclListValue(v)
+	if list == nil {
+		return v
+	}
+	res := make([]interface{}, len(list))
+	for i, elem := range list {
+		res[i] = e.resolveValue(kind, elem)
+	}
+	return res
+}
@@ -0,0 +1,330 @@
+package provider
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+)
+
+// hclExpr a raw hcl expression, rendered unquoted (ie a resource reference)
+type hclExpr string
+
+// hclResource a single resource (or data source) to be rendered
+type hclResource struct {
+	Type     string
+	Name     string
+	Data     bool
+	ImportID string
+	Schema   map[string]*schema.Schema
+	Attrs    map[string]interface{}
+}
+
+// Address terraform address of this resource
+func (r *hclResource) Address() string {
+	if r.Data {
+		return "data." + r.Type + "." + r.Name
+	}
+	return r.Type + "." + r.Name
+}
+
+// Ref expression referencing an attribute of this resource
+func (r *hclResource) Ref(attr string) hclExpr {
+	return hclExpr(r.Address() + "." + attr)
+}
+
+// attributes rendered before all others, in this order
+var hclAttrOrder = []string{
+	"hostid",
+	"ruleid",
+	"master_itemid",
+	"host",
+	"name",
+	"key",
+	"valuetype",
+	"expression",
+}
+
+var hclNameInvalid = regexp.MustCompile("[^0-9a-z]+")
+
+// hclNamer generate unique terraform resource names, per resource type
+type hclNamer struct {
+	prefix string
+	used   map[string]map[string]bool
+}
+
+func newHclNamer(prefix string) *hclNamer {
+	return &hclNamer{
+		prefix: prefix,
+		used:   map[string]map[string]bool{},
+	}
+}
+
+// Name generate a safe and unique name for a resource of type t
+func (n *hclNamer) Name(t, in string) string {
+	gen := strings.Trim(hclNameInvalid.ReplaceAllString(strings.ToLower(in), "-"), "-")
+	if n.prefix != "" {
+		gen = n.prefix + "-" + gen
+	}
+	// names must start with a letter or underscore
+	if gen == "" || !(gen[0] >= 'a' && gen[0] <= 'z') {
+		gen = "_" + gen
+	}
+
+	if n.used[t] == nil {
+		n.used[t] = map[string]bool{}
+	}
+
+	lookup := gen
+	for i := 0; n.used[t][lookup]; i++ {
+		lookup = fmt.Sprintf("%s-%d", gen, i)
+	}
+	n.used[t][lookup] = true
+
+	return lookup
+}
+
+// hclRender write resources, followed by import blocks for those with an id
+func hclRender(w io.Writer, resources []*hclResource) error {
+	var b strings.Builder
+
+	for _, r := range resources {
+		block := "resource"
+		if r.Data {
+			block = "data"
+		}
+		fmt.Fprintf(&b, "%s %q %q {\n", block, r.Type, r.Name)
+		hclRenderBody(&b, r.Schema, r.Attrs, 1)
+		b.WriteString("}\n\n")
+	}
+
+	for _, r := range resources {
+		if r.ImportID == "" {
+			continue
+		}
+		fmt.Fprintf(&b, "import {\n  to = %s\n  id = %s\n}\n\n", r.Address(), hclString(r.ImportID))
+	}
+
+	_, err := io.WriteString(w, strings.TrimSuffix(b.String(), "\n"))
+	return err
+}
+
+// hclVariable declaration of a sensitive string input variable
+func hclVariable(name string) string {
+	return fmt.Sprintf("variable %q {\n  type      = string\n  sensitive = true\n}\n\n", name)
+}
+
+// hclRenderBody render attributes and nested blocks of a schema
+func hclRenderBody(b *strings.Builder, s map[string]*schema.Schema, attrs map[string]interface{}, depth int) {
+	indent := strings.Repeat("  ", depth)
+
+	type line struct {
+		key   string
+		value string
+	}
+	lines := []line{}
+	blocks := []string{}
+
+	for _, k := range hclSortedKeys(s) {
+		sch := s[k]
+		v, ok := attrs[k]
+		if !ok || hclSkip(sch, v) {
+			continue
+		}
+
+		if elem, ok := sch.Elem.(*schema.Resource); ok {
+			for _, e := range hclListValue(v) {
+				var nested strings.Builder
+				fmt.Fprintf(&nested, "%s%s {\n", indent, k)
+				hclRenderBody(&nested, elem.Schema, e.(map[string]interface{}), depth+1)
+				fmt.Fprintf(&nested, "%s}\n", indent)
+				blocks = append(blocks, nested.String())
+			}
+			continue
+		}
+
+		lines = append(lines, line{k, hclValue(v)})
+	}
+
+	// align like terraform fmt
+	width := 0
+	for _, l := range lines {
+		if len(l.key) > width {
+			width = len(l.key)
+		}
+	}
+	for _, l := range lines {
+		fmt.Fprintf(b, "%s%-*s = %s\n", indent, width, l.key, l.value)
+	}
+
+	for i, block := range blocks {
+		if i > 0 || len(lines) > 0 {
+			b.WriteString("\n")
+		}
+		b.WriteString(block)
+	}
+}
+
+// hclSortedKeys schema keys, ordered per hclAttrOrder then alphabetically
+func hclSortedKeys(s map[string]*schema.Schema) []string {
+	keys := []string{}
+	for _, k := range hclAttrOrder {
+		if _, ok := s[k]; ok {
+			keys = append(keys, k)
+		}
+	}
+
+	rest := []string{}
+	for k := range s {
+		found := false
+		for _, o := range hclAttrOrder {
+			if k == o {
+				found = true
+				break
+			}
+		}
+		if !found {
+			rest = append(rest, k)
+		}
+	}
+	sort.Strings(rest)
+
+	return append(keys, rest...)
+}
+
+// hclSkip should this value be left out of the rendered config
+func hclSkip(s *schema.Schema, v interface{}) bool {
+	// computed only, not configurable
+	if !s.Required && !s.Optional {
+		return true
+	}
+	if s.Required {
+		return false
+	}
+	if _, ok := v.(hclExpr); ok {
+		return false
+	}
+	if s.Default != nil {
+		return reflect.DeepEqual(s.Default, v)
+	}
+
+	switch val := v.(type) {
+	case nil:
+		return true
+	case string:
+		return val == ""
+	case bool:
+		return !val
+	case int:
+		return val == 0
+	case map[string]interface{}:
+		return len(val) == 0
+	}
+	return len(hclListValue(v)) == 0
+}
+
+// hclListValue normalise lists and sets
+func hclListValue(v interface{}) []interface{} {
+	switch val := v.(type) {
+	case *schema.Set:
+		return val.List()
+	case []interface{}:
+		return val
+	}
+	return nil
+}
+
+// hclValue render a single attribute value
+func hclValue(v interface{}) string {
+	switch val := v.(type) {
+	case hclExpr:
+		return string(val)
+	case string:
+		return hclString(val)
+	case bool, int, float64:
+		return fmt.Sprintf("%v", val)
+	case map[string]interface{}:
+		keys := []string{}
+		for k := range val {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		parts := make([]string, len(keys))
+		for i, k := range keys {
+			parts[i] = hclString(k) + " = " + hclValue(val[k])
+		}
+		return "{ " + strings.Join(parts, ", ") + " }"
+	}
+
+	list := hclListValue(v)
+	parts := make([]string, len(list))
+	for i, e := range list {
+		parts[i] = hclValue(e)
+	}
+	return "[" + strings.Join(parts, ", ") + "]"
+}
+
+// hclString quote a string, escaping template sequences
+func hclString(s string) string {
+	return `"` + hclEscape(s) + `"`
+}
+
+// hclEscape escape a string for use within quotes
+func hclEscape(s string) string {
+	var b strings.Builder
+	for i, r := range s {
+		switch r {
+		case '\\':
+			b.WriteString(`\\`)
+		case '"':
+			b.WriteString(`\"`)
+		case '\n':
+			b.WriteString(`\n`)
+		case '\r':
+			b.WriteString(`\r`)
+		case '\t':
+			b.WriteString(`\t`)
+		case '$', '%':
+			b.WriteRune(r)
+			if i+1 < len(s) && s[i+1] == '{' {
+				b.WriteRune(r)
+			}
+		default:
+			if r < 0x20 {
+				fmt.Fprintf(&b, `\u%04x`, r)
+			} else {
+				b.WriteRune(r)
+			}
+		}
+	}
+	return b.String()
+}
+
+// hclTemplate a quoted string mixing literal text and interpolated expressions
+type hclTemplate struct {
+	b        strings.Builder
+	hasExprs bool
+}
+
+// Literal append escaped literal text
+func (t *hclTemplate) Literal(s string) {
+	t.b.WriteString(hclEscape(s))
+}
+
+// Interpolate append an interpolated expression
+func (t *hclTemplate) Interpolate(e hclExpr) {
+	t.hasExprs = true
+	t.b.WriteString("${" + string(e) + "}")
+}
+
+// Value the rendered template, or plain string when nothing was interpolated
+func (t *hclTemplate) Value(plain string) interface{} {
+	if !t.hasExprs {
+		return plain
+	}
+	return hclExpr(`"` + t.b.String() + `"`)
+}
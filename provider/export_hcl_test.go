@@ -0,0 +1,91 @@
+package provider
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestHclString(t *testing.T) {
+	cases := map[string]string{
+		"plain":              `"plain"`,
+		"a \"quoted\" \\ x":  `"a \"quoted\" \\ x"`,
+		"line\nbreak\t":      `"line\nbreak\t"`,
+		"{$MACRO} ${x} %{y}": `"{$MACRO} $${x} %%{y}"`,
+		"100%":               `"100%"`,
+	}
+	for in, expected := range cases {
+		if got := hclString(in); got != expected {
+			t.Errorf("%q: expected %s, got %s", in, expected, got)
+		}
+	}
+}
+
+func TestHclNamer(t *testing.T) {
+	n := newHclNamer("")
+	cases := []struct {
+		t, in, expected string
+	}{
+		{"zabbix_item_agent", "system.cpu.load[all,avg1]", "system-cpu-load-all-avg1"},
+		{"zabbix_item_agent", "system.cpu.load[all,avg1]", "system-cpu-load-all-avg1-0"},
+		{"zabbix_item_snmp", "system.cpu.load[all,avg1]", "system-cpu-load-all-avg1"},
+		{"zabbix_trigger", "5 minute load", "_5-minute-load"},
+	}
+	for _, c := range cases {
+		if got := n.Name(c.t, c.in); got != c.expected {
+			t.Errorf("%s %q: expected %s, got %s", c.t, c.in, c.expected, got)
+		}
+	}
+
+	if got := newHclNamer("linux").Name("zabbix_trigger", "Load"); got != "linux-load" {
+		t.Errorf("expected prefixed name, got %s", got)
+	}
+}
+
+func TestHclRender(t *testing.T) {
+	resources := []*hclResource{
+		&hclResource{
+			Type:     "zabbix_trigger",
+			Name:     "high-load",
+			ImportID: "13",
+			Schema:   schemaTrigger,
+			Attrs: map[string]interface{}{
+				"name":         "High load",
+				"expression":   "{Linux:system.cpu.load.last()}>5",
+				"priority":     "high",
+				"enabled":      true,
+				"multiple":     false,
+				"comments":     "",
+				"dependencies": []interface{}{hclExpr("zabbix_trigger.other.id"), "99"},
+				"tag": []interface{}{
+					map[string]interface{}{"key": "scope", "value": "performance"},
+				},
+			},
+		},
+	}
+
+	expected := `resource "zabbix_trigger" "high-load" {
+  name         = "High load"
+  expression   = "{Linux:system.cpu.load.last()}>5"
+  dependencies = [zabbix_trigger.other.id, "99"]
+  priority     = "high"
+
+  tag {
+    key   = "scope"
+    value = "performance"
+  }
+}
+
+import {
+  to = zabbix_trigger.high-load
+  id = "13"
+}
+`
+
+	var b strings.Builder
+	if err := hclRender(&b, resources); err != nil {
+		t.Fatal(err)
+	}
+	if b.String() != expected {
+		t.Errorf("unexpected render:\n%s\nexpected:\n%s", b.String(), expected)
+	}
+}
@@ -0,0 +1,111 @@
+package provider
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/tpretz/go-zabbix-api"
+)
+
+// exportTestRequest a request received by the fake api
+type exportTestRequest struct {
+	Method string          `json:"method"`
+	Params json.RawMessage `json:"params"`
+	ID     json.RawMessage `json:"id"`
+}
+
+// fake json-rpc api, returning a fixed result per method
+func exportTestServer(t *testing.T, results map[string]string) *httptest.Server {
+	return exportTestRecorder(t, results, nil)
+}
+
+// exportTestRecorder fake json-rpc api, also recording each request when given a list
+func exportTestRecorder(t *testing.T, results map[string]string, requests *[]exportTestRequest) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req exportTestRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Fatal(err)
+		}
+		req.Method = strings.ToLower(req.Method)
+		if requests != nil {
+			*requests = append(*requests, req)
+		}
+		result, ok := results[req.Method]
+		if !ok {
+			result = "[]"
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"jsonrpc":"2.0","id":` + string(req.ID) + `,"result":` + result + `}`))
+	}))
+}
+
+func TestExportTemplate(t *testing.T) {
+	server := exportTestServer(t, map[string]string{
+		"apiinfo.version": `"6.0.0"`,
+		"template.get":    `[{"templateid":"10001","host":"Template Test","name":"Template Test","description":"","groups":[{"groupid":"1"}],"macros":[],"parentTemplates":[]}]`,
+		"item.get":        `[{"itemid":"20001","type":"2","key_":"trap.value","name":"Trap value","hostid":"10001","value_type":"3","history":"90d","trends":"365d","status":"0","preprocessing":[],"tags":[]}]`,
+		"trigger.get":     `[{"triggerid":"30001","description":"Trap high","expression":"last(/Template Test/trap.value)>5","priority":"4","status":"0","type":"0","recovery_mode":"0","correlation_mode":"0","manual_close":"0","dependencies":[],"tags":[]}]`,
+	})
+	defer server.Close()
+
+	api, err := zabbix.NewAPI(zabbix.Config{Url: server.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var b strings.Builder
+	if err := ExportTemplate(api, ExportOptions{Template: "Template Test"}, &b); err != nil {
+		t.Fatal(err)
+	}
+	out := b.String()
+
+	for _, expected := range []string{
+		`resource "zabbix_template" "template-test" {`,
+		`resource "zabbix_item_trapper" "trap-value" {`,
+		`  hostid    = zabbix_template.template-test.id`,
+		`resource "zabbix_trigger" "trap-high" {`,
+		`  expression = "last(/Template Test/trap.value)>5"`,
+		"import {\n  to = zabbix_item_trapper.trap-value\n  id = \"20001\"\n}",
+		"import {\n  to = zabbix_trigger.trap-high\n  id = \"30001\"\n}",
+	} {
+		if !strings.Contains(out, expected) {
+			t.Errorf("expected output to contain %q, got:\n%s", expected, out)
+		}
+	}
+}
+
+func TestExportTemplateSensitive(t *testing.T) {
+	server := exportTestServer(t, map[string]string{
+		"apiinfo.version": `"6.0.0"`,
+		"template.get":    `[{"templateid":"10001","host":"Template Test","name":"Template Test","description":"","groups":[{"groupid":"1"}],"macros":[],"parentTemplates":[]}]`,
+		"item.get":        `[{"itemid":"20002","type":"13","key_":"ssh.run[uptime]","name":"Uptime","hostid":"10001","value_type":"4","history":"90d","trends":"0","status":"0","authtype":"0","username":"root","password":"hunter2","params":"uptime","preprocessing":[],"tags":[]}]`,
+	})
+	defer server.Close()
+
+	api, err := zabbix.NewAPI(zabbix.Config{Url: server.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var b strings.Builder
+	if err := ExportTemplate(api, ExportOptions{Template: "Template Test"}, &b); err != nil {
+		t.Fatal(err)
+	}
+	out := b.String()
+
+	if strings.Contains(out, "hunter2") {
+		t.Errorf("sensitive value written to output:\n%s", out)
+	}
+	for _, expected := range []string{
+		"# skipped zabbix_item_ssh.ssh-run-uptime password value (sensitive), set var.item_ssh_ssh_run_uptime_password instead",
+		"variable \"item_ssh_ssh_run_uptime_password\" {\n  type      = string\n  sensitive = true\n}",
+		"  password    = var.item_ssh_ssh_run_uptime_password",
+	} {
+		if !strings.Contains(out, expected) {
+			t.Errorf("expected output to contain %q, got:\n%s", expected, out)
+		}
+	}
+}
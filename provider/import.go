@@ -0,0 +1,132 @@
+package provider
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+	"github.com/tpretz/go-zabbix-api"
+)
+
+var importNumericId = regexp.MustCompile("^[0-9]+$")
+
+// importLookup resolve a natural import key to an object id
+type importLookup func(api *zabbix.API, key string) (string, error)
+
+// importStateWrapper import handler accepting numeric ids or natural keys
+func importStateWrapper(lookup importLookup) schema.StateFunc {
+	return func(d *schema.ResourceData, m interface{}) ([]*schema.ResourceData, error) {
+		if importNumericId.MatchString(d.Id()) {
+			return []*schema.ResourceData{d}, nil
+		}
+
+		id, err := lookup(metaAPI(m), d.Id())
+		if err != nil {
+			return nil, err
+		}
+		log.Debug("resolved import key %s to id %s", d.Id(), id)
+
+		d.SetId(id)
+		return []*schema.ResourceData{d}, nil
+	}
+}
+
+// importLookupByName lookup of a top level object by its name, ie host or template
+func importLookupByName(method, idField, nameField string) importLookup {
+	return func(api *zabbix.API, key string) (string, error) {
+		return importFind(api, method, idField, zabbix.Params{
+			"filter": map[string]interface{}{nameField: key},
+		}, key)
+	}
+}
+
+// importLookupByHost lookup of a host child object, key format <host>:<name>
+func importLookupByHost(method, idField, nameField string) importLookup {
+	return func(api *zabbix.API, key string) (string, error) {
+		// host names cannot contain ':', item keys and names can
+		parts := strings.SplitN(key, ":", 2)
+		if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+			return "", fmt.Errorf("invalid import id %q, expected a numeric id or <host>:<name>", key)
+		}
+
+		hostid, err := importFind(api, "host.get", "hostid", zabbix.Params{
+			"filter":          map[string]interface{}{"host": parts[0]},
+			"templated_hosts": true,
+		}, parts[0])
+		if err != nil {
+			return "", err
+		}
+
+		return importFind(api, method, idField, zabbix.Params{
+			"hostids": []string{hostid},
+			"filter":  map[string]interface{}{nameField: parts[1]},
+		}, key)
+	}
+}
+
+// importFind run a lookup expecting a single result
+func importFind(api *zabbix.API, method, idField string, params zabbix.Params, key string) (string, error) {
+	params["output"] = []string{idField}
+
+	var res []map[string]interface{}
+	if err := api.CallWithErrorParse(method, params, &res); err != nil {
+		return "", err
+	}
+	if len(res) < 1 {
+		return "", fmt.Errorf("%s found no object matching %q", method, key)
+	}
+	if len(res) > 1 {
+		return "", fmt.Errorf("%s found %d objects matching %q, import by id instead", method, len(res), key)
+	}
+
+	id, ok := res[0][idField].(string)
+	if !ok {
+		return "", fmt.Errorf("%s returned no %s for %q", method, idField, key)
+	}
+	return id, nil
+}
+
+func itemImportState(prototype bool) schema.StateFunc {
+	if prototype {
+		return importStateWrapper(importLookupByHost("itemprototype.get", "itemid", "key_"))
+	}
+	return importStateWrapper(importLookupByHost("item.get", "itemid", "key_"))
+}
+
+func lldImportState(prototype bool) schema.StateFunc {
+	if prototype {
+		return importStateWrapper(importLookupByHost("discoveryruleprototype.get", "itemid", "key_"))
+	}
+	return importStateWrapper(importLookupByHost("discoveryrule.get", "itemid", "key_"))
+}
+
+func triggerImportState(prototype bool) schema.StateFunc {
+	if prototype {
+		return importStateWrapper(importLookupByHost("triggerprototype.get", "triggerid", "description"))
+	}
+	return importStateWrapper(importLookupByHost("trigger.get", "triggerid", "description"))
+}
+
+func graphImportState(prototype bool) schema.StateFunc {
+	if prototype {
+		return importStateWrapper(importLookupByHost("graphprototype.get", "graphid", "name"))
+	}
+	return importStateWrapper(importLookupByHost("graph.get", "graphid", "name"))
+}
+
+func hostImportState() schema.StateFunc {
+	return importStateWrapper(importLookupByName("host.get", "hostid", "host"))
+}
+
+func templateImportState() schema.StateFunc {
+	return importStateWrapper(importLookupByName("template.get", "templateid", "host"))
+}
+
+func hostgroupImportState() schema.StateFunc {
+	return importStateWrapper(importLookupByName("hostgroup.get", "groupid", "name"))
+}
+
+func correlationImportState() schema.StateFunc {
+	return importStateWrapper(importLookupByName("correlation.get", "correlationid", "name"))
+}
@@ -0,0 +1,37 @@
+package provider
+
+import (
+	"testing"
+
+	"github.com/tpretz/go-zabbix-api"
+)
+
+func TestImportStateWrapper(t *testing.T) {
+	called := false
+	state := importStateWrapper(func(api *zabbix.API, key string) (string, error) {
+		called = true
+		return "42", nil
+	})
+
+	d := resourceHostgroup().TestResourceData()
+	d.SetId("1234")
+	res, err := state(d, &zabbix.API{})
+	if err != nil || called || res[0].Id() != "1234" {
+		t.Errorf("expected numeric id passthrough, got %v %v", res, err)
+	}
+
+	d.SetId("Linux servers")
+	res, err = state(d, &zabbix.API{})
+	if err != nil || !called || res[0].Id() != "42" {
+		t.Errorf("expected natural key lookup, got %v %v", res, err)
+	}
+}
+
+func TestImportLookupByHostKey(t *testing.T) {
+	lookup := importLookupByHost("item.get", "itemid", "key_")
+	for _, key := range []string{"nohost", ":key", "host:"} {
+		if _, err := lookup(nil, key); err == nil {
+			t.Errorf("expected %q to be rejected", key)
+		}
+	}
+}
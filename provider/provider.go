@@ -9,6 +9,29 @@ import (
 	"github.com/tpretz/go-zabbix-api"
 )
 
+// providerMeta passed to resources, the api client and provider level settings
+type providerMeta struct {
+	api                  *zabbix.API
+	normalizeExpressions bool
+}
+
+// metaAPI api client of the provider meta, a bare client is accepted as is
+func metaAPI(m interface{}) *zabbix.API {
+	switch v := m.(type) {
+	case *providerMeta:
+		return v.api
+	case *zabbix.API:
+		return v
+	}
+	return nil
+}
+
+// metaNormalizeExpressions normalize_expressions provider setting
+func metaNormalizeExpressions(m interface{}) bool {
+	v, ok := m.(*providerMeta)
+	return ok && v.normalizeExpressions
+}
+
 // Provider definition
 func Provider() *schema.Provider {
 	return &schema.Provider{
@@ -46,6 +69,12 @@ func Provider() *schema.Provider {
 				Default:     false,
 				Description: "Serialize API requests, if required due to API race conditions",
 			},
+			"normalize_expressions": &schema.Schema{
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+				Description: "Convert legacy {host:key.func()} trigger expressions to the zabbix 5.4+ syntax before sending",
+			},
 		},
 		DataSourcesMap: map[string]*schema.Resource{
 			"zabbix_host":        dataHost(),
@@ -53,14 +82,23 @@ func Provider() *schema.Provider {
 			"zabbix_proxy":       dataProxy(),
 			"zabbix_hostgroup":   dataHostgroup(),
 			"zabbix_template":    dataTemplate(),
+
+			"zabbix_preprocessing_test":   dataPreprocessingTest(),
+			"zabbix_configuration_export": dataConfigurationExport(),
 		},
 		ResourcesMap: map[string]*schema.Resource{
-			"zabbix_trigger":       resourceTrigger(),
-			"zabbix_proto_trigger": resourceProtoTrigger(),
-			"zabbix_template":      resourceTemplate(),
-			"zabbix_hostgroup":     resourceHostgroup(),
-			"zabbix_host":          resourceHost(),
-			"zabbix_application":   resourceApplication(),
+			"zabbix_trigger":        resourceTrigger(),
+			"zabbix_proto_trigger":  resourceProtoTrigger(),
+			"zabbix_template":       resourceTemplate(),
+			"zabbix_hostgroup":      resourceHostgroup(),
+			"zabbix_host":           resourceHost(),
+			"zabbix_host_interface": resourceHostInterface(),
+			"zabbix_application":    resourceApplication(),
+
+			"zabbix_trigger_dependency": resourceTriggerDependency(),
+			"zabbix_correlation":        resourceCorrelation(),
+
+			"zabbix_configuration_import": resourceConfigurationImport(),
 
 			"zabbix_graph":       resourceGraph(),
 			"zabbix_proto_graph": resourceProtoGraph(),
@@ -68,26 +106,40 @@ func Provider() *schema.Provider {
 			"zabbix_item_trapper":       resourceItemTrapper(),
 			"zabbix_proto_item_trapper": resourceProtoItemTrapper(),
 			"zabbix_lld_trapper":        resourceLLDTrapper(),
+			"zabbix_proto_lld_trapper":  resourceProtoLLDTrapper(),
 
 			"zabbix_item_http":       resourceItemHttp(),
 			"zabbix_proto_item_http": resourceProtoItemHttp(),
 			"zabbix_lld_http":        resourceLLDHttp(),
+			"zabbix_proto_lld_http":  resourceProtoLLDHttp(),
+
+			"zabbix_item_script":       resourceItemScript(),
+			"zabbix_proto_item_script": resourceProtoItemScript(),
+			"zabbix_lld_script":        resourceLLDScript(),
+			"zabbix_proto_lld_script":  resourceProtoLLDScript(),
+
+			"zabbix_item_browser":       resourceItemBrowser(),
+			"zabbix_proto_item_browser": resourceProtoItemBrowser(),
 
 			"zabbix_item_simple":       resourceItemSimple(),
 			"zabbix_proto_item_simple": resourceProtoItemSimple(),
 			"zabbix_lld_simple":        resourceLLDSimple(),
+			"zabbix_proto_lld_simple":  resourceProtoLLDSimple(),
 
 			"zabbix_item_external":       resourceItemExternal(),
 			"zabbix_proto_item_external": resourceProtoItemExternal(),
 			"zabbix_lld_external":        resourceLLDExternal(),
+			"zabbix_proto_lld_external":  resourceProtoLLDExternal(),
 
 			"zabbix_item_internal":       resourceItemInternal(),
 			"zabbix_proto_item_internal": resourceProtoItemInternal(),
 			"zabbix_lld_internal":        resourceLLDInternal(),
+			"zabbix_proto_lld_internal":  resourceProtoLLDInternal(),
 
 			"zabbix_item_snmp":       resourceItemSnmp(),
 			"zabbix_proto_item_snmp": resourceProtoItemSnmp(),
 			"zabbix_lld_snmp":        resourceLLDSnmp(),
+			"zabbix_proto_lld_snmp":  resourceProtoLLDSnmp(),
 
 			"zabbix_item_snmptrap":       resourceItemSnmpTrap(),
 			"zabbix_proto_item_snmptrap": resourceProtoItemSnmpTrap(),
@@ -95,6 +147,7 @@ func Provider() *schema.Provider {
 			"zabbix_item_agent":       resourceItemAgent(),
 			"zabbix_proto_item_agent": resourceProtoItemAgent(),
 			"zabbix_lld_agent":        resourceLLDAgent(),
+			"zabbix_proto_lld_agent":  resourceProtoLLDAgent(),
 
 			"zabbix_item_aggregate":       resourceItemAggregate(),
 			"zabbix_proto_item_aggregate": resourceProtoItemAggregate(),
@@ -105,6 +158,32 @@ func Provider() *schema.Provider {
 			"zabbix_item_dependent":       resourceItemDependent(),
 			"zabbix_proto_item_dependent": resourceProtoItemDependent(),
 			"zabbix_lld_dependent":        resourceLLDDependent(),
+			"zabbix_proto_lld_dependent":  resourceProtoLLDDependent(),
+
+			"zabbix_item_ipmi":       resourceItemIpmi(),
+			"zabbix_proto_item_ipmi": resourceProtoItemIpmi(),
+			"zabbix_lld_ipmi":        resourceLLDIpmi(),
+			"zabbix_proto_lld_ipmi":  resourceProtoLLDIpmi(),
+
+			"zabbix_item_jmx":       resourceItemJmx(),
+			"zabbix_proto_item_jmx": resourceProtoItemJmx(),
+			"zabbix_lld_jmx":        resourceLLDJmx(),
+			"zabbix_proto_lld_jmx":  resourceProtoLLDJmx(),
+
+			"zabbix_item_ssh":       resourceItemSsh(),
+			"zabbix_proto_item_ssh": resourceProtoItemSsh(),
+			"zabbix_lld_ssh":        resourceLLDSsh(),
+			"zabbix_proto_lld_ssh":  resourceProtoLLDSsh(),
+
+			"zabbix_item_telnet":       resourceItemTelnet(),
+			"zabbix_proto_item_telnet": resourceProtoItemTelnet(),
+			"zabbix_lld_telnet":        resourceLLDTelnet(),
+			"zabbix_proto_lld_telnet":  resourceProtoLLDTelnet(),
+
+			"zabbix_item_odbc":       resourceItemOdbc(),
+			"zabbix_proto_item_odbc": resourceProtoItemOdbc(),
+			"zabbix_lld_odbc":        resourceLLDOdbc(),
+			"zabbix_proto_lld_odbc":  resourceProtoLLDOdbc(),
 		},
 		ConfigureFunc: providerConfigure,
 	}
@@ -126,7 +205,10 @@ func providerConfigure(d *schema.ResourceData) (meta interface{}, err error) {
 	}
 
 	_, err = api.Login(d.Get("username").(string), d.Get("password").(string))
-	meta = api
+	meta = &providerMeta{
+		api:                  api,
+		normalizeExpressions: d.Get("normalize_expressions").(bool),
+	}
 	log.Trace("Started zabbix provider got error: %+v", err)
 
 	return
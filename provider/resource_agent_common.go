@@ -1,6 +1,7 @@
 package provider
 
 import (
+	"github.com/hashicorp/terraform-plugin-sdk/helper/customdiff"
 	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
 	"github.com/tpretz/go-zabbix-api"
 )
@@ -22,10 +23,11 @@ func resourceItemAgent() *schema.Resource {
 		Update: itemGetUpdateWrapper(itemAgentModFunc, itemAgentReadFunc),
 		Delete: resourceItemDelete,
 		Importer: &schema.ResourceImporter{
-			State: schema.ImportStatePassthrough,
+			State: itemImportState(false),
 		},
+		CustomizeDiff: customdiff.All(itemCustomizeDiff, itemTimeoutCustomizeDiff),
 
-		Schema: mergeSchemas(itemCommonSchema, itemDelaySchema, itemInterfaceSchema, schemaAgent),
+		Schema: mergeSchemas(itemCommonSchema, itemDelaySchema, itemInterfaceSchema, itemTimeoutSchema, schemaAgent),
 	}
 }
 func resourceProtoItemAgent() *schema.Resource {
@@ -35,10 +37,11 @@ func resourceProtoItemAgent() *schema.Resource {
 		Update: protoItemGetUpdateWrapper(itemAgentModFunc, itemAgentReadFunc),
 		Delete: resourceProtoItemDelete,
 		Importer: &schema.ResourceImporter{
-			State: schema.ImportStatePassthrough,
+			State: itemImportState(true),
 		},
+		CustomizeDiff: customdiff.All(itemCustomizeDiff, itemTimeoutCustomizeDiff),
 
-		Schema: mergeSchemas(itemCommonSchema, itemDelaySchema, itemInterfaceSchema, itemPrototypeSchema, schemaAgent),
+		Schema: mergeSchemas(itemCommonSchema, itemDelaySchema, itemInterfaceSchema, itemTimeoutSchema, itemPrototypeSchema, schemaAgent),
 	}
 }
 func resourceLLDAgent() *schema.Resource {
@@ -48,14 +51,29 @@ func resourceLLDAgent() *schema.Resource {
 		Update: lldGetUpdateWrapper(lldAgentModFunc, lldAgentReadFunc),
 		Delete: resourceLLDDelete,
 		Importer: &schema.ResourceImporter{
-			State: schema.ImportStatePassthrough,
+			State: lldImportState(false),
 		},
+		CustomizeDiff: lldCustomizeDiff,
 
 		Schema: mergeSchemas(lldCommonSchema, lldInterfaceSchema, schemaAgent),
 	}
 }
+func resourceProtoLLDAgent() *schema.Resource {
+	return &schema.Resource{
+		Create: protoLLDGetCreateWrapper(lldAgentModFunc, lldAgentReadFunc),
+		Read:   protoLLDGetReadWrapper(lldAgentReadFunc),
+		Update: protoLLDGetUpdateWrapper(lldAgentModFunc, lldAgentReadFunc),
+		Delete: resourceProtoLLDDelete,
+		Importer: &schema.ResourceImporter{
+			State: lldImportState(true),
+		},
+		CustomizeDiff: lldCustomizeDiff,
+
+		Schema: mergeSchemas(lldCommonSchema, lldInterfaceSchema, schemaAgent, lldPrototypeSchema),
+	}
+}
 
-func itemAgentModFunc(d *schema.ResourceData, m interface{}, item *zabbix.Item) {
+func itemAgentModFunc(d *schema.ResourceData, m interface{}, item *apiItem) {
 	t := zabbix.ZabbixAgent
 	if d.Get("active").(bool) {
 		t = zabbix.ZabbixAgentActive
@@ -65,7 +83,7 @@ func itemAgentModFunc(d *schema.ResourceData, m interface{}, item *zabbix.Item)
 	item.Delay = d.Get("delay").(string)
 }
 
-func lldAgentModFunc(d *schema.ResourceData, m interface{}, item *zabbix.LLDRule) {
+func lldAgentModFunc(d *schema.ResourceData, m interface{}, item *apiLLDRule) {
 	t := zabbix.ZabbixAgent
 	if d.Get("active").(bool) {
 		t = zabbix.ZabbixAgentActive
@@ -74,13 +92,13 @@ func lldAgentModFunc(d *schema.ResourceData, m interface{}, item *zabbix.LLDRule
 	item.InterfaceID = d.Get("interfaceid").(string)
 }
 
-func itemAgentReadFunc(d *schema.ResourceData, m interface{}, item *zabbix.Item) {
+func itemAgentReadFunc(d *schema.ResourceData, m interface{}, item *apiItem) {
 	d.Set("interfaceid", item.InterfaceID)
 	d.Set("delay", item.Delay)
 	d.Set("active", item.Type == zabbix.ZabbixAgentActive)
 }
 
-func lldAgentReadFunc(d *schema.ResourceData, m interface{}, item *zabbix.LLDRule) {
+func lldAgentReadFunc(d *schema.ResourceData, m interface{}, item *apiLLDRule) {
 	d.Set("interfaceid", item.InterfaceID)
 	d.Set("active", item.Type == zabbix.ZabbixAgentActive)
 }
@@ -13,8 +13,9 @@ func resourceItemAggregate() *schema.Resource {
 		Update: itemGetUpdateWrapper(itemAggregateModFunc, itemAggregateReadFunc),
 		Delete: resourceItemDelete,
 		Importer: &schema.ResourceImporter{
-			State: schema.ImportStatePassthrough,
+			State: itemImportState(false),
 		},
+		CustomizeDiff: itemCustomizeDiff,
 
 		Schema: mergeSchemas(itemCommonSchema, itemDelaySchema),
 	}
@@ -26,20 +27,21 @@ func resourceProtoItemAggregate() *schema.Resource {
 		Update: protoItemGetUpdateWrapper(itemAggregateModFunc, itemAggregateReadFunc),
 		Delete: resourceProtoItemDelete,
 		Importer: &schema.ResourceImporter{
-			State: schema.ImportStatePassthrough,
+			State: itemImportState(true),
 		},
+		CustomizeDiff: itemCustomizeDiff,
 
 		Schema: mergeSchemas(itemCommonSchema, itemDelaySchema, itemPrototypeSchema),
 	}
 }
 
 // Custom mod handler for item type
-func itemAggregateModFunc(d *schema.ResourceData, m interface{}, item *zabbix.Item) {
+func itemAggregateModFunc(d *schema.ResourceData, m interface{}, item *apiItem) {
 	item.Type = zabbix.ZabbixAggregate
 	item.Delay = d.Get("delay").(string)
 }
 
 // Custom read handler for item type
-func itemAggregateReadFunc(d *schema.ResourceData, m interface{}, item *zabbix.Item) {
+func itemAggregateReadFunc(d *schema.ResourceData, m interface{}, item *apiItem) {
 	d.Set("delay", item.Delay)
 }
@@ -61,7 +61,7 @@ func dataApplication() *schema.Resource {
 
 // terraform Application create function
 func resourceApplicationCreate(d *schema.ResourceData, m interface{}) error {
-	api := m.(*zabbix.API)
+	api := metaAPI(m)
 
 	item := zabbix.Application{
 		Name:   d.Get("name").(string),
@@ -85,7 +85,7 @@ func resourceApplicationCreate(d *schema.ResourceData, m interface{}) error {
 
 // ApplicationRead terraform Application read function
 func ApplicationRead(d *schema.ResourceData, m interface{}, params zabbix.Params) error {
-	api := m.(*zabbix.API)
+	api := metaAPI(m)
 
 	if api.Config.Version >= 50400 {
 		return errors.New("application API no longer supported in zabbix versions >= 5.4, see documentation around the use of tags to replace its behaviour")
@@ -140,6 +140,6 @@ func resourceApplicationRead(d *schema.ResourceData, m interface{}) error {
 
 // resourceApplicationDelete terraform resource delete handler
 func resourceApplicationDelete(d *schema.ResourceData, m interface{}) error {
-	api := m.(*zabbix.API)
+	api := metaAPI(m)
 	return api.ApplicationsDeleteByIds([]string{d.Id()})
 }
@@ -0,0 +1,126 @@
+package provider
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+
+	"github.com/hashicorp/terraform-plugin-sdk/helper/customdiff"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/validation"
+	"github.com/tpretz/go-zabbix-api"
+)
+
+// browser item type, not present in the api library
+const BrowserItem zabbix.ItemType = 22
+
+// user macro references, ie {$BROWSER.URL} or {$BROWSER.URL:"context"}
+var browserMacroRef = regexp.MustCompile(`\{\$[^}]*\}?`)
+var browserMacroValid = regexp.MustCompile(`^\{\$[A-Z0-9_.]+(:.*)?\}$`)
+
+var schemaBrowser = map[string]*schema.Schema{
+	"script": &schema.Schema{
+		Type:         schema.TypeString,
+		Required:     true,
+		Description:  "JavaScript code to execute against the WebDriver session",
+		ValidateFunc: validation.StringIsNotWhiteSpace,
+	},
+	"parameter": &schema.Schema{
+		Type:        schema.TypeSet,
+		Optional:    true,
+		Description: "Script parameters, values may reference user macros",
+		Elem: &schema.Resource{
+			Schema: map[string]*schema.Schema{
+				"name": &schema.Schema{
+					Type:         schema.TypeString,
+					Required:     true,
+					Description:  "Parameter name",
+					ValidateFunc: validation.StringIsNotWhiteSpace,
+				},
+				"value": &schema.Schema{
+					Type:         schema.TypeString,
+					Optional:     true,
+					Description:  "Parameter value",
+					ValidateFunc: validateBrowserMacros,
+				},
+			},
+		},
+	},
+	"timeout": &schema.Schema{
+		Type:        schema.TypeString,
+		Optional:    true,
+		Description: "Script execution timeout, empty to use the proxy/global setting",
+	},
+}
+
+// resourceItemBrowser Browser item resource handler
+func resourceItemBrowser() *schema.Resource {
+	return &schema.Resource{
+		Create: itemGetCreateWrapper(itemBrowserModFunc, itemBrowserReadFunc),
+		Read:   itemGetReadWrapper(itemBrowserReadFunc),
+		Update: itemGetUpdateWrapper(itemBrowserModFunc, itemBrowserReadFunc),
+		Delete: resourceItemDelete,
+		Importer: &schema.ResourceImporter{
+			State: itemImportState(false),
+		},
+		CustomizeDiff: customdiff.All(itemCustomizeDiff, browserCustomizeDiff),
+
+		Schema: mergeSchemas(itemCommonSchema, itemDelaySchema, itemInterfaceSchema, schemaBrowser),
+	}
+}
+func resourceProtoItemBrowser() *schema.Resource {
+	return &schema.Resource{
+		Create: protoItemGetCreateWrapper(itemBrowserModFunc, itemBrowserReadFunc),
+		Read:   protoItemGetReadWrapper(itemBrowserReadFunc),
+		Update: protoItemGetUpdateWrapper(itemBrowserModFunc, itemBrowserReadFunc),
+		Delete: resourceProtoItemDelete,
+		Importer: &schema.ResourceImporter{
+			State: itemImportState(true),
+		},
+		CustomizeDiff: customdiff.All(itemCustomizeDiff, browserCustomizeDiff),
+
+		Schema: mergeSchemas(itemCommonSchema, itemDelaySchema, itemInterfaceSchema, itemPrototypeSchema, schemaBrowser),
+	}
+}
+
+// browser items only exist from 7.0
+func browserCustomizeDiff(d *schema.ResourceDiff, m interface{}) error {
+	api := metaAPI(m)
+	if api.Config.Version < 70000 {
+		return errors.New("browser items require zabbix 7.0 or later")
+	}
+	return nil
+}
+
+// validateBrowserMacros ensure any user macro references in a value are well formed
+func validateBrowserMacros(i interface{}, k string) (s []string, es []error) {
+	v, ok := i.(string)
+	if !ok {
+		es = append(es, fmt.Errorf("expected type of %s to be string", k))
+		return
+	}
+	for _, ref := range browserMacroRef.FindAllString(v, -1) {
+		if !browserMacroValid.MatchString(ref) {
+			es = append(es, fmt.Errorf("%s contains invalid user macro reference %q, expected {$NAME} or {$NAME:context}", k, ref))
+		}
+	}
+	return
+}
+
+// browser item modify custom function
+func itemBrowserModFunc(d *schema.ResourceData, m interface{}, item *apiItem) {
+	item.Type = BrowserItem
+	item.Delay = d.Get("delay").(string)
+	item.InterfaceID = d.Get("interfaceid").(string)
+	item.Params = d.Get("script").(string)
+	item.Parameters = scriptGenerateParameters(d)
+}
+
+// browser item read custom function
+func itemBrowserReadFunc(d *schema.ResourceData, m interface{}, item *apiItem) {
+	d.Set("delay", item.Delay)
+	d.Set("interfaceid", item.InterfaceID)
+	d.Set("script", item.Params)
+	d.Set("timeout", derefString(item.Timeout))
+	d.Set("parameter", scriptFlattenParameters(item.Parameters))
+}
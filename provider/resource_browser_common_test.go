@@ -0,0 +1,27 @@
+package provider
+
+import (
+	"testing"
+)
+
+func TestValidateBrowserMacros(t *testing.T) {
+	cases := map[string]bool{
+		"":                                     true,
+		"https://example.com":                  true,
+		"{$BROWSER.URL}":                       true,
+		"{$BROWSER.URL:\"login\"}/?q={$QUERY}": true,
+		"{$browser.url}":                       false,
+		"{$BROWSER.URL":                        false,
+		"{$}":                                  false,
+	}
+
+	for v, valid := range cases {
+		_, es := validateBrowserMacros(v, "value")
+		if valid && len(es) != 0 {
+			t.Errorf("expected %q to be valid, got %v", v, es)
+		}
+		if !valid && len(es) == 0 {
+			t.Errorf("expected %q to be invalid", v)
+		}
+	}
+}
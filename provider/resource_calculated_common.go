@@ -1,6 +1,9 @@
 package provider
 
 import (
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-sdk/helper/customdiff"
 	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
 	"github.com/hashicorp/terraform-plugin-sdk/helper/validation"
 	"github.com/tpretz/go-zabbix-api"
@@ -23,8 +26,9 @@ func resourceItemCalculated() *schema.Resource {
 		Update: itemGetUpdateWrapper(itemCalculatedModFunc, itemCalculatedReadFunc),
 		Delete: resourceItemDelete,
 		Importer: &schema.ResourceImporter{
-			State: schema.ImportStatePassthrough,
+			State: itemImportState(false),
 		},
+		CustomizeDiff: customdiff.All(itemCustomizeDiff, calculatedCustomizeDiff),
 
 		Schema: mergeSchemas(itemCommonSchema, itemDelaySchema, schemaCalculated),
 	}
@@ -36,22 +40,36 @@ func resourceProtoItemCalculated() *schema.Resource {
 		Update: protoItemGetUpdateWrapper(itemCalculatedModFunc, itemCalculatedReadFunc),
 		Delete: resourceProtoItemDelete,
 		Importer: &schema.ResourceImporter{
-			State: schema.ImportStatePassthrough,
+			State: itemImportState(true),
 		},
+		CustomizeDiff: customdiff.All(itemCustomizeDiff, calculatedCustomizeDiff),
 
 		Schema: mergeSchemas(itemCommonSchema, itemDelaySchema, itemPrototypeSchema, schemaCalculated),
 	}
 }
 
 // Custom mod handler for item type
-func itemCalculatedModFunc(d *schema.ResourceData, m interface{}, item *zabbix.Item) {
+func itemCalculatedModFunc(d *schema.ResourceData, m interface{}, item *apiItem) {
 	item.Type = zabbix.Calculated
 	item.Delay = d.Get("delay").(string)
 	item.Params = d.Get("formula").(string)
 }
 
 // Custom read handler for item type
-func itemCalculatedReadFunc(d *schema.ResourceData, m interface{}, item *zabbix.Item) {
+func itemCalculatedReadFunc(d *schema.ResourceData, m interface{}, item *apiItem) {
 	d.Set("delay", item.Delay)
 	d.Set("formula", item.Params)
 }
+
+// calculatedCustomizeDiff validate formulas, only 5.4+ formulas share the trigger expression syntax
+func calculatedCustomizeDiff(d *schema.ResourceDiff, m interface{}) error {
+	api := metaAPI(m)
+
+	if api.Config.Version < expressionVersion || !d.NewValueKnown("formula") {
+		return nil
+	}
+	if _, err := parseExpression(d.Get("formula").(string), api.Config.Version); err != nil {
+		return fmt.Errorf("formula: %s", err)
+	}
+	return nil
+}
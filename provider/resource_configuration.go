@@ -0,0 +1,514 @@
+package provider
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/validation"
+	"github.com/tpretz/go-zabbix-api"
+)
+
+// import rule object types, terraform name to api name
+var CONFIGURATION_RULES = map[string]string{
+	"applications":        "applications",
+	"discovery_rules":     "discoveryRules",
+	"graphs":              "graphs",
+	"groups":              "groups",
+	"host_groups":         "host_groups",
+	"hosts":               "hosts",
+	"httptests":           "httptests",
+	"images":              "images",
+	"items":               "items",
+	"maps":                "maps",
+	"media_types":         "mediaTypes",
+	"screens":             "screens",
+	"template_dashboards": "templateDashboards",
+	"template_groups":     "template_groups",
+	"template_linkage":    "templateLinkage",
+	"template_screens":    "templateScreens",
+	"templates":           "templates",
+	"triggers":            "triggers",
+	"value_maps":          "valueMaps",
+}
+var CONFIGURATION_RULES_REV = map[string]string{}
+var CONFIGURATION_RULES_ARR = []string{}
+
+// object types within a template, updating them corrects drift of imported templates
+var CONFIGURATION_DRIFT_RULES = []string{
+	"discovery_rules",
+	"graphs",
+	"httptests",
+	"items",
+	"template_dashboards",
+	"templates",
+	"triggers",
+	"value_maps",
+}
+
+var _ = func() bool {
+	for k, v := range CONFIGURATION_RULES {
+		CONFIGURATION_RULES_REV[v] = k
+		CONFIGURATION_RULES_ARR = append(CONFIGURATION_RULES_ARR, k)
+	}
+	sort.Strings(CONFIGURATION_RULES_ARR)
+	return false
+}()
+
+// resourceConfigurationImport terraform resource handler
+func resourceConfigurationImport() *schema.Resource {
+	return &schema.Resource{
+		Create: resourceConfigurationImportCreate,
+		Read:   resourceConfigurationImportRead,
+		Update: resourceConfigurationImportUpdate,
+		Delete: resourceConfigurationImportDelete,
+
+		Schema: map[string]*schema.Schema{
+			"content": &schema.Schema{
+				Type:         schema.TypeString,
+				Required:     true,
+				ValidateFunc: validation.StringIsNotWhiteSpace,
+				Description:  "Export file content, kept in state so rule and format changes can re-import it",
+			},
+			"format": &schema.Schema{
+				Type:         schema.TypeString,
+				Required:     true,
+				ValidateFunc: validation.StringInSlice(CONVERT_FORMATS, false),
+				Description:  "Content format, one of: xml, json, yaml",
+			},
+			"rule": &schema.Schema{
+				Type:        schema.TypeSet,
+				Required:    true,
+				Description: "Import rules, per object type",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"object": &schema.Schema{
+							Type:         schema.TypeString,
+							Required:     true,
+							ValidateFunc: validation.StringInSlice(CONFIGURATION_RULES_ARR, false),
+							Description:  "Object type",
+						},
+						"create_missing": &schema.Schema{
+							Type:     schema.TypeBool,
+							Optional: true,
+							Default:  false,
+						},
+						"update_existing": &schema.Schema{
+							Type:     schema.TypeBool,
+							Optional: true,
+							Default:  false,
+						},
+						"delete_missing": &schema.Schema{
+							Type:     schema.TypeBool,
+							Optional: true,
+							Default:  false,
+						},
+					},
+				},
+			},
+			"delete_templates": &schema.Schema{
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+				Description: "Delete templates created by this import on destroy",
+			},
+			"templateids": &schema.Schema{
+				Type:        schema.TypeList,
+				Computed:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "IDs of the templates contained in the content",
+			},
+			"created_templateids": &schema.Schema{
+				Type:        schema.TypeList,
+				Computed:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "IDs of the templates created by this import",
+			},
+			"export_hash": &schema.Schema{
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "Hash of the templates export taken after import, used to detect drift",
+			},
+			"drift": &schema.Schema{
+				Type:        schema.TypeBool,
+				Computed:    true,
+				Description: "Imported templates changed since the last import",
+			},
+		},
+	}
+}
+
+// export options, terraform attribute to api option
+var CONFIGURATION_EXPORT_OPTIONS = map[string]string{
+	"templateids":      "templates",
+	"hostids":          "hosts",
+	"hostgroupids":     "groups",
+	"templategroupids": "template_groups",
+	"mapids":           "maps",
+	"mediatypeids":     "mediaTypes",
+}
+
+// dataConfigurationExport terraform configuration export data source entrypoint
+func dataConfigurationExport() *schema.Resource {
+	ids := func(description string) *schema.Schema {
+		return &schema.Schema{
+			Type:        schema.TypeSet,
+			Optional:    true,
+			Elem:        &schema.Schema{Type: schema.TypeString},
+			Description: description,
+		}
+	}
+
+	return &schema.Resource{
+		Read: dataConfigurationExportRead,
+
+		Schema: map[string]*schema.Schema{
+			"format": &schema.Schema{
+				Type:         schema.TypeString,
+				Optional:     true,
+				Default:      "yaml",
+				ValidateFunc: validation.StringInSlice(CONVERT_FORMATS, false),
+				Description:  "Export format, one of: xml, json, yaml",
+			},
+			"templateids":      ids("Template IDs to export"),
+			"hostids":          ids("Host IDs to export"),
+			"hostgroupids":     ids("Host group IDs to export"),
+			"templategroupids": ids("Template group IDs to export (zabbix 6.2+)"),
+			"mapids":           ids("Map IDs to export"),
+			"mediatypeids":     ids("Media type IDs to export"),
+			"export": &schema.Schema{
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "Exported configuration",
+			},
+		},
+	}
+}
+
+// dataConfigurationExportRead read handler for data resource
+func dataConfigurationExportRead(d *schema.ResourceData, m interface{}) error {
+	api := metaAPI(m)
+
+	options := map[string]interface{}{}
+	for attr, option := range CONFIGURATION_EXPORT_OPTIONS {
+		ids := d.Get(attr).(*schema.Set).List()
+		if len(ids) < 1 {
+			continue
+		}
+		// host and template groups were split in 6.2
+		if attr == "hostgroupids" && api.Config.Version >= 60200 {
+			option = "host_groups"
+		}
+		if attr == "templategroupids" && api.Config.Version < 60200 {
+			return fmt.Errorf("templategroupids requires zabbix 6.2 or later")
+		}
+		options[option] = ids
+	}
+	if len(options) < 1 {
+		return fmt.Errorf("at least one of %s is required", strings.Join(configurationExportAttrs(), ", "))
+	}
+
+	export, err := configurationExport(api, d.Get("format").(string), options)
+	if err != nil {
+		return err
+	}
+	log.Debug("exported configuration, %d bytes", len(export))
+
+	d.SetId(configurationHash(export))
+	d.Set("export", export)
+
+	return nil
+}
+
+// configurationExportAttrs sorted export id attributes
+func configurationExportAttrs() []string {
+	attrs := []string{}
+	for attr := range CONFIGURATION_EXPORT_OPTIONS {
+		attrs = append(attrs, attr)
+	}
+	sort.Strings(attrs)
+	return attrs
+}
+
+// configurationHash hex sha256 of import / export content
+func configurationHash(v interface{}) string {
+	sum := sha256.Sum256([]byte(v.(string)))
+	return hex.EncodeToString(sum[:])
+}
+
+// configurationRules build import rules from terraform inputs, only enabled
+// options are sent as the supported options differ per object and version
+func configurationRules(d *schema.ResourceData) map[string]interface{} {
+	rules := map[string]interface{}{}
+
+	for _, v := range d.Get("rule").(*schema.Set).List() {
+		current := v.(map[string]interface{})
+		rule := map[string]interface{}{}
+		for attr, key := range map[string]string{
+			"create_missing":  "createMissing",
+			"update_existing": "updateExisting",
+			"delete_missing":  "deleteMissing",
+		} {
+			if current[attr].(bool) {
+				rule[key] = true
+			}
+		}
+		if len(rule) > 0 {
+			rules[CONFIGURATION_RULES[current["object"].(string)]] = rule
+		}
+	}
+
+	return rules
+}
+
+// configurationCorrectsDrift can a re-import revert changes to the templates,
+// only when existing template objects are updated
+func configurationCorrectsDrift(d *schema.ResourceData) bool {
+	for _, v := range d.Get("rule").(*schema.Set).List() {
+		current := v.(map[string]interface{})
+		if !current["update_existing"].(bool) {
+			continue
+		}
+		for _, object := range CONFIGURATION_DRIFT_RULES {
+			if current["object"].(string) == object {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// configurationTemplates template ids by host name
+func configurationTemplates(api *zabbix.API, params zabbix.Params) (map[string]string, error) {
+	params["output"] = []string{"templateid", "host"}
+
+	templates, err := api.TemplatesGet(params)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := map[string]string{}
+	for _, t := range templates {
+		ids[t.Host] = t.TemplateID
+	}
+	return ids, nil
+}
+
+// configurationExport run configuration.export
+func configurationExport(api *zabbix.API, format string, options map[string]interface{}) (export string, err error) {
+	err = api.CallWithErrorParse("configuration.export", zabbix.Params{
+		"format":  format,
+		"options": options,
+	}, &export)
+	return
+}
+
+// configurationExportHash export the given templates, hashing the result
+// minus the export date
+func configurationExportHash(api *zabbix.API, ids []string) (string, error) {
+	export, err := configurationExport(api, "json", map[string]interface{}{
+		"templates": ids,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return configurationExportNormalise(export)
+}
+
+// configurationExportNormalise hash a json export, ignoring its date
+func configurationExportNormalise(export string) (string, error) {
+	var tree map[string]interface{}
+	if err := json.Unmarshal([]byte(export), &tree); err != nil {
+		return "", err
+	}
+	if root, ok := tree["zabbix_export"].(map[string]interface{}); ok {
+		delete(root, "date")
+	}
+
+	// map keys are marshalled sorted, so this is stable
+	normalised, err := json.Marshal(tree)
+	if err != nil {
+		return "", err
+	}
+	return configurationHash(string(normalised)), nil
+}
+
+// configurationImport run configuration.import, recording the templates involved
+func configurationImport(d *schema.ResourceData, m interface{}) error {
+	api := metaAPI(m)
+
+	content := d.Get("content").(string)
+	format := d.Get("format").(string)
+
+	export, err := convertParse([]byte(content), format)
+	if err != nil {
+		return fmt.Errorf("failed to parse content: %s", err)
+	}
+	names := []string{}
+	for _, t := range convertList(export, "templates") {
+		names = append(names, convertStr(t, "template"))
+	}
+
+	existing := map[string]string{}
+	if len(names) > 0 {
+		existing, err = configurationTemplates(api, zabbix.Params{
+			"filter": map[string]interface{}{
+				"host": names,
+			},
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	var res bool
+	err = api.CallWithErrorParse("configuration.import", zabbix.Params{
+		"format": format,
+		"source": content,
+		"rules":  configurationRules(d),
+	}, &res)
+	if err != nil {
+		return err
+	}
+	log.Debug("imported configuration, %d templates", len(names))
+
+	ids := []string{}
+	created := d.Get("created_templateids").([]interface{})
+	if len(names) > 0 {
+		imported, err := configurationTemplates(api, zabbix.Params{
+			"filter": map[string]interface{}{
+				"host": names,
+			},
+		})
+		if err != nil {
+			return err
+		}
+		for name, id := range imported {
+			ids = append(ids, id)
+			if _, ok := existing[name]; !ok {
+				created = append(created, id)
+			}
+		}
+	}
+	sort.Strings(ids)
+
+	d.SetId(configurationHash(content))
+	d.Set("templateids", ids)
+	d.Set("created_templateids", created)
+
+	hash := ""
+	if len(ids) > 0 {
+		if hash, err = configurationExportHash(api, ids); err != nil {
+			return err
+		}
+	}
+	d.Set("export_hash", hash)
+	d.Set("drift", false)
+
+	return nil
+}
+
+// resourceConfigurationImportCreate terraform create handler
+func resourceConfigurationImportCreate(d *schema.ResourceData, m interface{}) error {
+	if err := configurationImport(d, m); err != nil {
+		return err
+	}
+	return resourceConfigurationImportRead(d, m)
+}
+
+// resourceConfigurationImportUpdate terraform update handler, re-imports on any change
+func resourceConfigurationImportUpdate(d *schema.ResourceData, m interface{}) error {
+	if d.HasChange("content") || d.HasChange("format") || d.HasChange("rule") {
+		if err := configurationImport(d, m); err != nil {
+			return err
+		}
+	}
+	return resourceConfigurationImportRead(d, m)
+}
+
+// resourceConfigurationImportRead terraform read handler, detects drift of
+// the imported templates by comparing a fresh export against the one taken
+// after import, only forcing a re-import when the rules can revert it
+func resourceConfigurationImportRead(d *schema.ResourceData, m interface{}) error {
+	api := metaAPI(m)
+
+	ids := []string{}
+	for _, v := range d.Get("templateids").([]interface{}) {
+		ids = append(ids, v.(string))
+	}
+	if len(ids) < 1 {
+		return nil
+	}
+
+	found, err := configurationTemplates(api, zabbix.Params{
+		"templateids": ids,
+	})
+	if err != nil {
+		return err
+	}
+	if len(found) < 1 {
+		log.Debug("imported templates no longer exist")
+		d.SetId("")
+		return nil
+	}
+
+	drift := len(found) != len(ids)
+	if !drift {
+		hash, err := configurationExportHash(api, ids)
+		if err != nil {
+			return err
+		}
+		drift = hash != d.Get("export_hash").(string)
+	}
+
+	d.Set("drift", drift)
+
+	// clear the stored content hash so the next plan re-imports
+	if drift && configurationCorrectsDrift(d) {
+		log.Debug("imported templates %v changed since import", ids)
+		d.Set("content", "")
+	} else if drift {
+		log.Warn("imported templates %v changed since import, rules do not update existing objects so it is not re-imported", ids)
+	}
+
+	return nil
+}
+
+// resourceConfigurationImportDelete terraform delete handler
+func resourceConfigurationImportDelete(d *schema.ResourceData, m interface{}) error {
+	api := metaAPI(m)
+
+	if !d.Get("delete_templates").(bool) {
+		return nil
+	}
+
+	created := []string{}
+	for _, v := range d.Get("created_templateids").([]interface{}) {
+		created = append(created, v.(string))
+	}
+	if len(created) < 1 {
+		return nil
+	}
+
+	// skip those already removed outside of terraform
+	found, err := configurationTemplates(api, zabbix.Params{
+		"templateids": created,
+	})
+	if err != nil {
+		return err
+	}
+	ids := []string{}
+	for _, id := range found {
+		ids = append(ids, id)
+	}
+	if len(ids) < 1 {
+		return nil
+	}
+
+	return api.TemplatesDeleteByIds(ids)
+}
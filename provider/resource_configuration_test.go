@@ -0,0 +1,209 @@
+package provider
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/terraform"
+	"github.com/tpretz/go-zabbix-api"
+)
+
+const configurationTestContent = `zabbix_export:
+  version: '6.0'
+  templates:
+    - template: 'Template Vendor'
+      name: 'Template Vendor'
+`
+
+// configurationTestExport json export as returned by configuration.export, quoted as a result
+func configurationTestExport(t *testing.T, date, name string) string {
+	export, err := json.Marshal(map[string]interface{}{
+		"zabbix_export": map[string]interface{}{
+			"version":   "6.0",
+			"date":      date,
+			"templates": []interface{}{map[string]interface{}{"template": "Template Vendor", "name": name}},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	quoted, _ := json.Marshal(string(export))
+	return string(quoted)
+}
+
+func TestConfigurationRules(t *testing.T) {
+	d := resourceConfigurationImport().Data(nil)
+	d.Set("rule", []interface{}{
+		map[string]interface{}{"object": "templates", "create_missing": true, "update_existing": true, "delete_missing": false},
+		map[string]interface{}{"object": "discovery_rules", "create_missing": true, "update_existing": false, "delete_missing": true},
+		map[string]interface{}{"object": "value_maps", "create_missing": false, "update_existing": false, "delete_missing": false},
+	})
+
+	rules := configurationRules(d)
+	expected := map[string]interface{}{
+		"templates":      map[string]interface{}{"createMissing": true, "updateExisting": true},
+		"discoveryRules": map[string]interface{}{"createMissing": true, "deleteMissing": true},
+	}
+	got, _ := json.Marshal(rules)
+	want, _ := json.Marshal(expected)
+	if string(got) != string(want) {
+		t.Errorf("expected rules %s, got %s", want, got)
+	}
+}
+
+func TestConfigurationImport(t *testing.T) {
+	results := map[string]string{
+		"apiinfo.version":      `"6.0.0"`,
+		"template.get":         `[{"templateid":"10001","host":"Template Vendor"}]`,
+		"configuration.import": `true`,
+		"configuration.export": configurationTestExport(t, "2024-01-01T00:00:00Z", "Template Vendor"),
+	}
+	server := exportTestServer(t, results)
+	defer server.Close()
+
+	api, err := zabbix.NewAPI(zabbix.Config{Url: server.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	d := resourceConfigurationImport().Data(nil)
+	d.Set("content", configurationTestContent)
+	d.Set("format", "yaml")
+	d.Set("rule", []interface{}{
+		map[string]interface{}{"object": "templates", "create_missing": true},
+	})
+
+	if err := resourceConfigurationImportCreate(d, api); err != nil {
+		t.Fatal(err)
+	}
+	if d.Id() != configurationHash(configurationTestContent) {
+		t.Errorf("expected id to be the content hash, got %q", d.Id())
+	}
+	if ids := d.Get("templateids").([]interface{}); len(ids) != 1 || ids[0] != "10001" {
+		t.Errorf("unexpected templateids %v", ids)
+	}
+	// template existed prior to import
+	if created := d.Get("created_templateids").([]interface{}); len(created) != 0 {
+		t.Errorf("unexpected created_templateids %v", created)
+	}
+
+	// a new export date alone is not drift
+	results["configuration.export"] = configurationTestExport(t, "2024-02-01T00:00:00Z", "Template Vendor")
+	if err := resourceConfigurationImportRead(d, api); err != nil {
+		t.Fatal(err)
+	}
+	if d.Get("content").(string) == "" {
+		t.Error("expected no drift from a changed export date")
+	}
+
+	// drift is reported, but not re-imported when the rules would not revert it
+	results["configuration.export"] = configurationTestExport(t, "2024-02-01T00:00:00Z", "Template Vendor (modified)")
+	if err := resourceConfigurationImportRead(d, api); err != nil {
+		t.Fatal(err)
+	}
+	if d.Get("content").(string) == "" || !d.Get("drift").(bool) {
+		t.Error("expected drift without clearing content")
+	}
+
+	d.Set("rule", []interface{}{
+		map[string]interface{}{"object": "templates", "create_missing": true, "update_existing": true},
+	})
+	if err := resourceConfigurationImportRead(d, api); err != nil {
+		t.Fatal(err)
+	}
+	if d.Get("content").(string) != "" || !d.Get("drift").(bool) {
+		t.Error("expected modified template to clear content")
+	}
+
+	results["template.get"] = `[]`
+	if err := resourceConfigurationImportRead(d, api); err != nil {
+		t.Fatal(err)
+	}
+	if d.Id() != "" {
+		t.Error("expected removed templates to clear the id")
+	}
+}
+
+func TestConfigurationImportRuleUpdate(t *testing.T) {
+	requests := []exportTestRequest{}
+	server := exportTestRecorder(t, map[string]string{
+		"apiinfo.version":      `"6.0.0"`,
+		"template.get":         `[{"templateid":"10001","host":"Template Vendor"}]`,
+		"configuration.import": `true`,
+		"configuration.export": configurationTestExport(t, "2024-01-01T00:00:00Z", "Template Vendor"),
+	}, &requests)
+	defer server.Close()
+
+	api, err := zabbix.NewAPI(zabbix.Config{Url: server.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r := resourceConfigurationImport()
+	config := func(updateExisting bool) *terraform.ResourceConfig {
+		return terraform.NewResourceConfigRaw(map[string]interface{}{
+			"content": configurationTestContent,
+			"format":  "yaml",
+			"rule": []interface{}{
+				map[string]interface{}{"object": "templates", "create_missing": true, "update_existing": updateExisting},
+			},
+		})
+	}
+
+	state := &terraform.InstanceState{}
+	for i, c := range []*terraform.ResourceConfig{config(false), config(true)} {
+		diff, err := r.Diff(state, c, api)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if state, err = r.Apply(state, diff, api); err != nil {
+			t.Fatalf("apply %d: %s", i, err)
+		}
+	}
+
+	// only the rule changed on the second apply, the raw content is imported again
+	sources := []string{}
+	for _, req := range requests {
+		if req.Method == "configuration.import" {
+			var params struct {
+				Source string `json:"source"`
+			}
+			json.Unmarshal(req.Params, &params)
+			sources = append(sources, params.Source)
+		}
+	}
+	if len(sources) != 2 || sources[1] != configurationTestContent {
+		t.Errorf("expected the content to be imported twice, got %q", sources)
+	}
+}
+
+func TestDataConfigurationExport(t *testing.T) {
+	server := exportTestServer(t, map[string]string{
+		"apiinfo.version":      `"6.0.0"`,
+		"configuration.export": `"zabbix_export:\n  version: '6.0'\n"`,
+	})
+	defer server.Close()
+
+	api, err := zabbix.NewAPI(zabbix.Config{Url: server.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	d := dataConfigurationExport().Data(nil)
+	if err := dataConfigurationExportRead(d, api); err == nil {
+		t.Error("expected error without any ids")
+	}
+
+	d.Set("templateids", []interface{}{"10001"})
+	if err := dataConfigurationExportRead(d, api); err != nil {
+		t.Fatal(err)
+	}
+	if export := d.Get("export").(string); export != "zabbix_export:\n  version: '6.0'\n" {
+		t.Errorf("unexpected export %q", export)
+	}
+
+	d.Set("templategroupids", []interface{}{"1"})
+	if err := dataConfigurationExportRead(d, api); err == nil {
+		t.Error("expected error for template groups prior to 6.2")
+	}
+}
@@ -0,0 +1,459 @@
+package provider
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/validation"
+	"github.com/tpretz/go-zabbix-api"
+)
+
+// eval type, same values as lld filters
+var CORRELATION_EVALTYPE = map[string]string{
+	"andor":  "0",
+	"and":    "1",
+	"or":     "2",
+	"custom": "3",
+}
+var CORRELATION_EVALTYPE_REV = map[string]string{}
+var CORRELATION_EVALTYPE_ARR = []string{}
+
+// generate the above structures
+var _ = func() bool {
+	for k, v := range CORRELATION_EVALTYPE {
+		CORRELATION_EVALTYPE_REV[v] = k
+		CORRELATION_EVALTYPE_ARR = append(CORRELATION_EVALTYPE_ARR, k)
+	}
+	return false
+}()
+
+// condition type
+var CORRELATION_CONDITION = map[string]string{
+	"old_event_tag":       "0",
+	"new_event_tag":       "1",
+	"new_event_hostgroup": "2",
+	"event_tag_pair":      "3",
+	"old_event_tag_value": "4",
+	"new_event_tag_value": "5",
+}
+var CORRELATION_CONDITION_REV = map[string]string{}
+var CORRELATION_CONDITION_ARR = []string{}
+
+// generate the above structures
+var _ = func() bool {
+	for k, v := range CORRELATION_CONDITION {
+		CORRELATION_CONDITION_REV[v] = k
+		CORRELATION_CONDITION_ARR = append(CORRELATION_CONDITION_ARR, k)
+	}
+	return false
+}()
+
+// operator, host group conditions only support equal and notequal
+var CORRELATION_OPERATOR = map[string]string{
+	"equal":       "0",
+	"notequal":    "1",
+	"contains":    "2",
+	"notcontains": "3",
+}
+var CORRELATION_OPERATOR_REV = map[string]string{}
+var CORRELATION_OPERATOR_ARR = []string{}
+
+// generate the above structures
+var _ = func() bool {
+	for k, v := range CORRELATION_OPERATOR {
+		CORRELATION_OPERATOR_REV[v] = k
+		CORRELATION_OPERATOR_ARR = append(CORRELATION_OPERATOR_ARR, k)
+	}
+	return false
+}()
+
+// operation type
+var CORRELATION_OPERATION = map[string]string{
+	"close_old_events": "0",
+	"close_new_event":  "1",
+}
+var CORRELATION_OPERATION_REV = map[string]string{}
+var CORRELATION_OPERATION_ARR = []string{}
+
+// generate the above structures
+var _ = func() bool {
+	for k, v := range CORRELATION_OPERATION {
+		CORRELATION_OPERATION_REV[v] = k
+		CORRELATION_OPERATION_ARR = append(CORRELATION_OPERATION_ARR, k)
+	}
+	return false
+}()
+
+// attributes required by each condition type, the operator is only sent for types using it
+var correlationConditionFields = map[string][]string{
+	"old_event_tag":       {"tag"},
+	"new_event_tag":       {"tag"},
+	"new_event_hostgroup": {"groupid"},
+	"event_tag_pair":      {"oldtag", "newtag"},
+	"old_event_tag_value": {"tag"},
+	"new_event_tag_value": {"tag"},
+}
+var correlationConditionOperator = map[string]bool{
+	"new_event_hostgroup": true,
+	"old_event_tag_value": true,
+	"new_event_tag_value": true,
+}
+
+// correlationCondition api correlation filter condition
+type correlationCondition struct {
+	Type      string `json:"type"`
+	Tag       string `json:"tag,omitempty"`
+	OldTag    string `json:"oldtag,omitempty"`
+	NewTag    string `json:"newtag,omitempty"`
+	GroupID   string `json:"groupid,omitempty"`
+	Operator  string `json:"operator,omitempty"`
+	Value     string `json:"value,omitempty"`
+	FormulaID string `json:"formulaid,omitempty"`
+}
+
+// correlationFilter api correlation filter
+type correlationFilter struct {
+	EvalType   string                 `json:"evaltype"`
+	Formula    string                 `json:"formula,omitempty"`
+	Conditions []correlationCondition `json:"conditions"`
+}
+
+// correlationOperation api correlation operation
+type correlationOperation struct {
+	Type string `json:"type"`
+}
+
+// correlation api correlation, not handled by the api library
+type correlation struct {
+	CorrelationID string                 `json:"correlationid,omitempty"`
+	Name          string                 `json:"name"`
+	Description   string                 `json:"description"`
+	Status        string                 `json:"status"`
+	Filter        correlationFilter      `json:"filter"`
+	Operations    []correlationOperation `json:"operations"`
+}
+
+type correlations []correlation
+
+// resourceCorrelation terraform resource handler
+func resourceCorrelation() *schema.Resource {
+	return &schema.Resource{
+		Create: resourceCorrelationCreate,
+		Read:   resourceCorrelationRead,
+		Update: resourceCorrelationUpdate,
+		Delete: resourceCorrelationDelete,
+		Importer: &schema.ResourceImporter{
+			State: correlationImportState(),
+		},
+		CustomizeDiff: correlationCustomizeDiff,
+
+		Schema: map[string]*schema.Schema{
+			"name": &schema.Schema{
+				Type:         schema.TypeString,
+				Required:     true,
+				ValidateFunc: validation.StringIsNotWhiteSpace,
+				Description:  "Correlation name",
+			},
+			"description": &schema.Schema{
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "Correlation description",
+			},
+			"enabled": &schema.Schema{
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     true,
+				Description: "Enable this correlation",
+			},
+			"evaltype": &schema.Schema{
+				Type:         schema.TypeString,
+				Optional:     true,
+				Default:      "andor",
+				ValidateFunc: validation.StringInSlice(CORRELATION_EVALTYPE_ARR, false),
+				Description:  "EvalType, one of: " + strings.Join(CORRELATION_EVALTYPE_ARR, ", "),
+			},
+			"formula": &schema.Schema{
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "Custom formula referencing condition formulaids, required with evaltype custom",
+			},
+			"condition": &schema.Schema{
+				Type:     schema.TypeList,
+				Required: true,
+				MinItems: 1,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"type": &schema.Schema{
+							Type:         schema.TypeString,
+							Required:     true,
+							ValidateFunc: validation.StringInSlice(CORRELATION_CONDITION_ARR, false),
+							Description:  "Condition type, one of: " + strings.Join(CORRELATION_CONDITION_ARR, ", "),
+						},
+						"tag": &schema.Schema{
+							Type:        schema.TypeString,
+							Optional:    true,
+							Description: "Event tag, for old/new event tag and tag value conditions",
+						},
+						"oldtag": &schema.Schema{
+							Type:        schema.TypeString,
+							Optional:    true,
+							Description: "Old event tag, for event tag pair conditions",
+						},
+						"newtag": &schema.Schema{
+							Type:        schema.TypeString,
+							Optional:    true,
+							Description: "New event tag, for event tag pair conditions",
+						},
+						"groupid": &schema.Schema{
+							Type:         schema.TypeString,
+							Optional:     true,
+							ValidateFunc: validation.StringMatch(regexp.MustCompile("^[0-9]+$"), "must be numeric"),
+							Description:  "Host group ID, for new event host group conditions",
+						},
+						"operator": &schema.Schema{
+							Type:         schema.TypeString,
+							Optional:     true,
+							Default:      "equal",
+							ValidateFunc: validation.StringInSlice(CORRELATION_OPERATOR_ARR, false),
+							Description:  "Operator, for host group and tag value conditions, one of: " + strings.Join(CORRELATION_OPERATOR_ARR, ", "),
+						},
+						"value": &schema.Schema{
+							Type:        schema.TypeString,
+							Optional:    true,
+							Description: "Event tag value, for tag value conditions",
+						},
+						"formulaid": &schema.Schema{
+							Type:         schema.TypeString,
+							Optional:     true,
+							Computed:     true,
+							ValidateFunc: validation.StringMatch(regexp.MustCompile("^[A-Z]+$"), "must be upper case letters"),
+							Description:  "Condition ID referenced by a custom formula, assigned by zabbix otherwise",
+						},
+					},
+				},
+			},
+			"operations": &schema.Schema{
+				Type:     schema.TypeSet,
+				Required: true,
+				MinItems: 1,
+				Elem: &schema.Schema{
+					Type:         schema.TypeString,
+					ValidateFunc: validation.StringInSlice(CORRELATION_OPERATION_ARR, false),
+				},
+				Description: "Operations, any of: " + strings.Join(CORRELATION_OPERATION_ARR, ", "),
+			},
+		},
+	}
+}
+
+// buildCorrelationObject create correlation struct
+func buildCorrelationObject(d *schema.ResourceData) correlation {
+	item := correlation{
+		Name:        d.Get("name").(string),
+		Description: d.Get("description").(string),
+		Status:      "0",
+		Filter: correlationFilter{
+			EvalType:   CORRELATION_EVALTYPE[d.Get("evaltype").(string)],
+			Conditions: []correlationCondition{},
+		},
+		Operations: []correlationOperation{},
+	}
+	if !d.Get("enabled").(bool) {
+		item.Status = "1"
+	}
+	if d.Get("evaltype").(string) == "custom" {
+		item.Filter.Formula = d.Get("formula").(string)
+	}
+
+	for _, v := range d.Get("condition").([]interface{}) {
+		current := v.(map[string]interface{})
+		conditionType := current["type"].(string)
+
+		condition := correlationCondition{
+			Type:      CORRELATION_CONDITION[conditionType],
+			Tag:       current["tag"].(string),
+			OldTag:    current["oldtag"].(string),
+			NewTag:    current["newtag"].(string),
+			GroupID:   current["groupid"].(string),
+			Value:     current["value"].(string),
+			FormulaID: current["formulaid"].(string),
+		}
+		if correlationConditionOperator[conditionType] {
+			condition.Operator = CORRELATION_OPERATOR[current["operator"].(string)]
+		}
+		item.Filter.Conditions = append(item.Filter.Conditions, condition)
+	}
+
+	for _, v := range d.Get("operations").(*schema.Set).List() {
+		item.Operations = append(item.Operations, correlationOperation{Type: CORRELATION_OPERATION[v.(string)]})
+	}
+
+	return item
+}
+
+// resourceCorrelationCreate terraform create handler
+func resourceCorrelationCreate(d *schema.ResourceData, m interface{}) error {
+	api := metaAPI(m)
+
+	items := correlations{buildCorrelationObject(d)}
+
+	err := correlationsCreate(api, items)
+
+	if err != nil {
+		return err
+	}
+
+	log.Trace("created correlation: %+v", items[0])
+
+	d.SetId(items[0].CorrelationID)
+
+	return resourceCorrelationRead(d, m)
+}
+
+// resourceCorrelationRead terraform read handler
+func resourceCorrelationRead(d *schema.ResourceData, m interface{}) error {
+	api := metaAPI(m)
+
+	log.Debug("Lookup of correlation with id %s", d.Id())
+
+	items, err := correlationsGet(api, zabbix.Params{
+		"correlationids":   d.Id(),
+		"selectFilter":     "extend",
+		"selectOperations": "extend",
+	})
+
+	if err != nil {
+		return err
+	}
+
+	if len(items) < 1 {
+		d.SetId("")
+		return nil
+	}
+	if len(items) > 1 {
+		return errors.New("multiple correlations found")
+	}
+	item := items[0]
+
+	log.Debug("Got correlation: %+v", item)
+
+	d.Set("name", item.Name)
+	d.Set("description", item.Description)
+	d.Set("enabled", item.Status == "0")
+	d.Set("evaltype", CORRELATION_EVALTYPE_REV[item.Filter.EvalType])
+	// generated for other eval types
+	if item.Filter.EvalType == CORRELATION_EVALTYPE["custom"] {
+		d.Set("formula", item.Filter.Formula)
+	} else {
+		d.Set("formula", "")
+	}
+
+	conditions := make([]interface{}, len(item.Filter.Conditions))
+	for i, c := range item.Filter.Conditions {
+		operator := "equal"
+		if v, ok := CORRELATION_OPERATOR_REV[c.Operator]; ok && c.Operator != "" {
+			operator = v
+		}
+		conditions[i] = map[string]interface{}{
+			"type":      CORRELATION_CONDITION_REV[c.Type],
+			"tag":       c.Tag,
+			"oldtag":    c.OldTag,
+			"newtag":    c.NewTag,
+			"groupid":   c.GroupID,
+			"operator":  operator,
+			"value":     c.Value,
+			"formulaid": c.FormulaID,
+		}
+	}
+	d.Set("condition", conditions)
+
+	operations := schema.NewSet(schema.HashString, []interface{}{})
+	for _, o := range item.Operations {
+		operations.Add(CORRELATION_OPERATION_REV[o.Type])
+	}
+	d.Set("operations", operations)
+
+	return nil
+}
+
+// resourceCorrelationUpdate terraform update handler
+func resourceCorrelationUpdate(d *schema.ResourceData, m interface{}) error {
+	api := metaAPI(m)
+
+	item := buildCorrelationObject(d)
+	item.CorrelationID = d.Id()
+
+	err := correlationsUpdate(api, correlations{item})
+
+	if err != nil {
+		return err
+	}
+
+	return resourceCorrelationRead(d, m)
+}
+
+// resourceCorrelationDelete terraform delete handler
+func resourceCorrelationDelete(d *schema.ResourceData, m interface{}) error {
+	api := metaAPI(m)
+	_, err := api.CallWithError("correlation.delete", []string{d.Id()})
+	return err
+}
+
+// correlationsGet wrapper for correlation.get
+func correlationsGet(api *zabbix.API, params zabbix.Params) (res correlations, err error) {
+	if _, present := params["output"]; !present {
+		params["output"] = "extend"
+	}
+	err = api.CallWithErrorParse("correlation.get", params, &res)
+	return
+}
+
+// correlationsCreate wrapper for correlation.create
+func correlationsCreate(api *zabbix.API, items correlations) (err error) {
+	response, err := api.CallWithError("correlation.create", items)
+	if err != nil {
+		return
+	}
+
+	result := response.Result.(map[string]interface{})
+	correlationids := result["correlationids"].([]interface{})
+	for i, id := range correlationids {
+		items[i].CorrelationID = id.(string)
+	}
+	return
+}
+
+// correlationsUpdate wrapper for correlation.update
+func correlationsUpdate(api *zabbix.API, items correlations) (err error) {
+	_, err = api.CallWithError("correlation.update", items)
+	return
+}
+
+// correlationCustomizeDiff conditions must set the attributes of their type,
+// custom formulas are checked as for lld filters
+func correlationCustomizeDiff(d *schema.ResourceDiff, m interface{}) error {
+	for i, v := range d.Get("condition").([]interface{}) {
+		current := v.(map[string]interface{})
+		conditionType := current["type"].(string)
+		p := fmt.Sprintf("condition.%d.", i)
+
+		for _, k := range correlationConditionFields[conditionType] {
+			if d.NewValueKnown(p+k) && current[k].(string) == "" {
+				return fmt.Errorf("condition %d: %s requires %s", i, conditionType, k)
+			}
+		}
+
+		operator := current["operator"].(string)
+		switch {
+		case !correlationConditionOperator[conditionType] && operator != "equal":
+			return fmt.Errorf("condition %d: %s does not take an operator", i, conditionType)
+		case conditionType == "new_event_hostgroup" && operator != "equal" && operator != "notequal":
+			return fmt.Errorf("condition %d: %s only supports equal and notequal", i, conditionType)
+		}
+	}
+
+	return lldValidateFilter(d, "")
+}
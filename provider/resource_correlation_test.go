@@ -0,0 +1,109 @@
+package provider
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/terraform"
+	"github.com/tpretz/go-zabbix-api"
+)
+
+func TestBuildCorrelationObject(t *testing.T) {
+	d := resourceCorrelation().Data(nil)
+	d.Set("name", "flapping")
+	d.Set("enabled", false)
+	d.Set("evaltype", "custom")
+	d.Set("formula", "A and B")
+	d.Set("condition", []interface{}{
+		map[string]interface{}{"type": "event_tag_pair", "oldtag": "service", "newtag": "service", "operator": "equal", "formulaid": "A"},
+		map[string]interface{}{"type": "new_event_tag_value", "tag": "scope", "value": "avail", "operator": "contains", "formulaid": "B"},
+	})
+	d.Set("operations", []interface{}{"close_old_events"})
+
+	b, err := json.Marshal(buildCorrelationObject(d))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := `{"name":"flapping","description":"","status":"1","filter":{"evaltype":"3","formula":"A and B","conditions":[` +
+		`{"type":"3","oldtag":"service","newtag":"service","formulaid":"A"},` +
+		`{"type":"5","tag":"scope","operator":"2","value":"avail","formulaid":"B"}]},"operations":[{"type":"0"}]}`
+	if string(b) != expected {
+		t.Errorf("expected %s, got %s", expected, b)
+	}
+
+	// formula only sent with custom eval types
+	d.Set("evaltype", "and")
+	if b, _ := json.Marshal(buildCorrelationObject(d)); strings.Contains(string(b), "formula\"") {
+		t.Errorf("unexpected formula in %s", b)
+	}
+}
+
+func TestCorrelationCustomizeDiff(t *testing.T) {
+	condition := func(c map[string]interface{}) []interface{} {
+		return []interface{}{c}
+	}
+
+	cases := []struct {
+		config map[string]interface{}
+		err    string
+	}{
+		{map[string]interface{}{"condition": condition(map[string]interface{}{"type": "old_event_tag", "tag": "service"})}, ""},
+		{map[string]interface{}{"condition": condition(map[string]interface{}{"type": "new_event_hostgroup", "groupid": "2", "operator": "notequal"})}, ""},
+		{map[string]interface{}{"condition": condition(map[string]interface{}{"type": "old_event_tag"})}, "old_event_tag requires tag"},
+		{map[string]interface{}{"condition": condition(map[string]interface{}{"type": "event_tag_pair", "oldtag": "a"})}, "event_tag_pair requires newtag"},
+		{map[string]interface{}{"condition": condition(map[string]interface{}{"type": "new_event_tag", "tag": "a", "operator": "contains"})}, "new_event_tag does not take an operator"},
+		{map[string]interface{}{"condition": condition(map[string]interface{}{"type": "new_event_hostgroup", "groupid": "2", "operator": "contains"})}, "only supports equal and notequal"},
+		{map[string]interface{}{"evaltype": "custom", "formula": "A or B", "condition": condition(map[string]interface{}{"type": "old_event_tag", "tag": "a", "formulaid": "A"})}, "formula references undefined condition B"},
+		{map[string]interface{}{"evaltype": "custom", "formula": "A", "condition": condition(map[string]interface{}{"type": "old_event_tag", "tag": "a"})}, "formulaid is required with evaltype custom"},
+	}
+
+	for i, tc := range cases {
+		tc.config["name"] = "correlation"
+		tc.config["operations"] = []interface{}{"close_old_events"}
+
+		_, err := resourceCorrelation().Diff(nil, terraform.NewResourceConfigRaw(tc.config), nil)
+		switch {
+		case tc.err == "" && err != nil:
+			t.Errorf("case %d: unexpected error %s", i, err)
+		case tc.err != "" && err == nil:
+			t.Errorf("case %d: expected error %q", i, tc.err)
+		case tc.err != "" && !strings.Contains(err.Error(), tc.err):
+			t.Errorf("case %d: expected error %q, got %q", i, tc.err, err)
+		}
+	}
+}
+
+func TestCorrelationRead(t *testing.T) {
+	server := exportTestServer(t, map[string]string{
+		"apiinfo.version": `"6.0.0"`,
+		"correlation.get": `[{"correlationid":"7","name":"flapping","description":"close duplicates","status":"0",` +
+			`"filter":{"evaltype":"0","formula":"","eval_formula":"A","conditions":[{"type":"2","groupid":"4","operator":"1","formulaid":"A"},{"type":"0","tag":"service","formulaid":"B"}]},` +
+			`"operations":[{"type":"0"},{"type":"1"}]}]`,
+	})
+	defer server.Close()
+
+	api, err := zabbix.NewAPI(zabbix.Config{Url: server.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	d := resourceCorrelation().Data(nil)
+	d.SetId("7")
+	if err := resourceCorrelationRead(d, api); err != nil {
+		t.Fatal(err)
+	}
+
+	if d.Get("name") != "flapping" || d.Get("enabled") != true || d.Get("evaltype") != "andor" || d.Get("formula") != "" {
+		t.Errorf("unexpected correlation %v", d.State())
+	}
+	if d.Get("condition.0.type") != "new_event_hostgroup" || d.Get("condition.0.operator") != "notequal" || d.Get("condition.0.groupid") != "4" {
+		t.Errorf("unexpected condition %v", d.Get("condition.0"))
+	}
+	if d.Get("condition.1.type") != "old_event_tag" || d.Get("condition.1.operator") != "equal" || d.Get("condition.1.tag") != "service" {
+		t.Errorf("unexpected condition %v", d.Get("condition.1"))
+	}
+	if d.Get("operations.#") != 2 {
+		t.Errorf("expected 2 operations, got %v", d.Get("operations"))
+	}
+}
@@ -23,9 +23,10 @@ func resourceItemDependent() *schema.Resource {
 		Update: itemGetUpdateWrapper(itemDependentModFunc, itemDependentReadFunc),
 		Delete: resourceItemDelete,
 		Importer: &schema.ResourceImporter{
-			State: schema.ImportStatePassthrough,
+			State: itemImportState(false),
 		},
-		Schema: mergeSchemas(itemCommonSchema, schemaDependent),
+		CustomizeDiff: itemCustomizeDiff,
+		Schema:        mergeSchemas(itemCommonSchema, schemaDependent),
 	}
 }
 func resourceProtoItemDependent() *schema.Resource {
@@ -35,9 +36,10 @@ func resourceProtoItemDependent() *schema.Resource {
 		Update: protoItemGetUpdateWrapper(itemDependentModFunc, itemDependentReadFunc),
 		Delete: resourceProtoItemDelete,
 		Importer: &schema.ResourceImporter{
-			State: schema.ImportStatePassthrough,
+			State: itemImportState(true),
 		},
-		Schema: mergeSchemas(itemCommonSchema, itemPrototypeSchema, schemaDependent),
+		CustomizeDiff: itemCustomizeDiff,
+		Schema:        mergeSchemas(itemCommonSchema, itemPrototypeSchema, schemaDependent),
 	}
 }
 func resourceLLDDependent() *schema.Resource {
@@ -47,24 +49,38 @@ func resourceLLDDependent() *schema.Resource {
 		Update: lldGetUpdateWrapper(lldDependentModFunc, lldDependentReadFunc),
 		Delete: resourceLLDDelete,
 		Importer: &schema.ResourceImporter{
-			State: schema.ImportStatePassthrough,
+			State: lldImportState(false),
 		},
-		Schema: mergeSchemas(lldCommonSchema, schemaDependent),
+		CustomizeDiff: lldCustomizeDiff,
+		Schema:        mergeSchemas(lldCommonSchema, schemaDependent),
+	}
+}
+func resourceProtoLLDDependent() *schema.Resource {
+	return &schema.Resource{
+		Create: protoLLDGetCreateWrapper(lldDependentModFunc, lldDependentReadFunc),
+		Read:   protoLLDGetReadWrapper(lldDependentReadFunc),
+		Update: protoLLDGetUpdateWrapper(lldDependentModFunc, lldDependentReadFunc),
+		Delete: resourceProtoLLDDelete,
+		Importer: &schema.ResourceImporter{
+			State: lldImportState(true),
+		},
+		CustomizeDiff: lldCustomizeDiff,
+		Schema:        mergeSchemas(lldCommonSchema, schemaDependent, lldPrototypeSchema),
 	}
 }
 
-func itemDependentModFunc(d *schema.ResourceData, m interface{}, item *zabbix.Item) {
+func itemDependentModFunc(d *schema.ResourceData, m interface{}, item *apiItem) {
 	item.Type = zabbix.Dependent
 	item.MasterItemID = d.Get("master_itemid").(string)
 }
-func lldDependentModFunc(d *schema.ResourceData, m interface{}, item *zabbix.LLDRule) {
+func lldDependentModFunc(d *schema.ResourceData, m interface{}, item *apiLLDRule) {
 	item.Type = zabbix.Dependent
 	item.MasterItemID = d.Get("master_itemid").(string)
 }
 
-func itemDependentReadFunc(d *schema.ResourceData, m interface{}, item *zabbix.Item) {
+func itemDependentReadFunc(d *schema.ResourceData, m interface{}, item *apiItem) {
 	d.Set("master_itemid", item.MasterItemID)
 }
-func lldDependentReadFunc(d *schema.ResourceData, m interface{}, item *zabbix.LLDRule) {
+func lldDependentReadFunc(d *schema.ResourceData, m interface{}, item *apiLLDRule) {
 	d.Set("master_itemid", item.MasterItemID)
 }
@@ -1,6 +1,7 @@
 package provider
 
 import (
+	"github.com/hashicorp/terraform-plugin-sdk/helper/customdiff"
 	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
 	"github.com/tpretz/go-zabbix-api"
 )
@@ -13,10 +14,11 @@ func resourceItemExternal() *schema.Resource {
 		Update: itemGetUpdateWrapper(itemExternalModFunc, itemExternalReadFunc),
 		Delete: resourceItemDelete,
 		Importer: &schema.ResourceImporter{
-			State: schema.ImportStatePassthrough,
+			State: itemImportState(false),
 		},
+		CustomizeDiff: customdiff.All(itemCustomizeDiff, itemTimeoutCustomizeDiff),
 
-		Schema: mergeSchemas(itemCommonSchema, itemDelaySchema, itemInterfaceSchema),
+		Schema: mergeSchemas(itemCommonSchema, itemDelaySchema, itemInterfaceSchema, itemTimeoutSchema),
 	}
 }
 func resourceProtoItemExternal() *schema.Resource {
@@ -26,10 +28,11 @@ func resourceProtoItemExternal() *schema.Resource {
 		Update: protoItemGetUpdateWrapper(itemExternalModFunc, itemExternalReadFunc),
 		Delete: resourceProtoItemDelete,
 		Importer: &schema.ResourceImporter{
-			State: schema.ImportStatePassthrough,
+			State: itemImportState(true),
 		},
+		CustomizeDiff: customdiff.All(itemCustomizeDiff, itemTimeoutCustomizeDiff),
 
-		Schema: mergeSchemas(itemCommonSchema, itemDelaySchema, itemInterfaceSchema, itemPrototypeSchema),
+		Schema: mergeSchemas(itemCommonSchema, itemDelaySchema, itemInterfaceSchema, itemTimeoutSchema, itemPrototypeSchema),
 	}
 }
 func resourceLLDExternal() *schema.Resource {
@@ -39,29 +42,44 @@ func resourceLLDExternal() *schema.Resource {
 		Update: lldGetUpdateWrapper(lldExternalModFunc, lldExternalReadFunc),
 		Delete: resourceLLDDelete,
 		Importer: &schema.ResourceImporter{
-			State: schema.ImportStatePassthrough,
+			State: lldImportState(false),
 		},
+		CustomizeDiff: lldCustomizeDiff,
 
 		Schema: mergeSchemas(lldCommonSchema, itemInterfaceSchema),
 	}
 }
+func resourceProtoLLDExternal() *schema.Resource {
+	return &schema.Resource{
+		Create: protoLLDGetCreateWrapper(lldExternalModFunc, lldExternalReadFunc),
+		Read:   protoLLDGetReadWrapper(lldExternalReadFunc),
+		Update: protoLLDGetUpdateWrapper(lldExternalModFunc, lldExternalReadFunc),
+		Delete: resourceProtoLLDDelete,
+		Importer: &schema.ResourceImporter{
+			State: lldImportState(true),
+		},
+		CustomizeDiff: lldCustomizeDiff,
+
+		Schema: mergeSchemas(lldCommonSchema, itemInterfaceSchema, lldPrototypeSchema),
+	}
+}
 
 // Custom mod handler for item type
-func itemExternalModFunc(d *schema.ResourceData, m interface{}, item *zabbix.Item) {
+func itemExternalModFunc(d *schema.ResourceData, m interface{}, item *apiItem) {
 	item.Type = zabbix.ExternalCheck
 	item.InterfaceID = d.Get("interfaceid").(string)
 	item.Delay = d.Get("delay").(string)
 }
-func lldExternalModFunc(d *schema.ResourceData, m interface{}, item *zabbix.LLDRule) {
+func lldExternalModFunc(d *schema.ResourceData, m interface{}, item *apiLLDRule) {
 	item.Type = zabbix.ExternalCheck
 	item.InterfaceID = d.Get("interfaceid").(string)
 }
 
 // Custom read handler for item type
-func itemExternalReadFunc(d *schema.ResourceData, m interface{}, item *zabbix.Item) {
+func itemExternalReadFunc(d *schema.ResourceData, m interface{}, item *apiItem) {
 	d.Set("interfaceid", item.InterfaceID)
 	d.Set("delay", item.Delay)
 }
-func lldExternalReadFunc(d *schema.ResourceData, m interface{}, item *zabbix.LLDRule) {
+func lldExternalReadFunc(d *schema.ResourceData, m interface{}, item *apiLLDRule) {
 	d.Set("interfaceid", item.InterfaceID)
 }
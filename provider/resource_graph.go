@@ -259,7 +259,7 @@ func resourceGraph() *schema.Resource {
 		Update: resourceGraphUpdate(false),
 		Delete: resourceGraphDelete(false),
 		Importer: &schema.ResourceImporter{
-			State: schema.ImportStatePassthrough,
+			State: graphImportState(false),
 		},
 
 		Schema: schemaGraph,
@@ -272,7 +272,7 @@ func resourceProtoGraph() *schema.Resource {
 		Update: resourceGraphUpdate(true),
 		Delete: resourceGraphDelete(true),
 		Importer: &schema.ResourceImporter{
-			State: schema.ImportStatePassthrough,
+			State: graphImportState(true),
 		},
 
 		Schema: schemaGraph,
@@ -282,7 +282,7 @@ func resourceProtoGraph() *schema.Resource {
 // terraform Graph create function
 func resourceGraphCreate(prototype bool) schema.CreateFunc {
 	return func(d *schema.ResourceData, m interface{}) error {
-		api := m.(*zabbix.API)
+		api := metaAPI(m)
 
 		item := buildGraphObject(d)
 
@@ -387,7 +387,7 @@ func flattenGraphItems(items zabbix.GraphItems) []interface{} {
 // resourceGraphRead terraform resource read handler
 func resourceGraphRead(prototype bool) schema.ReadFunc {
 	return func(d *schema.ResourceData, m interface{}) error {
-		api := m.(*zabbix.API)
+		api := metaAPI(m)
 
 		log.Debug("Lookup of Graph with id %s", d.Id())
 		params := zabbix.Params{
@@ -445,7 +445,7 @@ func resourceGraphRead(prototype bool) schema.ReadFunc {
 // resourceGraphUpdate terraform resource update handler
 func resourceGraphUpdate(prototype bool) schema.UpdateFunc {
 	return func(d *schema.ResourceData, m interface{}) error {
-		api := m.(*zabbix.API)
+		api := metaAPI(m)
 
 		item := buildGraphObject(d)
 
@@ -470,7 +470,7 @@ func resourceGraphUpdate(prototype bool) schema.UpdateFunc {
 // resourceGraphDelete terraform resource delete handler
 func resourceGraphDelete(prototype bool) schema.DeleteFunc {
 	return func(d *schema.ResourceData, m interface{}) error {
-		api := m.(*zabbix.API)
+		api := metaAPI(m)
 
 		if prototype {
 			return api.GraphProtosDeleteByIds([]string{d.Id()})
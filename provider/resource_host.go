@@ -30,15 +30,23 @@ var HINV_LOOKUP_REV = map[zabbix.InventoryMode]string{}
 var HINV_LOOKUP_ARR = []string{}
 
 var HSNMP_AUTHPROTO = map[string]string{
-	"md5": "0",
-	"sha": "1",
+	"md5":    "0",
+	"sha1":   "1",
+	"sha224": "2",
+	"sha256": "3",
+	"sha384": "4",
+	"sha512": "5",
 }
 var HSNMP_AUTHPROTO_REV = map[string]string{}
 var HSNMP_AUTHPROTO_ARR = []string{}
 
 var HSNMP_PRIVPROTO = map[string]string{
-	"des": "0",
-	"aes": "1",
+	"des":     "0",
+	"aes128":  "1",
+	"aes192":  "2",
+	"aes256":  "3",
+	"aes192c": "4",
+	"aes256c": "5",
 }
 var HSNMP_PRIVPROTO_REV = map[string]string{}
 var HSNMP_PRIVPROTO_ARR = []string{}
@@ -173,6 +181,17 @@ var _ = func() bool {
 		HSNMP_SECLEVEL_REV[v] = k
 		HSNMP_SECLEVEL_ARR = append(HSNMP_SECLEVEL_ARR, k)
 	}
+	// legacy names (see SNMP_PROTO_LEGACY) are accepted, but never read back
+	for k, v := range SNMP_PROTO_LEGACY {
+		if id, ok := HSNMP_AUTHPROTO[v]; ok {
+			HSNMP_AUTHPROTO[k] = id
+			HSNMP_AUTHPROTO_ARR = append(HSNMP_AUTHPROTO_ARR, k)
+		}
+		if id, ok := HSNMP_PRIVPROTO[v]; ok {
+			HSNMP_PRIVPROTO[k] = id
+			HSNMP_PRIVPROTO_ARR = append(HSNMP_PRIVPROTO_ARR, k)
+		}
+	}
 	for _, v := range INVENTORY_KEYS {
 		inventorySchema.Elem.(*schema.Resource).Schema[v] = &schema.Schema{
 			Type:        schema.TypeString,
@@ -183,6 +202,121 @@ var _ = func() bool {
 	return false
 }()
 
+// interfaceSchema single host interface, shared with zabbix_host_interface
+var interfaceSchema = map[string]*schema.Schema{
+	"id": &schema.Schema{
+		Type:        schema.TypeString,
+		Computed:    true,
+		Description: "Interface ID (internally generated)",
+	},
+	"dns": &schema.Schema{
+		Type:        schema.TypeString,
+		Optional:    true,
+		Description: "Interface DNS name",
+	},
+	"ip": &schema.Schema{
+		Type:        schema.TypeString,
+		Optional:    true,
+		Description: "Interface IP address",
+	},
+	"main": &schema.Schema{
+		Type:        schema.TypeBool,
+		Optional:    true,
+		Default:     true,
+		Description: "Primary interface of this type",
+	},
+	"port": &schema.Schema{
+		Type:         schema.TypeInt,
+		Optional:     true,
+		Computed:     true,
+		ValidateFunc: validation.IntBetween(0, 65535),
+		Description:  "Destination Port",
+	},
+	"type": &schema.Schema{
+		Type:     schema.TypeString,
+		Optional: true,
+		Default:  "agent",
+		ValidateFunc: validation.StringInSlice([]string{
+			"agent",
+			"snmp",
+			"ipmi",
+			"jmx",
+		}, false),
+		Description: "Interface type",
+	},
+	"snmp_version": &schema.Schema{
+		Type:         schema.TypeString,
+		Optional:     true,
+		Default:      "2",
+		Description:  "SNMP Version, one of: " + strings.Join(HSNMP_LOOKUP_ARR, ", "),
+		ValidateFunc: validation.StringInSlice(HSNMP_LOOKUP_ARR, false),
+	},
+	"snmp_bulk": &schema.Schema{
+		Type:        schema.TypeBool,
+		Optional:    true,
+		Default:     true,
+		Description: "SNMP Bulk",
+	},
+	"snmp_community": &schema.Schema{
+		Type:         schema.TypeString,
+		Optional:     true,
+		Description:  "HSNMP Community (v1/v2 only)",
+		ValidateFunc: validation.StringIsNotWhiteSpace,
+		Default:      "{$SNMP_COMMUNITY}",
+	},
+	"snmp3_authpassphrase": &schema.Schema{
+		Type:         schema.TypeString,
+		Optional:     true,
+		Description:  "Authentication Passphrase (v3 only)",
+		ValidateFunc: validation.StringIsNotWhiteSpace,
+		Default:      "{$SNMP3_AUTHPASSPHRASE}",
+	},
+	"snmp3_authprotocol": &schema.Schema{
+		Type:             schema.TypeString,
+		Optional:         true,
+		Description:      "Authentication Protocol (v3 only), one of: " + strings.Join(HSNMP_AUTHPROTO_ARR, ", "),
+		ValidateFunc:     validation.StringInSlice(HSNMP_AUTHPROTO_ARR, false),
+		DiffSuppressFunc: snmpProtocolDiffSuppress,
+		Default:          "sha1",
+	},
+	"snmp3_contextname": &schema.Schema{
+		Type:         schema.TypeString,
+		Optional:     true,
+		Description:  "Context Name (v3 only)",
+		ValidateFunc: validation.StringIsNotWhiteSpace,
+		Default:      "{$SNMP3_CONTEXTNAME}",
+	},
+	"snmp3_privpassphrase": &schema.Schema{
+		Type:         schema.TypeString,
+		Optional:     true,
+		Description:  "Priv Passphrase (v3 only)",
+		ValidateFunc: validation.StringIsNotWhiteSpace,
+		Default:      "{$SNMP3_PRIVPASSPHRASE}",
+	},
+	"snmp3_privprotocol": &schema.Schema{
+		Type:             schema.TypeString,
+		Optional:         true,
+		Description:      "Priv Protocol (v3 only), one of: " + strings.Join(HSNMP_PRIVPROTO_ARR, ", "),
+		ValidateFunc:     validation.StringInSlice(HSNMP_PRIVPROTO_ARR, false),
+		DiffSuppressFunc: snmpProtocolDiffSuppress,
+		Default:          "aes128",
+	},
+	"snmp3_securitylevel": &schema.Schema{
+		Type:         schema.TypeString,
+		Optional:     true,
+		Description:  "Security Level (v3 only), one of: " + strings.Join(HSNMP_SECLEVEL_ARR, ", "),
+		ValidateFunc: validation.StringInSlice(HSNMP_SECLEVEL_ARR, false),
+		Default:      "authpriv",
+	},
+	"snmp3_securityname": &schema.Schema{
+		Type:         schema.TypeString,
+		Optional:     true,
+		Description:  "Security Name (v3 only)",
+		ValidateFunc: validation.StringIsNotWhiteSpace,
+		Default:      "{$SNMP3_SECURITYNAME}",
+	},
+}
+
 // hostSchemaBase base host schema
 var hostSchemaBase = map[string]*schema.Schema{
 	"name": &schema.Schema{
@@ -219,117 +353,7 @@ var hostSchemaBase = map[string]*schema.Schema{
 		Type:        schema.TypeList,
 		Description: "Host interfaces",
 		Elem: &schema.Resource{
-			Schema: map[string]*schema.Schema{
-				"id": &schema.Schema{
-					Type:        schema.TypeString,
-					Computed:    true,
-					Description: "Interface ID (internally generated)",
-				},
-				"dns": &schema.Schema{
-					Type:        schema.TypeString,
-					Optional:    true,
-					Description: "Interface DNS name",
-				},
-				"ip": &schema.Schema{
-					Type:        schema.TypeString,
-					Optional:    true,
-					Description: "Interface IP address",
-				},
-				"main": &schema.Schema{
-					Type:        schema.TypeBool,
-					Optional:    true,
-					Default:     true,
-					Description: "Primary interface of this type",
-				},
-				"port": &schema.Schema{
-					Type:         schema.TypeInt,
-					Optional:     true,
-					Computed:     true,
-					ValidateFunc: validation.IntBetween(0, 65535),
-					Description:  "Destination Port",
-				},
-				"type": &schema.Schema{
-					Type:     schema.TypeString,
-					Optional: true,
-					Default:  "agent",
-					ValidateFunc: validation.StringInSlice([]string{
-						"agent",
-						"snmp",
-						"ipmi",
-						"jmx",
-					}, false),
-					Description: "Interface type",
-				},
-				"snmp_version": &schema.Schema{
-					Type:         schema.TypeString,
-					Optional:     true,
-					Default:      "2",
-					Description:  "SNMP Version, one of: " + strings.Join(HSNMP_LOOKUP_ARR, ", "),
-					ValidateFunc: validation.StringInSlice(HSNMP_LOOKUP_ARR, false),
-				},
-				"snmp_bulk": &schema.Schema{
-					Type:        schema.TypeBool,
-					Optional:    true,
-					Default:     true,
-					Description: "SNMP Bulk",
-				},
-				"snmp_community": &schema.Schema{
-					Type:         schema.TypeString,
-					Optional:     true,
-					Description:  "HSNMP Community (v1/v2 only)",
-					ValidateFunc: validation.StringIsNotWhiteSpace,
-					Default:      "{$SNMP_COMMUNITY}",
-				},
-				"snmp3_authpassphrase": &schema.Schema{
-					Type:         schema.TypeString,
-					Optional:     true,
-					Description:  "Authentication Passphrase (v3 only)",
-					ValidateFunc: validation.StringIsNotWhiteSpace,
-					Default:      "{$SNMP3_AUTHPASSPHRASE}",
-				},
-				"snmp3_authprotocol": &schema.Schema{
-					Type:         schema.TypeString,
-					Optional:     true,
-					Description:  "Authentication Protocol (v3 only), one of: " + strings.Join(HSNMP_AUTHPROTO_ARR, ", "),
-					ValidateFunc: validation.StringInSlice(HSNMP_AUTHPROTO_ARR, false),
-					Default:      "sha",
-				},
-				"snmp3_contextname": &schema.Schema{
-					Type:         schema.TypeString,
-					Optional:     true,
-					Description:  "Context Name (v3 only)",
-					ValidateFunc: validation.StringIsNotWhiteSpace,
-					Default:      "{$SNMP3_CONTEXTNAME}",
-				},
-				"snmp3_privpassphrase": &schema.Schema{
-					Type:         schema.TypeString,
-					Optional:     true,
-					Description:  "Priv Passphrase (v3 only)",
-					ValidateFunc: validation.StringIsNotWhiteSpace,
-					Default:      "{$SNMP3_PRIVPASSPHRASE}",
-				},
-				"snmp3_privprotocol": &schema.Schema{
-					Type:         schema.TypeString,
-					Optional:     true,
-					Description:  "Priv Protocol (v3 only), one of: " + strings.Join(HSNMP_PRIVPROTO_ARR, ", "),
-					ValidateFunc: validation.StringInSlice(HSNMP_PRIVPROTO_ARR, false),
-					Default:      "aes",
-				},
-				"snmp3_securitylevel": &schema.Schema{
-					Type:         schema.TypeString,
-					Optional:     true,
-					Description:  "Security Level (v3 only), one of: " + strings.Join(HSNMP_SECLEVEL_ARR, ", "),
-					ValidateFunc: validation.StringInSlice(HSNMP_SECLEVEL_ARR, false),
-					Default:      "authpriv",
-				},
-				"snmp3_securityname": &schema.Schema{
-					Type:         schema.TypeString,
-					Optional:     true,
-					Description:  "Security Name (v3 only)",
-					ValidateFunc: validation.StringIsNotWhiteSpace,
-					Default:      "{$SNMP3_SECURITYNAME}",
-				},
-			},
+			Schema: interfaceSchema,
 		},
 	},
 	"groups": &schema.Schema{
@@ -379,7 +403,7 @@ func resourceHost() *schema.Resource {
 		Delete: resourceHostDelete,
 		Schema: hostResourceSchema(hostSchemaBase),
 		Importer: &schema.ResourceImporter{
-			State: schema.ImportStatePassthrough,
+			State: hostImportState(),
 		},
 	}
 }
@@ -411,6 +435,13 @@ func hostResourceSchema(m map[string]*schema.Schema) (o map[string]*schema.Schem
 
 	o["proxyid"].ValidateFunc = validation.StringIsNotWhiteSpace
 	o["proxyid"].Default = "0"
+
+	o["ignore_external_interfaces"] = &schema.Schema{
+		Type:        schema.TypeBool,
+		Optional:    true,
+		Default:     false,
+		Description: "Ignore interfaces not defined in this resource (e.g. managed by zabbix_host_interface)",
+	}
 	return o
 }
 
@@ -443,73 +474,83 @@ func hostDataSchema(m map[string]*schema.Schema) (o map[string]*schema.Schema) {
 
 // hostGenerateInterfaces generate interface object array
 func hostGenerateInterfaces(d *schema.ResourceData, m interface{}) (interfaces zabbix.HostInterfaces, err error) {
-	api := m.(*zabbix.API)
 	interfaceCount := d.Get("interface.#").(int)
 	interfaces = make(zabbix.HostInterfaces, interfaceCount)
 
 	for i := 0; i < interfaceCount; i++ {
 		prefix := fmt.Sprintf("interface.%d.", i)
-		typeId := HOST_IFACE_TYPES[d.Get(prefix+"type").(string)]
-
-		interfaces[i] = zabbix.HostInterface{
-			IP:    d.Get(prefix + "ip").(string),
-			DNS:   d.Get(prefix + "dns").(string),
-			Main:  "0",
-			Type:  typeId,
-			UseIP: "0",
-		}
-		if interfaces[i].IP == "" && interfaces[i].DNS == "" {
-			err = errors.New("interface requires either an IP or DNS entry")
+		interfaces[i], err = buildHostInterface(d, m, prefix)
+		if err != nil {
 			return
 		}
 
-		if interfaces[i].IP != "" {
-			interfaces[i].UseIP = "1"
-		}
-
-		if d.Get(prefix + "main").(bool) {
-			interfaces[i].Main = "1"
-		}
-
-		// if no port set, set the default for the type
-		if v, ok := d.GetOk(prefix + "port"); ok {
-			interfaces[i].Port = strconv.FormatInt(int64(v.(int)), 10)
-		} else {
-			v := HOST_IFACE_PORTS[d.Get(prefix+"type").(string)]
-			d.Set(prefix+"port", v)
-			interfaces[i].Port = strconv.FormatInt(int64(v), 10)
-		}
-
 		// if we have an id (i.e an update)
 		if str := d.Get(prefix + "id").(string); str != "" {
 			interfaces[i].InterfaceID = str
 		}
+	}
 
-		log.Debug("interface config abc: %+v", api.Config)
-		// version 5 and snmp
-		if api.Config.Version >= 50000 && typeId == zabbix.SNMP {
-			details := zabbix.HostInterfaceDetail{}
-			details.Version = d.Get(prefix + "snmp_version").(string)
-			details.Bulk = "0"
-			if d.Get(prefix + "snmp_bulk").(bool) {
-				details.Bulk = "1"
-			}
+	return
+}
+
+// buildHostInterface generate a single interface object, attributes read from prefix
+func buildHostInterface(d *schema.ResourceData, m interface{}, prefix string) (iface zabbix.HostInterface, err error) {
+	api := metaAPI(m)
+	typeId := HOST_IFACE_TYPES[d.Get(prefix+"type").(string)]
+
+	iface = zabbix.HostInterface{
+		IP:    d.Get(prefix + "ip").(string),
+		DNS:   d.Get(prefix + "dns").(string),
+		Main:  "0",
+		Type:  typeId,
+		UseIP: "0",
+	}
+	if iface.IP == "" && iface.DNS == "" {
+		err = errors.New("interface requires either an IP or DNS entry")
+		return
+	}
+
+	if iface.IP != "" {
+		iface.UseIP = "1"
+	}
+
+	if d.Get(prefix + "main").(bool) {
+		iface.Main = "1"
+	}
+
+	// if no port set, set the default for the type
+	if v, ok := d.GetOk(prefix + "port"); ok {
+		iface.Port = strconv.FormatInt(int64(v.(int)), 10)
+	} else {
+		v := HOST_IFACE_PORTS[d.Get(prefix+"type").(string)]
+		d.Set(prefix+"port", v)
+		iface.Port = strconv.FormatInt(int64(v), 10)
+	}
 
-			// only pull relevent params
-			//if details.Version == "3" {
-			details.SecurityName = d.Get(prefix + "snmp3_securityname").(string)
-			details.SecurityLevel = HSNMP_SECLEVEL[d.Get(prefix+"snmp3_securitylevel").(string)]
-			details.AuthPassphrase = d.Get(prefix + "snmp3_authpassphrase").(string)
-			details.PrivPassphrase = d.Get(prefix + "snmp3_privpassphrase").(string)
-			details.AuthProtocol = HSNMP_AUTHPROTO[d.Get(prefix+"snmp3_authprotocol").(string)]
-			details.PrivProtocol = HSNMP_PRIVPROTO[d.Get(prefix+"snmp3_privprotocol").(string)]
-			details.ContextName = d.Get(prefix + "snmp3_contextname").(string)
-			//} else {
-			details.Community = d.Get(prefix + "snmp_community").(string)
-			//}
-			//interfaces[i].Details = zabbix.HostInterfaceDetails{details}
-			interfaces[i].Details = &details
+	log.Debug("interface config abc: %+v", api.Config)
+	// version 5 and snmp
+	if api.Config.Version >= 50000 && typeId == zabbix.SNMP {
+		details := zabbix.HostInterfaceDetail{}
+		details.Version = d.Get(prefix + "snmp_version").(string)
+		details.Bulk = "0"
+		if d.Get(prefix + "snmp_bulk").(bool) {
+			details.Bulk = "1"
 		}
+
+		// only pull relevent params
+		//if details.Version == "3" {
+		details.SecurityName = d.Get(prefix + "snmp3_securityname").(string)
+		details.SecurityLevel = HSNMP_SECLEVEL[d.Get(prefix+"snmp3_securitylevel").(string)]
+		details.AuthPassphrase = d.Get(prefix + "snmp3_authpassphrase").(string)
+		details.PrivPassphrase = d.Get(prefix + "snmp3_privpassphrase").(string)
+		details.AuthProtocol = HSNMP_AUTHPROTO[d.Get(prefix+"snmp3_authprotocol").(string)]
+		details.PrivProtocol = HSNMP_PRIVPROTO[d.Get(prefix+"snmp3_privprotocol").(string)]
+		details.ContextName = d.Get(prefix + "snmp3_contextname").(string)
+		//} else {
+		details.Community = d.Get(prefix + "snmp_community").(string)
+		//}
+		//iface.Details = zabbix.HostInterfaceDetails{details}
+		iface.Details = &details
 	}
 
 	return
@@ -583,7 +624,7 @@ func buildHostObject(d *schema.ResourceData, m interface{}) (*zabbix.Host, error
 
 // resourceHostCreate terraform create handler
 func resourceHostCreate(d *schema.ResourceData, m interface{}) error {
-	api := m.(*zabbix.API)
+	api := metaAPI(m)
 
 	item, err := buildHostObject(d, m)
 
@@ -603,7 +644,7 @@ func resourceHostCreate(d *schema.ResourceData, m interface{}) error {
 
 	d.SetId(items[0].HostID)
 
-	return resourceHostRead(d, m)
+	return hostRead(d, m, hostResourceParams(d), nil)
 }
 
 // dataHostRead read handler for data resource
@@ -630,14 +671,31 @@ func dataHostRead(d *schema.ResourceData, m interface{}) error {
 	}
 	log.Debug("performing data lookup with params: %#v", params)
 
-	return hostRead(d, m, params)
+	return hostRead(d, m, params, nil)
 }
 
 // resourceHostRead read handler for resource
 func resourceHostRead(d *schema.ResourceData, m interface{}) error {
 	log.Debug("Lookup of hostgroup with id %s", d.Id())
 
-	return hostRead(d, m, zabbix.Params{
+	var filter hostInterfaceFilter
+
+	// only keep interfaces we already know about
+	if d.Get("ignore_external_interfaces").(bool) {
+		managed := hostManagedInterfaceIds(d.Get("interface").([]interface{}))
+		if len(managed) > 0 {
+			filter = func(iface zabbix.HostInterface) bool {
+				return managed[iface.InterfaceID]
+			}
+		}
+	}
+
+	return hostRead(d, m, hostResourceParams(d), filter)
+}
+
+// hostResourceParams lookup parameters for a host resource
+func hostResourceParams(d *schema.ResourceData) zabbix.Params {
+	return zabbix.Params{
 		"selectInterfaces":      "extend",
 		"selectParentTemplates": "extend",
 		"selectGroups":          "extend",
@@ -645,12 +703,26 @@ func resourceHostRead(d *schema.ResourceData, m interface{}) error {
 		"selectTags":            "extend",
 		"selectInventory":       "extend",
 		"hostids":               d.Id(),
-	})
+	}
+}
+
+// hostInterfaceFilter returns true for interfaces managed by the host resource
+type hostInterfaceFilter func(zabbix.HostInterface) bool
+
+// hostManagedInterfaceIds collect interface ids from a terraform interface list
+func hostManagedInterfaceIds(list []interface{}) map[string]bool {
+	ids := map[string]bool{}
+	for _, v := range list {
+		if id := v.(map[string]interface{})["id"].(string); id != "" {
+			ids[id] = true
+		}
+	}
+	return ids
 }
 
 // hostRead common host read function
-func hostRead(d *schema.ResourceData, m interface{}, params zabbix.Params) error {
-	api := m.(*zabbix.API)
+func hostRead(d *schema.ResourceData, m interface{}, params zabbix.Params, filter hostInterfaceFilter) error {
+	api := metaAPI(m)
 
 	log.Debug("Lookup of host with params %#v", params)
 
@@ -671,6 +743,16 @@ func hostRead(d *schema.ResourceData, m interface{}, params zabbix.Params) error
 
 	log.Debug("Got host: %+v", host)
 
+	if filter != nil {
+		interfaces := zabbix.HostInterfaces{}
+		for _, iface := range host.Interfaces {
+			if filter(iface) {
+				interfaces = append(interfaces, iface)
+			}
+		}
+		host.Interfaces = interfaces
+	}
+
 	d.SetId(host.HostID)
 	d.Set("name", host.Name)
 	d.Set("host", host.Host)
@@ -702,67 +784,72 @@ func flattenInventory(host zabbix.Host) []interface{} {
 
 // flattenHostInterfaces convert API response into terraform structs
 func flattenHostInterfaces(host zabbix.Host, d *schema.ResourceData, m interface{}) []interface{} {
-	api := m.(*zabbix.API)
-	val := make([]interface{}, len(host.Interfaces))
+	val := []interface{}{}
 	for i := 0; i < len(host.Interfaces); i++ {
-		port, _ := strconv.ParseInt(host.Interfaces[i].Port, 10, 64)
-		params := map[string]interface{}{
-			"id":   host.Interfaces[i].InterfaceID,
-			"ip":   host.Interfaces[i].IP,
-			"dns":  host.Interfaces[i].DNS,
-			"main": host.Interfaces[i].Main == "1",
-			"port": port,
-			"type": HOST_IFACE_TYPES_REV[host.Interfaces[i].Type],
-		}
-
-		// Set defaults, as these may or may not be bounced back
-		arr := []string{
-			"snmp_version",
-			"snmp_community",
-			"snmp3_authpassphrase",
-			"snmp3_authprotocol",
-			"snmp3_contextname",
-			"snmp3_privpassphrase",
-			"snmp3_privprotocol",
-			"snmp3_securitylevel",
-			"snmp3_securityname",
-			"snmp_bulk",
-		}
-
-		for _, v := range arr {
-			params[v] = hostSchemaBase["interface"].Elem.(*schema.Resource).Schema[v].Default
-		}
+		val = append(val, flattenHostInterface(host.Interfaces[i], m))
+	}
+	return val
+}
 
-		// need to handle detail
-		details := host.Interfaces[i].Details
-		log.Debug("got details: %+v", details)
-		if api.Config.Version >= 50000 && params["type"] == "snmp" && details != nil {
-			log.Debug("interface new logic")
-			params["snmp_version"] = details.Version
-			params["snmp_bulk"] = details.Bulk == "1"
-
-			if params["snmp_version"] != "3" {
-				params["snmp_community"] = details.Community
-			} else {
-				params["snmp3_securityname"] = details.SecurityName
-				params["snmp3_securitylevel"] = HSNMP_SECLEVEL_REV[details.SecurityLevel]
-				params["snmp3_authpassphrase"] = details.AuthPassphrase
-				params["snmp3_privpassphrase"] = details.PrivPassphrase
-				params["snmp3_authprotocol"] = HSNMP_AUTHPROTO_REV[details.AuthProtocol]
-				params["snmp3_privprotocol"] = HSNMP_PRIVPROTO_REV[details.PrivProtocol]
-				params["snmp3_contextname"] = details.ContextName
-			}
+// flattenHostInterface convert a single API interface into a terraform struct
+func flattenHostInterface(iface zabbix.HostInterface, m interface{}) map[string]interface{} {
+	api := metaAPI(m)
+	port, _ := strconv.ParseInt(iface.Port, 10, 64)
+	params := map[string]interface{}{
+		"id":   iface.InterfaceID,
+		"ip":   iface.IP,
+		"dns":  iface.DNS,
+		"main": iface.Main == "1",
+		"port": port,
+		"type": HOST_IFACE_TYPES_REV[iface.Type],
+	}
+
+	// Set defaults, as these may or may not be bounced back
+	arr := []string{
+		"snmp_version",
+		"snmp_community",
+		"snmp3_authpassphrase",
+		"snmp3_authprotocol",
+		"snmp3_contextname",
+		"snmp3_privpassphrase",
+		"snmp3_privprotocol",
+		"snmp3_securitylevel",
+		"snmp3_securityname",
+		"snmp_bulk",
+	}
+
+	for _, v := range arr {
+		params[v] = interfaceSchema[v].Default
+	}
+
+	// need to handle detail
+	details := iface.Details
+	log.Debug("got details: %+v", details)
+	if api.Config.Version >= 50000 && params["type"] == "snmp" && details != nil {
+		log.Debug("interface new logic")
+		params["snmp_version"] = details.Version
+		params["snmp_bulk"] = details.Bulk == "1"
+
+		if params["snmp_version"] != "3" {
+			params["snmp_community"] = details.Community
+		} else {
+			params["snmp3_securityname"] = details.SecurityName
+			params["snmp3_securitylevel"] = HSNMP_SECLEVEL_REV[details.SecurityLevel]
+			params["snmp3_authpassphrase"] = details.AuthPassphrase
+			params["snmp3_privpassphrase"] = details.PrivPassphrase
+			params["snmp3_authprotocol"] = HSNMP_AUTHPROTO_REV[details.AuthProtocol]
+			params["snmp3_privprotocol"] = HSNMP_PRIVPROTO_REV[details.PrivProtocol]
+			params["snmp3_contextname"] = details.ContextName
 		}
-
-		log.Debug("Got host interface: %+v", params)
-		val[i] = params
 	}
-	return val
+
+	log.Debug("Got host interface: %+v", params)
+	return params
 }
 
 // resourceHostUpdate terraform update resource handler
 func resourceHostUpdate(d *schema.ResourceData, m interface{}) error {
-	api := m.(*zabbix.API)
+	api := metaAPI(m)
 
 	item, err := buildHostObject(d, m)
 
@@ -783,6 +870,26 @@ func resourceHostUpdate(d *schema.ResourceData, m interface{}) error {
 
 	item.HostID = d.Id()
 
+	var filter hostInterfaceFilter
+
+	// carry over interfaces managed elsewhere, host.update replaces the full list
+	if d.Get("ignore_external_interfaces").(bool) {
+		external, err := hostExternalInterfaces(d, m)
+		if err != nil {
+			return err
+		}
+		item.Interfaces = append(item.Interfaces, external...)
+
+		filter = func(iface zabbix.HostInterface) bool {
+			for _, e := range external {
+				if e.InterfaceID == iface.InterfaceID {
+					return false
+				}
+			}
+			return true
+		}
+	}
+
 	items := []zabbix.Host{*item}
 
 	err = api.HostsUpdate(items)
@@ -791,11 +898,44 @@ func resourceHostUpdate(d *schema.ResourceData, m interface{}) error {
 		return err
 	}
 
-	return resourceHostRead(d, m)
+	return hostRead(d, m, hostResourceParams(d), filter)
+}
+
+// hostExternalInterfaces fetch interfaces on the host not previously managed by this resource
+func hostExternalInterfaces(d *schema.ResourceData, m interface{}) (external zabbix.HostInterfaces, err error) {
+	api := metaAPI(m)
+
+	hosts, err := api.HostsGet(zabbix.Params{
+		"hostids":          d.Id(),
+		"selectInterfaces": "extend",
+	})
+	if err != nil {
+		return
+	}
+	if len(hosts) != 1 {
+		err = errors.New("unable to lookup existing host interfaces")
+		return
+	}
+
+	old, _ := d.GetChange("interface")
+	managed := hostManagedInterfaceIds(old.([]interface{}))
+
+	for _, iface := range hosts[0].Interfaces {
+		if !managed[iface.InterfaceID] {
+			// empty details are returned as [], don't send them back
+			if iface.Details == nil {
+				iface.RawDetails = nil
+			}
+			external = append(external, iface)
+		}
+	}
+
+	log.Debug("preserving external interfaces: %+v", external)
+	return
 }
 
 // resourceHostDelete terraform delete resource handler
 func resourceHostDelete(d *schema.ResourceData, m interface{}) error {
-	api := m.(*zabbix.API)
+	api := metaAPI(m)
 	return api.HostsDeleteByIds([]string{d.Id()})
 }
@@ -0,0 +1,217 @@
+package provider
+
+import (
+	"encoding/json"
+	"errors"
+	"regexp"
+
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/validation"
+	"github.com/tpretz/go-zabbix-api"
+)
+
+// hostInterface api host interface, with owning host (hostinterface.* methods)
+type hostInterface struct {
+	zabbix.HostInterface
+	HostID string `json:"hostid,omitempty"`
+}
+
+type hostInterfaces []hostInterface
+
+// resourceHostInterface terraform host interface resource entrypoint
+func resourceHostInterface() *schema.Resource {
+	s := map[string]*schema.Schema{}
+	for k, v := range interfaceSchema {
+		if k == "id" {
+			continue
+		}
+		s[k] = v
+	}
+	s["hostid"] = &schema.Schema{
+		Type:         schema.TypeString,
+		Required:     true,
+		ForceNew:     true,
+		Description:  "Host ID",
+		ValidateFunc: validation.StringMatch(regexp.MustCompile("^[0-9]+$"), "must be numeric"),
+	}
+
+	return &schema.Resource{
+		Create: resourceHostInterfaceCreate,
+		Read:   resourceHostInterfaceRead,
+		Update: resourceHostInterfaceUpdate,
+		Delete: resourceHostInterfaceDelete,
+		Importer: &schema.ResourceImporter{
+			State: schema.ImportStatePassthrough,
+		},
+		Schema: s,
+	}
+}
+
+// buildHostInterfaceObject create host interface struct
+func buildHostInterfaceObject(d *schema.ResourceData, m interface{}) (*hostInterface, error) {
+	iface, err := buildHostInterface(d, m, "")
+	if err != nil {
+		return nil, err
+	}
+
+	item := hostInterface{
+		HostInterface: iface,
+		HostID:        d.Get("hostid").(string),
+	}
+	item.InterfaceID = d.Id()
+
+	log.Trace("build host interface object: %#v", item)
+
+	return &item, nil
+}
+
+// resourceHostInterfaceCreate terraform create handler
+func resourceHostInterfaceCreate(d *schema.ResourceData, m interface{}) error {
+	api := metaAPI(m)
+
+	item, err := buildHostInterfaceObject(d, m)
+
+	if err != nil {
+		return err
+	}
+
+	items := hostInterfaces{*item}
+
+	err = hostInterfacesCreate(api, items)
+
+	if err != nil {
+		return err
+	}
+
+	log.Trace("created host interface: %+v", items[0])
+
+	d.SetId(items[0].InterfaceID)
+
+	return resourceHostInterfaceRead(d, m)
+}
+
+// resourceHostInterfaceRead terraform read handler
+func resourceHostInterfaceRead(d *schema.ResourceData, m interface{}) error {
+	api := metaAPI(m)
+
+	log.Debug("Lookup of host interface with id %s", d.Id())
+
+	items, err := hostInterfacesGet(api, zabbix.Params{
+		"interfaceids": d.Id(),
+	})
+
+	if err != nil {
+		return err
+	}
+
+	if len(items) < 1 {
+		d.SetId("")
+		return nil
+	}
+	if len(items) > 1 {
+		return errors.New("multiple host interfaces found")
+	}
+	item := items[0]
+
+	log.Debug("Got host interface: %+v", item)
+
+	d.Set("hostid", item.HostID)
+	for k, v := range flattenHostInterface(item.HostInterface, m) {
+		if k == "id" {
+			continue
+		}
+		d.Set(k, v)
+	}
+
+	return nil
+}
+
+// resourceHostInterfaceUpdate terraform update handler
+func resourceHostInterfaceUpdate(d *schema.ResourceData, m interface{}) error {
+	api := metaAPI(m)
+
+	item, err := buildHostInterfaceObject(d, m)
+
+	if err != nil {
+		return err
+	}
+
+	// hostid may not be updated
+	item.HostID = ""
+
+	err = hostInterfacesUpdate(api, hostInterfaces{*item})
+
+	if err != nil {
+		return err
+	}
+
+	return resourceHostInterfaceRead(d, m)
+}
+
+// resourceHostInterfaceDelete terraform delete handler
+func resourceHostInterfaceDelete(d *schema.ResourceData, m interface{}) error {
+	api := metaAPI(m)
+	_, err := api.CallWithError("hostinterface.delete", []string{d.Id()})
+	return err
+}
+
+// handle manual marshal of interface details
+func prepHostInterfaces(items hostInterfaces) {
+	for i := 0; i < len(items); i++ {
+		if items[i].Details == nil {
+			continue
+		}
+		asB, _ := json.Marshal(items[i].Details)
+		items[i].RawDetails = json.RawMessage(asB)
+	}
+}
+
+// hostInterfacesGet wrapper for hostinterface.get
+func hostInterfacesGet(api *zabbix.API, params zabbix.Params) (res hostInterfaces, err error) {
+	if _, present := params["output"]; !present {
+		params["output"] = "extend"
+	}
+	err = api.CallWithErrorParse("hostinterface.get", params, &res)
+	if err != nil {
+		return
+	}
+
+	// unbox details, empty details are returned as []
+	for i := 0; i < len(res); i++ {
+		res[i].Details = nil
+		raw := string(res[i].RawDetails)
+		if raw == "" || raw == "[]" {
+			continue
+		}
+
+		out := zabbix.HostInterfaceDetail{}
+		if err = json.Unmarshal(res[i].RawDetails, &out); err != nil {
+			return
+		}
+		res[i].Details = &out
+	}
+	return
+}
+
+// hostInterfacesCreate wrapper for hostinterface.create
+func hostInterfacesCreate(api *zabbix.API, items hostInterfaces) (err error) {
+	prepHostInterfaces(items)
+	response, err := api.CallWithError("hostinterface.create", items)
+	if err != nil {
+		return
+	}
+
+	result := response.Result.(map[string]interface{})
+	ids := result["interfaceids"].([]interface{})
+	for i, id := range ids {
+		items[i].InterfaceID = id.(string)
+	}
+	return
+}
+
+// hostInterfacesUpdate wrapper for hostinterface.update
+func hostInterfacesUpdate(api *zabbix.API, items hostInterfaces) (err error) {
+	prepHostInterfaces(items)
+	_, err = api.CallWithError("hostinterface.update", items)
+	return
+}
@@ -0,0 +1,48 @@
+package provider
+
+import (
+	"github.com/hashicorp/terraform-plugin-sdk/helper/resource"
+	"testing"
+)
+
+func TestAccResourceHostInterface(t *testing.T) {
+
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+		},
+		Providers: testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccResourceHostInterfaceBasic(),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("zabbix_host_interface.testiface", "type", "snmp"),
+					resource.TestCheckResourceAttr("zabbix_host_interface.testiface", "port", "161"),
+					resource.TestCheckResourceAttr("zabbix_host.testhost3", "interface.#", "1"),
+				),
+			},
+		},
+	})
+}
+
+func testAccResourceHostInterfaceBasic() string {
+	return `
+resource "zabbix_hostgroup" "testgrp3" {
+	name = "test-group3"
+}
+resource "zabbix_host" "testhost3" {
+	host   = "test-host3"
+	groups = [zabbix_hostgroup.testgrp3.id]
+	interface {
+		type = "agent"
+		ip   = "127.0.0.1"
+	}
+	ignore_external_interfaces = true
+}
+resource "zabbix_host_interface" "testiface" {
+	hostid = zabbix_host.testhost3.id
+	type   = "snmp"
+	ip     = "127.0.0.1"
+}
+`
+}
@@ -16,7 +16,7 @@ func resourceHostgroup() *schema.Resource {
 		Update: resourceHostgroupUpdate,
 		Delete: resourceHostgroupDelete,
 		Importer: &schema.ResourceImporter{
-			State: schema.ImportStatePassthrough,
+			State: hostgroupImportState(),
 		},
 
 		Schema: map[string]*schema.Schema{
@@ -48,7 +48,7 @@ func dataHostgroup() *schema.Resource {
 
 // terraform hostgroup create function
 func resourceHostgroupCreate(d *schema.ResourceData, m interface{}) error {
-	api := m.(*zabbix.API)
+	api := metaAPI(m)
 
 	item := zabbix.HostGroup{
 		Name: d.Get("name").(string),
@@ -71,7 +71,7 @@ func resourceHostgroupCreate(d *schema.ResourceData, m interface{}) error {
 
 // hostgroupRead terraform hostgroup read function
 func hostgroupRead(d *schema.ResourceData, m interface{}, params zabbix.Params) error {
-	api := m.(*zabbix.API)
+	api := metaAPI(m)
 
 	hostgroups, err := api.HostGroupsGet(params)
 
@@ -116,7 +116,7 @@ func resourceHostgroupRead(d *schema.ResourceData, m interface{}) error {
 
 // resourceHostgroupUpdate terraform resource update handler
 func resourceHostgroupUpdate(d *schema.ResourceData, m interface{}) error {
-	api := m.(*zabbix.API)
+	api := metaAPI(m)
 
 	item := zabbix.HostGroup{
 		GroupID: d.Id(),
@@ -136,6 +136,6 @@ func resourceHostgroupUpdate(d *schema.ResourceData, m interface{}) error {
 
 // resourceHostgroupDelete terraform resource delete handler
 func resourceHostgroupDelete(d *schema.ResourceData, m interface{}) error {
-	api := m.(*zabbix.API)
+	api := metaAPI(m)
 	return api.HostGroupsDeleteByIds([]string{d.Id()})
 }
@@ -162,6 +162,16 @@ var schemaHttp = map[string]*schema.Schema{
 	},
 }
 
+// item only http attributes, not supported by LLD rules
+var schemaHttpItem = map[string]*schema.Schema{
+	"allow_traps": &schema.Schema{
+		Type:        schema.TypeBool,
+		Optional:    true,
+		Default:     false,
+		Description: "Allow values to be populated as a trapper item",
+	},
+}
+
 // resourceItemHttp Http item resource handler
 func resourceItemHttp() *schema.Resource {
 	return &schema.Resource{
@@ -170,10 +180,11 @@ func resourceItemHttp() *schema.Resource {
 		Update: itemGetUpdateWrapper(itemHttpModFunc, itemHttpReadFunc),
 		Delete: resourceItemDelete,
 		Importer: &schema.ResourceImporter{
-			State: schema.ImportStatePassthrough,
+			State: itemImportState(false),
 		},
+		CustomizeDiff: itemCustomizeDiff,
 
-		Schema: mergeSchemas(itemCommonSchema, itemDelaySchema, itemInterfaceSchema, schemaHttp),
+		Schema: mergeSchemas(itemCommonSchema, itemDelaySchema, itemInterfaceSchema, schemaHttp, schemaHttpItem),
 	}
 }
 func resourceProtoItemHttp() *schema.Resource {
@@ -183,10 +194,11 @@ func resourceProtoItemHttp() *schema.Resource {
 		Update: protoItemGetUpdateWrapper(itemHttpModFunc, itemHttpReadFunc),
 		Delete: resourceProtoItemDelete,
 		Importer: &schema.ResourceImporter{
-			State: schema.ImportStatePassthrough,
+			State: itemImportState(true),
 		},
+		CustomizeDiff: itemCustomizeDiff,
 
-		Schema: mergeSchemas(itemCommonSchema, itemDelaySchema, itemInterfaceSchema, itemPrototypeSchema, schemaHttp),
+		Schema: mergeSchemas(itemCommonSchema, itemDelaySchema, itemInterfaceSchema, itemPrototypeSchema, schemaHttp, schemaHttpItem),
 	}
 }
 func resourceLLDHttp() *schema.Resource {
@@ -196,12 +208,27 @@ func resourceLLDHttp() *schema.Resource {
 		Update: lldGetUpdateWrapper(lldHttpModFunc, lldHttpReadFunc),
 		Delete: resourceLLDDelete,
 		Importer: &schema.ResourceImporter{
-			State: schema.ImportStatePassthrough,
+			State: lldImportState(false),
 		},
+		CustomizeDiff: lldCustomizeDiff,
 
 		Schema: mergeSchemas(lldCommonSchema, itemInterfaceSchema, schemaHttp),
 	}
 }
+func resourceProtoLLDHttp() *schema.Resource {
+	return &schema.Resource{
+		Create: protoLLDGetCreateWrapper(lldHttpModFunc, lldHttpReadFunc),
+		Read:   protoLLDGetReadWrapper(lldHttpReadFunc),
+		Update: protoLLDGetUpdateWrapper(lldHttpModFunc, lldHttpReadFunc),
+		Delete: resourceProtoLLDDelete,
+		Importer: &schema.ResourceImporter{
+			State: lldImportState(true),
+		},
+		CustomizeDiff: lldCustomizeDiff,
+
+		Schema: mergeSchemas(lldCommonSchema, itemInterfaceSchema, schemaHttp, lldPrototypeSchema),
+	}
+}
 
 func httpGenerateHeaders(d *schema.ResourceData) (headers zabbix.HttpHeaders) {
 	m := d.Get("headers").(map[string]interface{})
@@ -223,7 +250,7 @@ func httpFlattenHeaders(headers zabbix.HttpHeaders) (ret map[string]interface{})
 }
 
 // http item modify custom function
-func itemHttpModFunc(d *schema.ResourceData, m interface{}, item *zabbix.Item) {
+func itemHttpModFunc(d *schema.ResourceData, m interface{}, item *apiItem) {
 	item.InterfaceID = d.Get("interfaceid").(string)
 	item.Url = d.Get("url").(string)
 	item.Delay = d.Get("delay").(string)
@@ -236,11 +263,11 @@ func itemHttpModFunc(d *schema.ResourceData, m interface{}, item *zabbix.Item) {
 	item.Password = d.Get("password").(string)
 	item.Posts = d.Get("posts").(string)
 	item.StatusCodes = d.Get("status_codes").(string)
-	item.Timeout = d.Get("timeout").(string)
 	item.Type = zabbix.HTTPAgent
 	item.VerifyHost = "0"
 	item.VerifyPeer = "0"
 	item.FollowRedirects = "1"
+	item.AllowTraps = "0"
 
 	if d.Get("verify_host").(bool) {
 		item.VerifyHost = "1"
@@ -253,9 +280,13 @@ func itemHttpModFunc(d *schema.ResourceData, m interface{}, item *zabbix.Item) {
 	if !d.Get("follow_redirects").(bool) {
 		item.FollowRedirects = "0"
 	}
+
+	if d.Get("allow_traps").(bool) {
+		item.AllowTraps = "1"
+	}
 	item.Headers = httpGenerateHeaders(d)
 }
-func lldHttpModFunc(d *schema.ResourceData, m interface{}, item *zabbix.LLDRule) {
+func lldHttpModFunc(d *schema.ResourceData, m interface{}, item *apiLLDRule) {
 	item.InterfaceID = d.Get("interfaceid").(string)
 	item.Url = d.Get("url").(string)
 	item.RequestMethod = HTTP_METHODS[d.Get("request_method").(string)]
@@ -288,7 +319,7 @@ func lldHttpModFunc(d *schema.ResourceData, m interface{}, item *zabbix.LLDRule)
 }
 
 // http item read custom function
-func itemHttpReadFunc(d *schema.ResourceData, m interface{}, item *zabbix.Item) {
+func itemHttpReadFunc(d *schema.ResourceData, m interface{}, item *apiItem) {
 	d.Set("interfaceid", item.InterfaceID)
 	d.Set("url", item.Url)
 	d.Set("delay", item.Delay)
@@ -301,13 +332,14 @@ func itemHttpReadFunc(d *schema.ResourceData, m interface{}, item *zabbix.Item)
 	d.Set("password", item.Password)
 	d.Set("posts", item.Posts)
 	d.Set("status_codes", item.StatusCodes)
-	d.Set("timeout", item.Timeout)
+	d.Set("timeout", derefString(item.Timeout))
 	d.Set("verify_host", item.VerifyHost == "1")
 	d.Set("verify_peer", item.VerifyPeer == "1")
 	d.Set("follow_redirects", item.FollowRedirects != "0")
+	d.Set("allow_traps", item.AllowTraps == "1")
 	d.Set("headers", httpFlattenHeaders(item.Headers))
 }
-func lldHttpReadFunc(d *schema.ResourceData, m interface{}, item *zabbix.LLDRule) {
+func lldHttpReadFunc(d *schema.ResourceData, m interface{}, item *apiLLDRule) {
 	d.Set("interfaceid", item.InterfaceID)
 	d.Set("url", item.Url)
 	d.Set("request_method", HTTP_METHODS_REV[item.RequestMethod])
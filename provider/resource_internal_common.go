@@ -13,8 +13,9 @@ func resourceItemInternal() *schema.Resource {
 		Update: itemGetUpdateWrapper(itemInternalModFunc, itemInternalReadFunc),
 		Delete: resourceItemDelete,
 		Importer: &schema.ResourceImporter{
-			State: schema.ImportStatePassthrough,
+			State: itemImportState(false),
 		},
+		CustomizeDiff: itemCustomizeDiff,
 
 		Schema: mergeSchemas(itemCommonSchema, itemDelaySchema, itemInterfaceSchema),
 	}
@@ -26,8 +27,9 @@ func resourceProtoItemInternal() *schema.Resource {
 		Update: protoItemGetUpdateWrapper(itemInternalModFunc, itemInternalReadFunc),
 		Delete: resourceProtoItemDelete,
 		Importer: &schema.ResourceImporter{
-			State: schema.ImportStatePassthrough,
+			State: itemImportState(true),
 		},
+		CustomizeDiff: itemCustomizeDiff,
 
 		Schema: mergeSchemas(itemCommonSchema, itemDelaySchema, itemInterfaceSchema, itemPrototypeSchema),
 	}
@@ -39,29 +41,44 @@ func resourceLLDInternal() *schema.Resource {
 		Update: lldGetUpdateWrapper(lldInternalModFunc, lldInternalReadFunc),
 		Delete: resourceLLDDelete,
 		Importer: &schema.ResourceImporter{
-			State: schema.ImportStatePassthrough,
+			State: lldImportState(false),
 		},
+		CustomizeDiff: lldCustomizeDiff,
 
 		Schema: mergeSchemas(lldCommonSchema, itemInterfaceSchema),
 	}
 }
+func resourceProtoLLDInternal() *schema.Resource {
+	return &schema.Resource{
+		Create: protoLLDGetCreateWrapper(lldInternalModFunc, lldInternalReadFunc),
+		Read:   protoLLDGetReadWrapper(lldInternalReadFunc),
+		Update: protoLLDGetUpdateWrapper(lldInternalModFunc, lldInternalReadFunc),
+		Delete: resourceProtoLLDDelete,
+		Importer: &schema.ResourceImporter{
+			State: lldImportState(true),
+		},
+		CustomizeDiff: lldCustomizeDiff,
+
+		Schema: mergeSchemas(lldCommonSchema, itemInterfaceSchema, lldPrototypeSchema),
+	}
+}
 
 // Custom mod handler for item type
-func itemInternalModFunc(d *schema.ResourceData, m interface{}, item *zabbix.Item) {
+func itemInternalModFunc(d *schema.ResourceData, m interface{}, item *apiItem) {
 	item.Type = zabbix.ZabbixInternal
 	item.InterfaceID = d.Get("interfaceid").(string)
 	item.Delay = d.Get("delay").(string)
 }
-func lldInternalModFunc(d *schema.ResourceData, m interface{}, item *zabbix.LLDRule) {
+func lldInternalModFunc(d *schema.ResourceData, m interface{}, item *apiLLDRule) {
 	item.Type = zabbix.ZabbixInternal
 	item.InterfaceID = d.Get("interfaceid").(string)
 }
 
 // Custom read handler for item type
-func itemInternalReadFunc(d *schema.ResourceData, m interface{}, item *zabbix.Item) {
+func itemInternalReadFunc(d *schema.ResourceData, m interface{}, item *apiItem) {
 	d.Set("interfaceid", item.InterfaceID)
 	d.Set("delay", item.Delay)
 }
-func lldInternalReadFunc(d *schema.ResourceData, m interface{}, item *zabbix.LLDRule) {
+func lldInternalReadFunc(d *schema.ResourceData, m interface{}, item *apiLLDRule) {
 	d.Set("interfaceid", item.InterfaceID)
 }
@@ -0,0 +1,96 @@
+package provider
+
+import (
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+	"github.com/tpretz/go-zabbix-api"
+)
+
+var schemaIpmi = map[string]*schema.Schema{
+	"ipmi_sensor": &schema.Schema{
+		Type:        schema.TypeString,
+		Optional:    true,
+		Description: "IPMI Sensor name (not required with the ipmi.get key)",
+	},
+}
+
+// terraform resource handler for item type
+func resourceItemIpmi() *schema.Resource {
+	return &schema.Resource{
+		Create: itemGetCreateWrapper(itemIpmiModFunc, itemIpmiReadFunc),
+		Read:   itemGetReadWrapper(itemIpmiReadFunc),
+		Update: itemGetUpdateWrapper(itemIpmiModFunc, itemIpmiReadFunc),
+		Delete: resourceItemDelete,
+		Importer: &schema.ResourceImporter{
+			State: itemImportState(false),
+		},
+		CustomizeDiff: itemCustomizeDiff,
+
+		Schema: mergeSchemas(itemCommonSchema, itemDelaySchema, itemInterfaceSchema, schemaIpmi),
+	}
+}
+func resourceProtoItemIpmi() *schema.Resource {
+	return &schema.Resource{
+		Create: protoItemGetCreateWrapper(itemIpmiModFunc, itemIpmiReadFunc),
+		Read:   protoItemGetReadWrapper(itemIpmiReadFunc),
+		Update: protoItemGetUpdateWrapper(itemIpmiModFunc, itemIpmiReadFunc),
+		Delete: resourceProtoItemDelete,
+		Importer: &schema.ResourceImporter{
+			State: itemImportState(true),
+		},
+		CustomizeDiff: itemCustomizeDiff,
+
+		Schema: mergeSchemas(itemCommonSchema, itemDelaySchema, itemInterfaceSchema, itemPrototypeSchema, schemaIpmi),
+	}
+}
+func resourceLLDIpmi() *schema.Resource {
+	return &schema.Resource{
+		Create: lldGetCreateWrapper(lldIpmiModFunc, lldIpmiReadFunc),
+		Read:   lldGetReadWrapper(lldIpmiReadFunc),
+		Update: lldGetUpdateWrapper(lldIpmiModFunc, lldIpmiReadFunc),
+		Delete: resourceLLDDelete,
+		Importer: &schema.ResourceImporter{
+			State: lldImportState(false),
+		},
+		CustomizeDiff: lldCustomizeDiff,
+
+		Schema: mergeSchemas(lldCommonSchema, lldInterfaceSchema, schemaIpmi),
+	}
+}
+func resourceProtoLLDIpmi() *schema.Resource {
+	return &schema.Resource{
+		Create: protoLLDGetCreateWrapper(lldIpmiModFunc, lldIpmiReadFunc),
+		Read:   protoLLDGetReadWrapper(lldIpmiReadFunc),
+		Update: protoLLDGetUpdateWrapper(lldIpmiModFunc, lldIpmiReadFunc),
+		Delete: resourceProtoLLDDelete,
+		Importer: &schema.ResourceImporter{
+			State: lldImportState(true),
+		},
+		CustomizeDiff: lldCustomizeDiff,
+
+		Schema: mergeSchemas(lldCommonSchema, lldInterfaceSchema, schemaIpmi, lldPrototypeSchema),
+	}
+}
+
+// Custom mod handler for item type
+func itemIpmiModFunc(d *schema.ResourceData, m interface{}, item *apiItem) {
+	item.Type = zabbix.IPMIAgent
+	item.Delay = d.Get("delay").(string)
+	item.InterfaceID = d.Get("interfaceid").(string)
+	item.IpmiSensor = d.Get("ipmi_sensor").(string)
+}
+func lldIpmiModFunc(d *schema.ResourceData, m interface{}, item *apiLLDRule) {
+	item.Type = zabbix.IPMIAgent
+	item.InterfaceID = d.Get("interfaceid").(string)
+	item.IpmiSensor = d.Get("ipmi_sensor").(string)
+}
+
+// Custom read handler for item type
+func itemIpmiReadFunc(d *schema.ResourceData, m interface{}, item *apiItem) {
+	d.Set("delay", item.Delay)
+	d.Set("interfaceid", item.InterfaceID)
+	d.Set("ipmi_sensor", item.IpmiSensor)
+}
+func lldIpmiReadFunc(d *schema.ResourceData, m interface{}, item *apiLLDRule) {
+	d.Set("interfaceid", item.InterfaceID)
+	d.Set("ipmi_sensor", item.IpmiSensor)
+}
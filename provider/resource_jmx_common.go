@@ -0,0 +1,118 @@
+package provider
+
+import (
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/validation"
+	"github.com/tpretz/go-zabbix-api"
+)
+
+var schemaJmx = map[string]*schema.Schema{
+	"jmx_endpoint": &schema.Schema{
+		Type:         schema.TypeString,
+		Optional:     true,
+		Description:  "JMX endpoint connection string",
+		ValidateFunc: validation.StringIsNotWhiteSpace,
+		Default:      "service:jmx:rmi:///jndi/rmi://{HOST.CONN}:{HOST.PORT}/jmxrmi",
+	},
+	"username": &schema.Schema{
+		Type:        schema.TypeString,
+		Optional:    true,
+		Description: "JMX Authentication Username",
+	},
+	"password": &schema.Schema{
+		Type:        schema.TypeString,
+		Optional:    true,
+		Sensitive:   true,
+		Description: "JMX Authentication Password",
+	},
+}
+
+// terraform resource handler for item type
+func resourceItemJmx() *schema.Resource {
+	return &schema.Resource{
+		Create: itemGetCreateWrapper(itemJmxModFunc, itemJmxReadFunc),
+		Read:   itemGetReadWrapper(itemJmxReadFunc),
+		Update: itemGetUpdateWrapper(itemJmxModFunc, itemJmxReadFunc),
+		Delete: resourceItemDelete,
+		Importer: &schema.ResourceImporter{
+			State: itemImportState(false),
+		},
+		CustomizeDiff: itemCustomizeDiff,
+
+		Schema: mergeSchemas(itemCommonSchema, itemDelaySchema, itemInterfaceSchema, schemaJmx),
+	}
+}
+func resourceProtoItemJmx() *schema.Resource {
+	return &schema.Resource{
+		Create: protoItemGetCreateWrapper(itemJmxModFunc, itemJmxReadFunc),
+		Read:   protoItemGetReadWrapper(itemJmxReadFunc),
+		Update: protoItemGetUpdateWrapper(itemJmxModFunc, itemJmxReadFunc),
+		Delete: resourceProtoItemDelete,
+		Importer: &schema.ResourceImporter{
+			State: itemImportState(true),
+		},
+		CustomizeDiff: itemCustomizeDiff,
+
+		Schema: mergeSchemas(itemCommonSchema, itemDelaySchema, itemInterfaceSchema, itemPrototypeSchema, schemaJmx),
+	}
+}
+func resourceLLDJmx() *schema.Resource {
+	return &schema.Resource{
+		Create: lldGetCreateWrapper(lldJmxModFunc, lldJmxReadFunc),
+		Read:   lldGetReadWrapper(lldJmxReadFunc),
+		Update: lldGetUpdateWrapper(lldJmxModFunc, lldJmxReadFunc),
+		Delete: resourceLLDDelete,
+		Importer: &schema.ResourceImporter{
+			State: lldImportState(false),
+		},
+		CustomizeDiff: lldCustomizeDiff,
+
+		Schema: mergeSchemas(lldCommonSchema, lldInterfaceSchema, schemaJmx),
+	}
+}
+func resourceProtoLLDJmx() *schema.Resource {
+	return &schema.Resource{
+		Create: protoLLDGetCreateWrapper(lldJmxModFunc, lldJmxReadFunc),
+		Read:   protoLLDGetReadWrapper(lldJmxReadFunc),
+		Update: protoLLDGetUpdateWrapper(lldJmxModFunc, lldJmxReadFunc),
+		Delete: resourceProtoLLDDelete,
+		Importer: &schema.ResourceImporter{
+			State: lldImportState(true),
+		},
+		CustomizeDiff: lldCustomizeDiff,
+
+		Schema: mergeSchemas(lldCommonSchema, lldInterfaceSchema, schemaJmx, lldPrototypeSchema),
+	}
+}
+
+// Custom mod handler for item type
+func itemJmxModFunc(d *schema.ResourceData, m interface{}, item *apiItem) {
+	item.Type = zabbix.JMXAgent
+	item.Delay = d.Get("delay").(string)
+	item.InterfaceID = d.Get("interfaceid").(string)
+	item.JmxEndpoint = d.Get("jmx_endpoint").(string)
+	item.Username = d.Get("username").(string)
+	item.Password = d.Get("password").(string)
+}
+func lldJmxModFunc(d *schema.ResourceData, m interface{}, item *apiLLDRule) {
+	item.Type = zabbix.JMXAgent
+	item.InterfaceID = d.Get("interfaceid").(string)
+	item.JmxEndpoint = d.Get("jmx_endpoint").(string)
+	item.Username = d.Get("username").(string)
+	item.Password = d.Get("password").(string)
+}
+
+// Custom read handler for item type
+func itemJmxReadFunc(d *schema.ResourceData, m interface{}, item *apiItem) {
+	d.Set("delay", item.Delay)
+	d.Set("interfaceid", item.InterfaceID)
+	d.Set("jmx_endpoint", item.JmxEndpoint)
+	d.Set("username", item.Username)
+	d.Set("password", item.Password)
+}
+func lldJmxReadFunc(d *schema.ResourceData, m interface{}, item *apiLLDRule) {
+	d.Set("interfaceid", item.InterfaceID)
+	d.Set("jmx_endpoint", item.JmxEndpoint)
+	d.Set("username", item.Username)
+	d.Set("password", item.Password)
+}
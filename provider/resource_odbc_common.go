@@ -0,0 +1,114 @@
+package provider
+
+import (
+	"github.com/hashicorp/terraform-plugin-sdk/helper/customdiff"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/validation"
+	"github.com/tpretz/go-zabbix-api"
+)
+
+var schemaOdbc = map[string]*schema.Schema{
+	"username": &schema.Schema{
+		Type:        schema.TypeString,
+		Optional:    true,
+		Description: "Database Username",
+	},
+	"password": &schema.Schema{
+		Type:        schema.TypeString,
+		Optional:    true,
+		Sensitive:   true,
+		Description: "Database Password",
+	},
+	"sql": &schema.Schema{
+		Type:         schema.TypeString,
+		Required:     true,
+		Description:  "SQL query to execute",
+		ValidateFunc: validation.StringIsNotWhiteSpace,
+	},
+}
+
+// terraform resource handler for item type
+func resourceItemOdbc() *schema.Resource {
+	return &schema.Resource{
+		Create: itemGetCreateWrapper(itemOdbcModFunc, itemOdbcReadFunc),
+		Read:   itemGetReadWrapper(itemOdbcReadFunc),
+		Update: itemGetUpdateWrapper(itemOdbcModFunc, itemOdbcReadFunc),
+		Delete: resourceItemDelete,
+		Importer: &schema.ResourceImporter{
+			State: itemImportState(false),
+		},
+		CustomizeDiff: customdiff.All(itemCustomizeDiff, itemTimeoutCustomizeDiff),
+
+		Schema: mergeSchemas(itemCommonSchema, itemDelaySchema, itemTimeoutSchema, schemaOdbc),
+	}
+}
+func resourceProtoItemOdbc() *schema.Resource {
+	return &schema.Resource{
+		Create: protoItemGetCreateWrapper(itemOdbcModFunc, itemOdbcReadFunc),
+		Read:   protoItemGetReadWrapper(itemOdbcReadFunc),
+		Update: protoItemGetUpdateWrapper(itemOdbcModFunc, itemOdbcReadFunc),
+		Delete: resourceProtoItemDelete,
+		Importer: &schema.ResourceImporter{
+			State: itemImportState(true),
+		},
+		CustomizeDiff: customdiff.All(itemCustomizeDiff, itemTimeoutCustomizeDiff),
+
+		Schema: mergeSchemas(itemCommonSchema, itemDelaySchema, itemTimeoutSchema, itemPrototypeSchema, schemaOdbc),
+	}
+}
+func resourceLLDOdbc() *schema.Resource {
+	return &schema.Resource{
+		Create: lldGetCreateWrapper(lldOdbcModFunc, lldOdbcReadFunc),
+		Read:   lldGetReadWrapper(lldOdbcReadFunc),
+		Update: lldGetUpdateWrapper(lldOdbcModFunc, lldOdbcReadFunc),
+		Delete: resourceLLDDelete,
+		Importer: &schema.ResourceImporter{
+			State: lldImportState(false),
+		},
+		CustomizeDiff: lldCustomizeDiff,
+
+		Schema: mergeSchemas(lldCommonSchema, schemaOdbc),
+	}
+}
+func resourceProtoLLDOdbc() *schema.Resource {
+	return &schema.Resource{
+		Create: protoLLDGetCreateWrapper(lldOdbcModFunc, lldOdbcReadFunc),
+		Read:   protoLLDGetReadWrapper(lldOdbcReadFunc),
+		Update: protoLLDGetUpdateWrapper(lldOdbcModFunc, lldOdbcReadFunc),
+		Delete: resourceProtoLLDDelete,
+		Importer: &schema.ResourceImporter{
+			State: lldImportState(true),
+		},
+		CustomizeDiff: lldCustomizeDiff,
+
+		Schema: mergeSchemas(lldCommonSchema, schemaOdbc, lldPrototypeSchema),
+	}
+}
+
+// Custom mod handler for item type
+func itemOdbcModFunc(d *schema.ResourceData, m interface{}, item *apiItem) {
+	item.Type = zabbix.DatabaseMonitor
+	item.Delay = d.Get("delay").(string)
+	item.Username = d.Get("username").(string)
+	item.Password = d.Get("password").(string)
+	item.Params = d.Get("sql").(string)
+}
+func lldOdbcModFunc(d *schema.ResourceData, m interface{}, item *apiLLDRule) {
+	item.Type = zabbix.DatabaseMonitor
+	item.Username = d.Get("username").(string)
+	item.Password = d.Get("password").(string)
+	item.Params = d.Get("sql").(string)
+}
+
+// Custom read handler for item type
+func itemOdbcReadFunc(d *schema.ResourceData, m interface{}, item *apiItem) {
+	d.Set("delay", item.Delay)
+	d.Set("username", item.Username)
+	d.Set("password", item.Password)
+	d.Set("sql", item.Params)
+}
+func lldOdbcReadFunc(d *schema.ResourceData, m interface{}, item *apiLLDRule) {
+	d.Set("username", item.Username)
+	d.Set("password", item.Password)
+	d.Set("sql", item.Params)
+}
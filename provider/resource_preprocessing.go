@@ -0,0 +1,262 @@
+package provider
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/validation"
+	"github.com/hashicorp/terraform/helper/hashcode"
+	"github.com/tpretz/go-zabbix-api"
+)
+
+// zabbix server trapper protocol header
+var zabbixProtocolHeader = []byte("ZBXD\x01")
+
+// zabbix server trapper protocol flags, compressed and large packets are not supported
+const zabbixProtocolCompressed = 0x02
+const zabbixProtocolLarge = 0x04
+
+const zabbixServerPort = "10051"
+const zabbixServerTimeout = 30 * time.Second
+
+// largest accepted server response, preprocessing results are small
+const zabbixServerMaxResponse = 16 * 1024 * 1024
+
+// preprocessingTestRequest server side preprocessing.test request
+type preprocessingTestRequest struct {
+	Request string                `json:"request"`
+	Sid     string                `json:"sid"`
+	Data    preprocessingTestData `json:"data"`
+}
+
+type preprocessingTestData struct {
+	Value     string                    `json:"value"`
+	ValueType zabbix.ValueType          `json:"value_type,string"`
+	History   *preprocessingTestHistory `json:"history,omitempty"`
+	Steps     zabbix.Preprocessors      `json:"steps"`
+}
+
+type preprocessingTestHistory struct {
+	Value     string `json:"value"`
+	Timestamp string `json:"timestamp"`
+}
+
+// preprocessingTestResponse server side preprocessing.test response
+type preprocessingTestResponse struct {
+	Response string `json:"response"`
+	Info     string `json:"info"`
+	Error    string `json:"error"`
+	Data     struct {
+		Steps []struct {
+			Result *string `json:"result"`
+			Error  *string `json:"error"`
+		} `json:"steps"`
+		Result *string `json:"result"`
+		Error  *string `json:"error"`
+	} `json:"data"`
+}
+
+// dataPreprocessingTest terraform preprocessing test data source entrypoint
+func dataPreprocessingTest() *schema.Resource {
+	steps := preprocessorSchema()
+	steps.Optional = false
+	steps.Required = true
+
+	return &schema.Resource{
+		Read: dataPreprocessingTestRead,
+
+		Schema: map[string]*schema.Schema{
+			"server": &schema.Schema{
+				Type:         schema.TypeString,
+				Required:     true,
+				ValidateFunc: validation.StringIsNotWhiteSpace,
+				Description:  "Zabbix server trapper address (host:port), port defaults to " + zabbixServerPort + ", the api session id is sent to it unencrypted",
+			},
+			"allow_cleartext_session": &schema.Schema{
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+				Description: "Allow sending the api session id unencrypted to the server trapper, must be set to true to run the test",
+			},
+			"value": &schema.Schema{
+				Type:        schema.TypeString,
+				Required:    true,
+				Description: "Value to preprocess",
+			},
+			"valuetype": &schema.Schema{
+				Type:         schema.TypeString,
+				Required:     true,
+				Description:  "Value type, one of: float, character, log, unsigned, text",
+				ValidateFunc: validation.StringInSlice(ITEM_VALUE_TYPES_ARR, false),
+			},
+			"previous_value": &schema.Schema{
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "Previous value, for change and throttling steps",
+			},
+			"previous_timestamp": &schema.Schema{
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "Previous value timestamp, for change and throttling steps",
+			},
+			"preprocessor": steps,
+			"step": &schema.Schema{
+				Type:        schema.TypeList,
+				Computed:    true,
+				Description: "Per step results",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"result": &schema.Schema{
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"error": &schema.Schema{
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+					},
+				},
+			},
+			"result": &schema.Schema{
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "Final preprocessed value",
+			},
+			"error": &schema.Schema{
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "Final preprocessing error",
+			},
+		},
+	}
+}
+
+// dataPreprocessingTestRead read handler for data resource
+func dataPreprocessingTestRead(d *schema.ResourceData, m interface{}) error {
+	api := metaAPI(m)
+
+	// anyone observing the trapper connection gains a working api session
+	if !d.Get("allow_cleartext_session").(bool) {
+		return errors.New("allow_cleartext_session must be set to true, the api session id is sent unencrypted to the server trapper")
+	}
+
+	steps := itemGeneratePreprocessors(d)
+	for i := range steps {
+		if err := validatePreprocessorStep(steps[i], preprocessorStepParams(d, fmt.Sprintf("preprocessor.%d.", i))); err != nil {
+			return fmt.Errorf("preprocessor %d: %s", i, err)
+		}
+	}
+
+	req := preprocessingTestRequest{
+		Request: "preprocessing.test",
+		Sid:     api.Auth,
+		Data: preprocessingTestData{
+			Value:     d.Get("value").(string),
+			ValueType: ITEM_VALUE_TYPES[d.Get("valuetype").(string)],
+			Steps:     steps,
+		},
+	}
+	if v, ok := d.GetOk("previous_value"); ok {
+		req.Data.History = &preprocessingTestHistory{
+			Value:     v.(string),
+			Timestamp: d.Get("previous_timestamp").(string),
+		}
+	}
+
+	server := zabbixServerAddress(d.Get("server").(string))
+
+	log.Debug("testing preprocessing against %s with %+v", server, req.Data)
+
+	var res preprocessingTestResponse
+	if err := zabbixServerRequest(server, req, &res); err != nil {
+		return err
+	}
+	if res.Response != "success" {
+		if res.Error == "" {
+			res.Error = res.Info
+		}
+		return fmt.Errorf("preprocessing.test failed: %s", res.Error)
+	}
+
+	results := make([]interface{}, len(res.Data.Steps))
+	for i, s := range res.Data.Steps {
+		results[i] = map[string]interface{}{
+			"result": derefString(s.Result),
+			"error":  derefString(s.Error),
+		}
+	}
+
+	id, _ := json.Marshal(req.Data)
+	d.SetId(strconv.Itoa(hashcode.String(string(id))))
+	d.Set("step", results)
+	d.Set("result", derefString(res.Data.Result))
+	d.Set("error", derefString(res.Data.Error))
+
+	return nil
+}
+
+// zabbixServerAddress trapper address, with the default port when not given
+func zabbixServerAddress(server string) string {
+	if _, _, err := net.SplitHostPort(server); err != nil {
+		return net.JoinHostPort(server, zabbixServerPort)
+	}
+	return server
+}
+
+// zabbixServerRequest perform a single request against the server trapper
+func zabbixServerRequest(server string, req interface{}, res interface{}) error {
+	body, err := json.Marshal(req)
+	if err != nil {
+		return err
+	}
+
+	conn, err := net.DialTimeout("tcp", server, zabbixServerTimeout)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(zabbixServerTimeout))
+
+	packet := make([]byte, len(zabbixProtocolHeader)+8, len(zabbixProtocolHeader)+8+len(body))
+	copy(packet, zabbixProtocolHeader)
+	binary.LittleEndian.PutUint64(packet[len(zabbixProtocolHeader):], uint64(len(body)))
+	if _, err = conn.Write(append(packet, body...)); err != nil {
+		return err
+	}
+
+	header := make([]byte, len(zabbixProtocolHeader)+8)
+	if _, err = io.ReadFull(conn, header); err != nil {
+		return err
+	}
+	if string(header[:4]) != "ZBXD" {
+		return errors.New("invalid zabbix server response header")
+	}
+	if header[4]&(zabbixProtocolCompressed|zabbixProtocolLarge) != 0 {
+		return fmt.Errorf("unsupported zabbix server response flags 0x%02x", header[4])
+	}
+	// 4 byte data length followed by the reserved uncompressed length
+	length := binary.LittleEndian.Uint32(header[len(zabbixProtocolHeader):])
+	if length > zabbixServerMaxResponse {
+		return fmt.Errorf("zabbix server response of %d bytes exceeds the %d byte limit", length, zabbixServerMaxResponse)
+	}
+	data := make([]byte, length)
+	if _, err = io.ReadFull(conn, data); err != nil {
+		return err
+	}
+
+	return json.Unmarshal(data, res)
+}
+
+func derefString(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
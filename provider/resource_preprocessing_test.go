@@ -0,0 +1,144 @@
+package provider
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"io"
+	"net"
+	"strings"
+	"testing"
+
+	"github.com/tpretz/go-zabbix-api"
+)
+
+func TestZabbixServerRequest(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+
+	received := make(chan map[string]interface{}, 1)
+	go func() {
+		conn, err := l.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		header := make([]byte, 13)
+		io.ReadFull(conn, header)
+		body := make([]byte, binary.LittleEndian.Uint64(header[5:]))
+		io.ReadFull(conn, body)
+
+		var req map[string]interface{}
+		json.Unmarshal(body, &req)
+		received <- req
+
+		resp := []byte(`{"response":"success","data":{"steps":[{"result":"2"},{"error":"boom"}],"error":"boom"}}`)
+		out := append([]byte("ZBXD\x01"), make([]byte, 8)...)
+		binary.LittleEndian.PutUint64(out[5:], uint64(len(resp)))
+		conn.Write(append(out, resp...))
+	}()
+
+	req := preprocessingTestRequest{
+		Request: "preprocessing.test",
+		Sid:     "abc",
+		Data: preprocessingTestData{
+			Value: "1",
+		},
+	}
+	var res preprocessingTestResponse
+	if err := zabbixServerRequest(l.Addr().String(), req, &res); err != nil {
+		t.Fatal(err)
+	}
+
+	got := <-received
+	if got["request"] != "preprocessing.test" || got["sid"] != "abc" {
+		t.Errorf("unexpected request %v", got)
+	}
+	if len(res.Data.Steps) != 2 || derefString(res.Data.Steps[0].Result) != "2" || derefString(res.Data.Steps[1].Error) != "boom" {
+		t.Errorf("unexpected steps %+v", res.Data.Steps)
+	}
+	if derefString(res.Data.Result) != "" || derefString(res.Data.Error) != "boom" {
+		t.Errorf("unexpected final result %+v", res.Data)
+	}
+}
+
+func TestZabbixServerAddress(t *testing.T) {
+	cases := map[string]string{
+		"server.example.com":       "server.example.com:10051",
+		"server.example.com:10052": "server.example.com:10052",
+		"[::1]:10052":              "[::1]:10052",
+	}
+	for in, expected := range cases {
+		if got := zabbixServerAddress(in); got != expected {
+			t.Errorf("expected %s to resolve to %s, got %s", in, expected, got)
+		}
+	}
+}
+
+func TestZabbixServerRequestInvalidResponse(t *testing.T) {
+	cases := map[string][]byte{
+		"unsupported zabbix server response flags": append([]byte("ZBXD\x03"), 10, 0, 0, 0, 20, 0, 0, 0),
+		"exceeds the":                           append([]byte("ZBXD\x01"), 0xff, 0xff, 0xff, 0xff, 0, 0, 0, 0),
+		"invalid zabbix server response header": []byte("HTTP/1.1 400 "),
+	}
+
+	for expected, header := range cases {
+		l, err := net.Listen("tcp", "127.0.0.1:0")
+		if err != nil {
+			t.Fatal(err)
+		}
+		go func(header []byte) {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			defer conn.Close()
+			io.ReadFull(conn, make([]byte, 13))
+			conn.Write(header)
+		}(header)
+
+		var res preprocessingTestResponse
+		err = zabbixServerRequest(l.Addr().String(), preprocessingTestRequest{Request: "preprocessing.test"}, &res)
+		if err == nil || !strings.Contains(err.Error(), expected) {
+			t.Errorf("expected error %q, got %v", expected, err)
+		}
+		l.Close()
+	}
+}
+
+func TestPreprocessingTestCleartextOptIn(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+
+	connected := make(chan bool, 1)
+	go func() {
+		if conn, err := l.Accept(); err == nil {
+			connected <- true
+			conn.Close()
+		}
+	}()
+
+	d := dataPreprocessingTest().Data(nil)
+	d.Set("server", l.Addr().String())
+	d.Set("value", "1")
+	d.Set("valuetype", "unsigned")
+	d.Set("preprocessor", []interface{}{map[string]interface{}{"type": "multiplier", "params": []interface{}{"8"}}})
+
+	api := &zabbix.API{Config: zabbix.Config{Version: 60000}}
+	api.Auth = "abc"
+	err = dataPreprocessingTestRead(d, api)
+	if err == nil || !strings.Contains(err.Error(), "allow_cleartext_session must be set to true") {
+		t.Errorf("expected opt-in error, got %v", err)
+	}
+	select {
+	case <-connected:
+		t.Error("session sent to the server without opt-in")
+	default:
+	}
+}
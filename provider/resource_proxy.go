@@ -51,7 +51,7 @@ func dataProxyRead(d *schema.ResourceData, m interface{}) error {
 
 // proxyRead common proxy read function
 func proxyRead(d *schema.ResourceData, m interface{}, params zabbix.Params) error {
-	api := m.(*zabbix.API)
+	api := metaAPI(m)
 
 	log.Debug("Lookup of proxy with params %#v", params)
 
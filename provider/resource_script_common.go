@@ -0,0 +1,171 @@
+package provider
+
+import (
+	"errors"
+
+	"github.com/hashicorp/terraform-plugin-sdk/helper/customdiff"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/validation"
+	"github.com/tpretz/go-zabbix-api"
+)
+
+// script item type, not present in the api library
+const ScriptItem zabbix.ItemType = 21
+
+var schemaScript = map[string]*schema.Schema{
+	"script": &schema.Schema{
+		Type:         schema.TypeString,
+		Required:     true,
+		Description:  "JavaScript code to execute",
+		ValidateFunc: validation.StringIsNotWhiteSpace,
+	},
+	"parameter": &schema.Schema{
+		Type:        schema.TypeSet,
+		Optional:    true,
+		Description: "Script parameters",
+		Elem: &schema.Resource{
+			Schema: map[string]*schema.Schema{
+				"name": &schema.Schema{
+					Type:         schema.TypeString,
+					Required:     true,
+					Description:  "Parameter name",
+					ValidateFunc: validation.StringIsNotWhiteSpace,
+				},
+				"value": &schema.Schema{
+					Type:        schema.TypeString,
+					Optional:    true,
+					Description: "Parameter value",
+				},
+			},
+		},
+	},
+	"timeout": &schema.Schema{
+		Type:        schema.TypeString,
+		Optional:    true,
+		Description: "Script execution timeout",
+		Default:     "3s",
+	},
+}
+
+// resourceItemScript Script item resource handler
+func resourceItemScript() *schema.Resource {
+	return &schema.Resource{
+		Create: itemGetCreateWrapper(itemScriptModFunc, itemScriptReadFunc),
+		Read:   itemGetReadWrapper(itemScriptReadFunc),
+		Update: itemGetUpdateWrapper(itemScriptModFunc, itemScriptReadFunc),
+		Delete: resourceItemDelete,
+		Importer: &schema.ResourceImporter{
+			State: itemImportState(false),
+		},
+		CustomizeDiff: customdiff.All(itemCustomizeDiff, scriptCustomizeDiff),
+
+		Schema: mergeSchemas(itemCommonSchema, itemDelaySchema, itemInterfaceSchema, schemaScript),
+	}
+}
+func resourceProtoItemScript() *schema.Resource {
+	return &schema.Resource{
+		Create: protoItemGetCreateWrapper(itemScriptModFunc, itemScriptReadFunc),
+		Read:   protoItemGetReadWrapper(itemScriptReadFunc),
+		Update: protoItemGetUpdateWrapper(itemScriptModFunc, itemScriptReadFunc),
+		Delete: resourceProtoItemDelete,
+		Importer: &schema.ResourceImporter{
+			State: itemImportState(true),
+		},
+		CustomizeDiff: customdiff.All(itemCustomizeDiff, scriptCustomizeDiff),
+
+		Schema: mergeSchemas(itemCommonSchema, itemDelaySchema, itemInterfaceSchema, itemPrototypeSchema, schemaScript),
+	}
+}
+func resourceLLDScript() *schema.Resource {
+	return &schema.Resource{
+		Create: lldGetCreateWrapper(lldScriptModFunc, lldScriptReadFunc),
+		Read:   lldGetReadWrapper(lldScriptReadFunc),
+		Update: lldGetUpdateWrapper(lldScriptModFunc, lldScriptReadFunc),
+		Delete: resourceLLDDelete,
+		Importer: &schema.ResourceImporter{
+			State: lldImportState(false),
+		},
+		CustomizeDiff: customdiff.All(lldCustomizeDiff, scriptCustomizeDiff),
+
+		Schema: mergeSchemas(lldCommonSchema, lldInterfaceSchema, schemaScript),
+	}
+}
+func resourceProtoLLDScript() *schema.Resource {
+	return &schema.Resource{
+		Create: protoLLDGetCreateWrapper(lldScriptModFunc, lldScriptReadFunc),
+		Read:   protoLLDGetReadWrapper(lldScriptReadFunc),
+		Update: protoLLDGetUpdateWrapper(lldScriptModFunc, lldScriptReadFunc),
+		Delete: resourceProtoLLDDelete,
+		Importer: &schema.ResourceImporter{
+			State: lldImportState(true),
+		},
+		CustomizeDiff: customdiff.All(lldCustomizeDiff, scriptCustomizeDiff),
+
+		Schema: mergeSchemas(lldCommonSchema, lldInterfaceSchema, schemaScript, lldPrototypeSchema),
+	}
+}
+
+// script items only exist from 5.4
+func scriptCustomizeDiff(d *schema.ResourceDiff, m interface{}) error {
+	api := metaAPI(m)
+	if api.Config.Version < 50400 {
+		return errors.New("script items require zabbix 5.4 or later")
+	}
+	return nil
+}
+
+func scriptGenerateParameters(d *schema.ResourceData) (params itemParameters) {
+	set := d.Get("parameter").(*schema.Set).List()
+	params = make(itemParameters, len(set))
+
+	for i := 0; i < len(set); i++ {
+		current := set[i].(map[string]interface{})
+		params[i] = itemParameter{
+			Name:  current["name"].(string),
+			Value: current["value"].(string),
+		}
+	}
+	return
+}
+
+func scriptFlattenParameters(params itemParameters) []interface{} {
+	val := make([]interface{}, len(params))
+	for i := 0; i < len(params); i++ {
+		val[i] = map[string]interface{}{
+			"name":  params[i].Name,
+			"value": params[i].Value,
+		}
+	}
+	return val
+}
+
+// script item modify custom function
+func itemScriptModFunc(d *schema.ResourceData, m interface{}, item *apiItem) {
+	item.Type = ScriptItem
+	item.Delay = d.Get("delay").(string)
+	item.InterfaceID = d.Get("interfaceid").(string)
+	item.Params = d.Get("script").(string)
+	item.Parameters = scriptGenerateParameters(d)
+}
+func lldScriptModFunc(d *schema.ResourceData, m interface{}, item *apiLLDRule) {
+	item.Type = ScriptItem
+	item.InterfaceID = d.Get("interfaceid").(string)
+	item.Params = d.Get("script").(string)
+	item.Timeout = d.Get("timeout").(string)
+	item.Parameters = scriptGenerateParameters(d)
+}
+
+// script item read custom function
+func itemScriptReadFunc(d *schema.ResourceData, m interface{}, item *apiItem) {
+	d.Set("delay", item.Delay)
+	d.Set("interfaceid", item.InterfaceID)
+	d.Set("script", item.Params)
+	d.Set("timeout", derefString(item.Timeout))
+	d.Set("parameter", scriptFlattenParameters(item.Parameters))
+}
+func lldScriptReadFunc(d *schema.ResourceData, m interface{}, item *apiLLDRule) {
+	d.Set("interfaceid", item.InterfaceID)
+	d.Set("script", item.Params)
+	d.Set("timeout", item.Timeout)
+	d.Set("parameter", scriptFlattenParameters(item.Parameters))
+}
@@ -1,6 +1,7 @@
 package provider
 
 import (
+	"github.com/hashicorp/terraform-plugin-sdk/helper/customdiff"
 	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
 	"github.com/tpretz/go-zabbix-api"
 )
@@ -13,10 +14,11 @@ func resourceItemSimple() *schema.Resource {
 		Update: itemGetUpdateWrapper(itemSimpleModFunc, itemSimpleReadFunc),
 		Delete: resourceItemDelete,
 		Importer: &schema.ResourceImporter{
-			State: schema.ImportStatePassthrough,
+			State: itemImportState(false),
 		},
+		CustomizeDiff: customdiff.All(itemCustomizeDiff, itemTimeoutCustomizeDiff),
 
-		Schema: mergeSchemas(itemCommonSchema, itemDelaySchema, itemInterfaceSchema),
+		Schema: mergeSchemas(itemCommonSchema, itemDelaySchema, itemInterfaceSchema, itemTimeoutSchema),
 	}
 }
 func resourceProtoItemSimple() *schema.Resource {
@@ -26,10 +28,11 @@ func resourceProtoItemSimple() *schema.Resource {
 		Update: protoItemGetUpdateWrapper(itemSimpleModFunc, itemSimpleReadFunc),
 		Delete: resourceProtoItemDelete,
 		Importer: &schema.ResourceImporter{
-			State: schema.ImportStatePassthrough,
+			State: itemImportState(true),
 		},
+		CustomizeDiff: customdiff.All(itemCustomizeDiff, itemTimeoutCustomizeDiff),
 
-		Schema: mergeSchemas(itemCommonSchema, itemDelaySchema, itemInterfaceSchema, itemPrototypeSchema),
+		Schema: mergeSchemas(itemCommonSchema, itemDelaySchema, itemInterfaceSchema, itemTimeoutSchema, itemPrototypeSchema),
 	}
 }
 func resourceLLDSimple() *schema.Resource {
@@ -39,29 +42,44 @@ func resourceLLDSimple() *schema.Resource {
 		Update: lldGetUpdateWrapper(lldSimpleModFunc, lldSimpleReadFunc),
 		Delete: resourceLLDDelete,
 		Importer: &schema.ResourceImporter{
-			State: schema.ImportStatePassthrough,
+			State: lldImportState(false),
 		},
+		CustomizeDiff: lldCustomizeDiff,
 
 		Schema: mergeSchemas(lldCommonSchema, itemInterfaceSchema),
 	}
 }
+func resourceProtoLLDSimple() *schema.Resource {
+	return &schema.Resource{
+		Create: protoLLDGetCreateWrapper(lldSimpleModFunc, lldSimpleReadFunc),
+		Read:   protoLLDGetReadWrapper(lldSimpleReadFunc),
+		Update: protoLLDGetUpdateWrapper(lldSimpleModFunc, lldSimpleReadFunc),
+		Delete: resourceProtoLLDDelete,
+		Importer: &schema.ResourceImporter{
+			State: lldImportState(true),
+		},
+		CustomizeDiff: lldCustomizeDiff,
+
+		Schema: mergeSchemas(lldCommonSchema, itemInterfaceSchema, lldPrototypeSchema),
+	}
+}
 
 // Custom mod handler for item type
-func itemSimpleModFunc(d *schema.ResourceData, m interface{}, item *zabbix.Item) {
+func itemSimpleModFunc(d *schema.ResourceData, m interface{}, item *apiItem) {
 	item.Delay = d.Get("delay").(string)
 	item.Type = zabbix.SimpleCheck
 	item.InterfaceID = d.Get("interfaceid").(string)
 }
-func lldSimpleModFunc(d *schema.ResourceData, m interface{}, item *zabbix.LLDRule) {
+func lldSimpleModFunc(d *schema.ResourceData, m interface{}, item *apiLLDRule) {
 	item.Type = zabbix.SimpleCheck
 	item.InterfaceID = d.Get("interfaceid").(string)
 }
 
 // Custom read handler for item type
-func itemSimpleReadFunc(d *schema.ResourceData, m interface{}, item *zabbix.Item) {
+func itemSimpleReadFunc(d *schema.ResourceData, m interface{}, item *apiItem) {
 	d.Set("interfaceid", item.InterfaceID)
 	d.Set("delay", item.Delay)
 }
-func lldSimpleReadFunc(d *schema.ResourceData, m interface{}, item *zabbix.LLDRule) {
+func lldSimpleReadFunc(d *schema.ResourceData, m interface{}, item *apiLLDRule) {
 	d.Set("interfaceid", item.InterfaceID)
 }
@@ -1,8 +1,12 @@
 package provider
 
 import (
+	"errors"
+	"fmt"
+	"regexp"
 	"strings"
 
+	"github.com/hashicorp/terraform-plugin-sdk/helper/customdiff"
 	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
 	"github.com/hashicorp/terraform-plugin-sdk/helper/validation"
 	"github.com/tpretz/go-zabbix-api"
@@ -17,19 +21,36 @@ var SNMP_LOOKUP_REV = map[zabbix.ItemType]string{}
 var SNMP_LOOKUP_ARR = []string{}
 
 var SNMP_AUTHPROTO = map[string]string{
-	"md5": "0",
-	"sha": "1",
+	"md5":    "0",
+	"sha1":   "1",
+	"sha224": "2",
+	"sha256": "3",
+	"sha384": "4",
+	"sha512": "5",
 }
 var SNMP_AUTHPROTO_REV = map[string]string{}
 var SNMP_AUTHPROTO_ARR = []string{}
 
 var SNMP_PRIVPROTO = map[string]string{
-	"des": "0",
-	"aes": "1",
+	"des":     "0",
+	"aes128":  "1",
+	"aes192":  "2",
+	"aes256":  "3",
+	"aes192c": "4",
+	"aes256c": "5",
 }
 var SNMP_PRIVPROTO_REV = map[string]string{}
 var SNMP_PRIVPROTO_ARR = []string{}
 
+// legacy protocol names, accepted as aliases of their replacements
+var SNMP_PROTO_LEGACY = map[string]string{
+	"sha": "sha1",
+	"aes": "aes128",
+}
+
+// protocols understood by item level credentials (pre zabbix 5.0)
+var SNMP_PROTO_PRE50 = []string{"0", "1"}
+
 var SNMP_SECLEVEL = map[string]string{
 	"noauthnopriv": "0",
 	"authnopriv":   "1",
@@ -56,6 +77,17 @@ var _ = func() bool {
 		SNMP_SECLEVEL_REV[v] = k
 		SNMP_SECLEVEL_ARR = append(SNMP_SECLEVEL_ARR, k)
 	}
+	// aliases are accepted, but never read back
+	for k, v := range SNMP_PROTO_LEGACY {
+		if id, ok := SNMP_AUTHPROTO[v]; ok {
+			SNMP_AUTHPROTO[k] = id
+			SNMP_AUTHPROTO_ARR = append(SNMP_AUTHPROTO_ARR, k)
+		}
+		if id, ok := SNMP_PRIVPROTO[v]; ok {
+			SNMP_PRIVPROTO[k] = id
+			SNMP_PRIVPROTO_ARR = append(SNMP_PRIVPROTO_ARR, k)
+		}
+	}
 	return false
 }()
 
@@ -69,13 +101,14 @@ var schemaSnmp = map[string]*schema.Schema{
 	},
 	"snmp_oid": &schema.Schema{
 		Type:         schema.TypeString,
-		ValidateFunc: validation.StringIsNotWhiteSpace,
-		Description:  "SNMP OID",
+		ValidateFunc: validateSnmpOid,
+		Description:  "SNMP OID, or get[OID] / walk[OID,...] on zabbix 6.4+",
 		Required:     true,
 	},
 	"snmp_community": &schema.Schema{
 		Type:         schema.TypeString,
 		Optional:     true,
+		Sensitive:    true,
 		Description:  "SNMP Community (v1/v2 only)",
 		ValidateFunc: validation.StringIsNotWhiteSpace,
 		Default:      "{$SNMP_COMMUNITY}",
@@ -83,16 +116,18 @@ var schemaSnmp = map[string]*schema.Schema{
 	"snmp3_authpassphrase": &schema.Schema{
 		Type:         schema.TypeString,
 		Optional:     true,
+		Sensitive:    true,
 		Description:  "Authentication Passphrase (v3 only)",
 		ValidateFunc: validation.StringIsNotWhiteSpace,
 		Default:      "{$SNMP3_AUTHPASSPHRASE}",
 	},
 	"snmp3_authprotocol": &schema.Schema{
-		Type:         schema.TypeString,
-		Optional:     true,
-		Description:  "Authentication Protocol (v3 only), one of: " + strings.Join(SNMP_AUTHPROTO_ARR, ", "),
-		ValidateFunc: validation.StringInSlice(SNMP_AUTHPROTO_ARR, false),
-		Default:      "sha",
+		Type:             schema.TypeString,
+		Optional:         true,
+		Description:      "Authentication Protocol (v3 only), one of: " + strings.Join(SNMP_AUTHPROTO_ARR, ", "),
+		ValidateFunc:     validation.StringInSlice(SNMP_AUTHPROTO_ARR, false),
+		DiffSuppressFunc: snmpProtocolDiffSuppress,
+		Default:          "sha1",
 	},
 	"snmp3_contextname": &schema.Schema{
 		Type:         schema.TypeString,
@@ -104,16 +139,18 @@ var schemaSnmp = map[string]*schema.Schema{
 	"snmp3_privpassphrase": &schema.Schema{
 		Type:         schema.TypeString,
 		Optional:     true,
+		Sensitive:    true,
 		Description:  "Priv Passphrase (v3 only)",
 		ValidateFunc: validation.StringIsNotWhiteSpace,
 		Default:      "{$SNMP3_PRIVPASSPHRASE}",
 	},
 	"snmp3_privprotocol": &schema.Schema{
-		Type:         schema.TypeString,
-		Optional:     true,
-		Description:  "Priv Protocol (v3 only), one of: " + strings.Join(SNMP_PRIVPROTO_ARR, ", "),
-		ValidateFunc: validation.StringInSlice(SNMP_PRIVPROTO_ARR, false),
-		Default:      "aes",
+		Type:             schema.TypeString,
+		Optional:         true,
+		Description:      "Priv Protocol (v3 only), one of: " + strings.Join(SNMP_PRIVPROTO_ARR, ", "),
+		ValidateFunc:     validation.StringInSlice(SNMP_PRIVPROTO_ARR, false),
+		DiffSuppressFunc: snmpProtocolDiffSuppress,
+		Default:          "aes128",
 	},
 	"snmp3_securitylevel": &schema.Schema{
 		Type:         schema.TypeString,
@@ -139,9 +176,10 @@ func resourceItemSnmp() *schema.Resource {
 		Update: itemGetUpdateWrapper(itemSnmpModFunc, itemSnmpReadFunc),
 		Delete: resourceItemDelete,
 		Importer: &schema.ResourceImporter{
-			State: schema.ImportStatePassthrough,
+			State: itemImportState(false),
 		},
-		Schema: mergeSchemas(itemCommonSchema, itemDelaySchema, itemInterfaceSchema, schemaSnmp),
+		CustomizeDiff: customdiff.All(itemCustomizeDiff, itemTimeoutCustomizeDiff, snmpCustomizeDiff),
+		Schema:        mergeSchemas(itemCommonSchema, itemDelaySchema, itemInterfaceSchema, itemTimeoutSchema, schemaSnmp),
 	}
 }
 func resourceProtoItemSnmp() *schema.Resource {
@@ -151,9 +189,10 @@ func resourceProtoItemSnmp() *schema.Resource {
 		Update: protoItemGetUpdateWrapper(itemSnmpModFunc, itemSnmpReadFunc),
 		Delete: resourceProtoItemDelete,
 		Importer: &schema.ResourceImporter{
-			State: schema.ImportStatePassthrough,
+			State: itemImportState(true),
 		},
-		Schema: mergeSchemas(itemCommonSchema, itemDelaySchema, itemInterfaceSchema, itemPrototypeSchema, schemaSnmp),
+		CustomizeDiff: customdiff.All(itemCustomizeDiff, itemTimeoutCustomizeDiff, snmpCustomizeDiff),
+		Schema:        mergeSchemas(itemCommonSchema, itemDelaySchema, itemInterfaceSchema, itemTimeoutSchema, itemPrototypeSchema, schemaSnmp),
 	}
 }
 
@@ -164,15 +203,131 @@ func resourceLLDSnmp() *schema.Resource {
 		Update: lldGetUpdateWrapper(lldSnmpModFunc, lldSnmpReadFunc),
 		Delete: resourceLLDDelete,
 		Importer: &schema.ResourceImporter{
-			State: schema.ImportStatePassthrough,
+			State: lldImportState(false),
 		},
-		Schema: mergeSchemas(lldCommonSchema, lldInterfaceSchema, schemaSnmp),
+		CustomizeDiff: customdiff.All(lldCustomizeDiff, snmpCustomizeDiff),
+		Schema:        mergeSchemas(lldCommonSchema, lldInterfaceSchema, schemaSnmp),
+	}
+}
+func resourceProtoLLDSnmp() *schema.Resource {
+	return &schema.Resource{
+		Create: protoLLDGetCreateWrapper(lldSnmpModFunc, lldSnmpReadFunc),
+		Read:   protoLLDGetReadWrapper(lldSnmpReadFunc),
+		Update: protoLLDGetUpdateWrapper(lldSnmpModFunc, lldSnmpReadFunc),
+		Delete: resourceProtoLLDDelete,
+		Importer: &schema.ResourceImporter{
+			State: lldImportState(true),
+		},
+		CustomizeDiff: customdiff.All(lldCustomizeDiff, snmpCustomizeDiff),
+		Schema:        mergeSchemas(lldCommonSchema, lldInterfaceSchema, schemaSnmp, lldPrototypeSchema),
+	}
+}
+
+// snmpProtocolDiffSuppress treat legacy protocol names as equal to their replacements
+func snmpProtocolDiffSuppress(k, old, new string, d *schema.ResourceData) bool {
+	if v, ok := SNMP_PROTO_LEGACY[old]; ok {
+		old = v
+	}
+	if v, ok := SNMP_PROTO_LEGACY[new]; ok {
+		new = v
+	}
+	return old == new
+}
+
+// snmp_oid get[] / walk[] expressions (zabbix 6.4+)
+var snmpOidExpression = regexp.MustCompile(`^(get|walk)\[(.*)\]$`)
+var snmpOidElement = regexp.MustCompile(`^[A-Za-z0-9._:{}$#-]+$`)
+
+// validateSnmpOid check the structure of get[] / walk[] OID expressions
+func validateSnmpOid(i interface{}, k string) (warnings []string, errors []error) {
+	v := i.(string)
+
+	if strings.TrimSpace(v) == "" {
+		errors = append(errors, fmt.Errorf("expected %q to not be an empty string or whitespace", k))
+		return
+	}
+
+	// plain OIDs and legacy discovery[] keys are passed through as is
+	if !strings.HasPrefix(v, "get[") && !strings.HasPrefix(v, "walk[") {
+		return
+	}
+
+	parts := snmpOidExpression.FindStringSubmatch(v)
+	if parts == nil {
+		errors = append(errors, fmt.Errorf("%q: %s must end with ]", k, v))
+		return
+	}
+
+	oids := strings.Split(parts[2], ",")
+	if parts[1] == "get" && len(oids) != 1 {
+		errors = append(errors, fmt.Errorf("%q: get[] takes exactly one OID, got %d", k, len(oids)))
+	}
+	for _, oid := range oids {
+		if !snmpOidElement.MatchString(strings.TrimSpace(oid)) {
+			errors = append(errors, fmt.Errorf("%q: invalid OID %q in %s[]", k, oid, parts[1]))
+		}
+	}
+	return
+}
+
+// item level credentials, removed from items in zabbix 5.0
+var snmpCredentialKeys = []string{
+	"snmp_community",
+	"snmp3_authpassphrase",
+	"snmp3_authprotocol",
+	"snmp3_contextname",
+	"snmp3_privpassphrase",
+	"snmp3_privprotocol",
+	"snmp3_securitylevel",
+	"snmp3_securityname",
+}
+
+// snmpCustomizeDiff check configuration against features of the server version
+func snmpCustomizeDiff(d *schema.ResourceDiff, m interface{}) error {
+	api := metaAPI(m)
+
+	if snmpOidExpression.MatchString(d.Get("snmp_oid").(string)) && api.Config.Version < 60400 {
+		return errors.New("snmp_oid get[] and walk[] expressions require zabbix 6.4 or later")
+	}
+
+	// credentials live on the interface from 5.0, the server would never receive item values
+	if api.Config.Version >= 50000 {
+		for _, k := range snmpCredentialKeys {
+			v := d.Get(k).(string)
+			if !snmpProtocolDiffSuppress(k, v, schemaSnmp[k].Default.(string), nil) {
+				return fmt.Errorf("%s is not supported on items from zabbix 5.0, configure it on the host interface", k)
+			}
+		}
+		return nil
+	}
+
+	if d.Get("snmp_version").(string) != "3" {
+		return nil
+	}
+
+	// reject v3 protocols the server cannot store against an item
+	checks := map[string]map[string]string{
+		"snmp3_authprotocol": SNMP_AUTHPROTO,
+		"snmp3_privprotocol": SNMP_PRIVPROTO,
+	}
+	for k, lookup := range checks {
+		v := d.Get(k).(string)
+		supported := false
+		for _, id := range SNMP_PROTO_PRE50 {
+			if lookup[v] == id {
+				supported = true
+			}
+		}
+		if !supported {
+			return fmt.Errorf("%s %s requires zabbix 5.0 or later", k, v)
+		}
 	}
+	return nil
 }
 
 // Custom mod handler for item type
-func itemSnmpModFunc(d *schema.ResourceData, m interface{}, item *zabbix.Item) {
-	api := m.(*zabbix.API)
+func itemSnmpModFunc(d *schema.ResourceData, m interface{}, item *apiItem) {
+	api := metaAPI(m)
 	item.InterfaceID = d.Get("interfaceid").(string)
 	item.Delay = d.Get("delay").(string)
 
@@ -199,8 +354,8 @@ func itemSnmpModFunc(d *schema.ResourceData, m interface{}, item *zabbix.Item) {
 }
 
 // Also for LLD Discovery SNMP
-func lldSnmpModFunc(d *schema.ResourceData, m interface{}, item *zabbix.LLDRule) {
-	api := m.(*zabbix.API)
+func lldSnmpModFunc(d *schema.ResourceData, m interface{}, item *apiLLDRule) {
+	api := metaAPI(m)
 	item.InterfaceID = d.Get("interfaceid").(string)
 
 	item.SNMPOid = d.Get("snmp_oid").(string)
@@ -225,8 +380,8 @@ func lldSnmpModFunc(d *schema.ResourceData, m interface{}, item *zabbix.LLDRule)
 }
 
 // Custom read handler for item type
-func itemSnmpReadFunc(d *schema.ResourceData, m interface{}, item *zabbix.Item) {
-	api := m.(*zabbix.API)
+func itemSnmpReadFunc(d *schema.ResourceData, m interface{}, item *apiItem) {
+	api := metaAPI(m)
 	d.Set("interfaceid", item.InterfaceID)
 	d.Set("delay", item.Delay)
 
@@ -250,8 +405,8 @@ func itemSnmpReadFunc(d *schema.ResourceData, m interface{}, item *zabbix.Item)
 }
 
 // Also for LLD Discovery SNMP
-func lldSnmpReadFunc(d *schema.ResourceData, m interface{}, item *zabbix.LLDRule) {
-	api := m.(*zabbix.API)
+func lldSnmpReadFunc(d *schema.ResourceData, m interface{}, item *apiLLDRule) {
+	api := metaAPI(m)
 	d.Set("interfaceid", item.InterfaceID)
 
 	d.Set("snmp_oid", item.SNMPOid)
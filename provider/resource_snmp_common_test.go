@@ -0,0 +1,65 @@
+package provider
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/terraform"
+	"github.com/tpretz/go-zabbix-api"
+)
+
+func TestValidateSnmpOid(t *testing.T) {
+	cases := map[string]bool{
+		".1.3.6.1.2.1.1.1.0":                          true,
+		"IF-MIB::ifDescr.{#SNMPINDEX}":                true,
+		"discovery[{#IFDESCR},1.3.6.1.2.1.2.2.1.2]":   true,
+		"get[1.3.6.1.2.1.1.1.0]":                      true,
+		"walk[1.3.6.1.2.1.2.2.1.2,1.3.6.1.2.1.2.2.1]": true,
+		"walk[IF-MIB::ifDescr, IF-MIB::ifType]":       true,
+		"get[1.3.6.1.2.1.1.1.0,1.3.6.1.2.1.1.5.0]":    false,
+		"walk[]":                 false,
+		"walk[1.3.6.1,,1.3.6.2]": false,
+		"get[1.3.6.1.2.1.1.1.0":  false,
+		"walk[1.3.6.1.2 [1]]":    false,
+		" ":                      false,
+	}
+
+	for v, valid := range cases {
+		_, errs := validateSnmpOid(v, "snmp_oid")
+		if valid && len(errs) > 0 {
+			t.Errorf("%q: expected valid, got %v", v, errs)
+		}
+		if !valid && len(errs) == 0 {
+			t.Errorf("%q: expected error", v)
+		}
+	}
+}
+
+func TestSnmpCustomizeDiffCredentials(t *testing.T) {
+	cases := []struct {
+		version int
+		extra   map[string]interface{}
+		err     string
+	}{
+		{50000, map[string]interface{}{}, ""},
+		{50000, map[string]interface{}{"snmp_community": "{$SNMP_COMMUNITY}"}, ""},
+		{50000, map[string]interface{}{"snmp_community": "public"}, "snmp_community is not supported on items from zabbix 5.0"},
+		{60400, map[string]interface{}{"snmp3_privprotocol": "des"}, "snmp3_privprotocol is not supported on items from zabbix 5.0"},
+		{40400, map[string]interface{}{"snmp_community": "public"}, ""},
+	}
+
+	for i, tc := range cases {
+		api := &zabbix.API{Config: zabbix.Config{Version: tc.version}}
+		config := map[string]interface{}{"hostid": "1", "key": "k", "name": "item", "interfaceid": "1", "snmp_oid": ".1.3.6.1.2.1.1.1.0"}
+		for k, v := range tc.extra {
+			config[k] = v
+		}
+		_, err := resourceItemSnmp().Diff(nil, terraform.NewResourceConfigRaw(config), api)
+		switch {
+		case tc.err == "" && err != nil:
+			t.Errorf("case %d: unexpected error %s", i, err)
+		case tc.err != "" && (err == nil || !strings.Contains(err.Error(), tc.err)):
+			t.Errorf("case %d: expected error %q, got %v", i, tc.err, err)
+		}
+	}
+}
@@ -13,8 +13,9 @@ func resourceItemSnmpTrap() *schema.Resource {
 		Update: itemGetUpdateWrapper(itemSnmpTrapModFunc, itemSnmpTrapReadFunc),
 		Delete: resourceItemDelete,
 		Importer: &schema.ResourceImporter{
-			State: schema.ImportStatePassthrough,
+			State: itemImportState(false),
 		},
+		CustomizeDiff: itemCustomizeDiff,
 
 		Schema: itemCommonSchema,
 	}
@@ -26,18 +27,19 @@ func resourceProtoItemSnmpTrap() *schema.Resource {
 		Update: protoItemGetUpdateWrapper(itemSnmpTrapModFunc, itemSnmpTrapReadFunc),
 		Delete: resourceProtoItemDelete,
 		Importer: &schema.ResourceImporter{
-			State: schema.ImportStatePassthrough,
+			State: itemImportState(true),
 		},
+		CustomizeDiff: itemCustomizeDiff,
 
 		Schema: mergeSchemas(itemCommonSchema, itemPrototypeSchema),
 	}
 }
 
 // Custom mod handler for item type
-func itemSnmpTrapModFunc(d *schema.ResourceData, m interface{}, item *zabbix.Item) {
+func itemSnmpTrapModFunc(d *schema.ResourceData, m interface{}, item *apiItem) {
 	item.Type = zabbix.SNMPTrap
 }
 
 // Custom read handler for item type
-func itemSnmpTrapReadFunc(d *schema.ResourceData, m interface{}, item *zabbix.Item) {
+func itemSnmpTrapReadFunc(d *schema.ResourceData, m interface{}, item *apiItem) {
 }
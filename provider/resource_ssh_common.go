@@ -0,0 +1,166 @@
+package provider
+
+import (
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/helper/customdiff"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/validation"
+	"github.com/tpretz/go-zabbix-api"
+)
+
+var SSH_AUTHTYPE = map[string]string{
+	"password":  "0",
+	"publickey": "1",
+}
+var SSH_AUTHTYPE_REV = map[string]string{}
+var SSH_AUTHTYPE_ARR = []string{}
+
+// generate the above structures
+var _ = func() bool {
+	for k, v := range SSH_AUTHTYPE {
+		SSH_AUTHTYPE_REV[v] = k
+		SSH_AUTHTYPE_ARR = append(SSH_AUTHTYPE_ARR, k)
+	}
+	return false
+}()
+
+var schemaSsh = map[string]*schema.Schema{
+	"auth_type": &schema.Schema{
+		Type:         schema.TypeString,
+		Optional:     true,
+		Description:  "SSH auth type, one of: " + strings.Join(SSH_AUTHTYPE_ARR, ", "),
+		ValidateFunc: validation.StringInSlice(SSH_AUTHTYPE_ARR, false),
+		Default:      "password",
+	},
+	"username": &schema.Schema{
+		Type:         schema.TypeString,
+		Required:     true,
+		Description:  "Authentication Username",
+		ValidateFunc: validation.StringIsNotWhiteSpace,
+	},
+	"password": &schema.Schema{
+		Type:        schema.TypeString,
+		Optional:    true,
+		Sensitive:   true,
+		Description: "Authentication Password, or key passphrase with publickey auth",
+	},
+	"publickey": &schema.Schema{
+		Type:        schema.TypeString,
+		Optional:    true,
+		Description: "Public key file name (publickey auth only)",
+	},
+	"privatekey": &schema.Schema{
+		Type:        schema.TypeString,
+		Optional:    true,
+		Description: "Private key file name (publickey auth only)",
+	},
+	"script": &schema.Schema{
+		Type:         schema.TypeString,
+		Required:     true,
+		Description:  "Script to execute",
+		ValidateFunc: validation.StringIsNotWhiteSpace,
+	},
+}
+
+// terraform resource handler for item type
+func resourceItemSsh() *schema.Resource {
+	return &schema.Resource{
+		Create: itemGetCreateWrapper(itemSshModFunc, itemSshReadFunc),
+		Read:   itemGetReadWrapper(itemSshReadFunc),
+		Update: itemGetUpdateWrapper(itemSshModFunc, itemSshReadFunc),
+		Delete: resourceItemDelete,
+		Importer: &schema.ResourceImporter{
+			State: itemImportState(false),
+		},
+		CustomizeDiff: customdiff.All(itemCustomizeDiff, itemTimeoutCustomizeDiff),
+
+		Schema: mergeSchemas(itemCommonSchema, itemDelaySchema, itemInterfaceSchema, itemTimeoutSchema, schemaSsh),
+	}
+}
+func resourceProtoItemSsh() *schema.Resource {
+	return &schema.Resource{
+		Create: protoItemGetCreateWrapper(itemSshModFunc, itemSshReadFunc),
+		Read:   protoItemGetReadWrapper(itemSshReadFunc),
+		Update: protoItemGetUpdateWrapper(itemSshModFunc, itemSshReadFunc),
+		Delete: resourceProtoItemDelete,
+		Importer: &schema.ResourceImporter{
+			State: itemImportState(true),
+		},
+		CustomizeDiff: customdiff.All(itemCustomizeDiff, itemTimeoutCustomizeDiff),
+
+		Schema: mergeSchemas(itemCommonSchema, itemDelaySchema, itemInterfaceSchema, itemTimeoutSchema, itemPrototypeSchema, schemaSsh),
+	}
+}
+func resourceLLDSsh() *schema.Resource {
+	return &schema.Resource{
+		Create: lldGetCreateWrapper(lldSshModFunc, lldSshReadFunc),
+		Read:   lldGetReadWrapper(lldSshReadFunc),
+		Update: lldGetUpdateWrapper(lldSshModFunc, lldSshReadFunc),
+		Delete: resourceLLDDelete,
+		Importer: &schema.ResourceImporter{
+			State: lldImportState(false),
+		},
+		CustomizeDiff: lldCustomizeDiff,
+
+		Schema: mergeSchemas(lldCommonSchema, lldInterfaceSchema, schemaSsh),
+	}
+}
+func resourceProtoLLDSsh() *schema.Resource {
+	return &schema.Resource{
+		Create: protoLLDGetCreateWrapper(lldSshModFunc, lldSshReadFunc),
+		Read:   protoLLDGetReadWrapper(lldSshReadFunc),
+		Update: protoLLDGetUpdateWrapper(lldSshModFunc, lldSshReadFunc),
+		Delete: resourceProtoLLDDelete,
+		Importer: &schema.ResourceImporter{
+			State: lldImportState(true),
+		},
+		CustomizeDiff: lldCustomizeDiff,
+
+		Schema: mergeSchemas(lldCommonSchema, lldInterfaceSchema, schemaSsh, lldPrototypeSchema),
+	}
+}
+
+// Custom mod handler for item type
+func itemSshModFunc(d *schema.ResourceData, m interface{}, item *apiItem) {
+	item.Type = zabbix.SSHAgent
+	item.Delay = d.Get("delay").(string)
+	item.InterfaceID = d.Get("interfaceid").(string)
+	item.AuthType = SSH_AUTHTYPE[d.Get("auth_type").(string)]
+	item.Username = d.Get("username").(string)
+	item.Password = d.Get("password").(string)
+	item.PublicKey = d.Get("publickey").(string)
+	item.PrivateKey = d.Get("privatekey").(string)
+	item.Params = d.Get("script").(string)
+}
+func lldSshModFunc(d *schema.ResourceData, m interface{}, item *apiLLDRule) {
+	item.Type = zabbix.SSHAgent
+	item.InterfaceID = d.Get("interfaceid").(string)
+	item.AuthType = SSH_AUTHTYPE[d.Get("auth_type").(string)]
+	item.Username = d.Get("username").(string)
+	item.Password = d.Get("password").(string)
+	item.PublicKey = d.Get("publickey").(string)
+	item.PrivateKey = d.Get("privatekey").(string)
+	item.Params = d.Get("script").(string)
+}
+
+// Custom read handler for item type
+func itemSshReadFunc(d *schema.ResourceData, m interface{}, item *apiItem) {
+	d.Set("delay", item.Delay)
+	d.Set("interfaceid", item.InterfaceID)
+	d.Set("auth_type", SSH_AUTHTYPE_REV[item.AuthType])
+	d.Set("username", item.Username)
+	d.Set("password", item.Password)
+	d.Set("publickey", item.PublicKey)
+	d.Set("privatekey", item.PrivateKey)
+	d.Set("script", item.Params)
+}
+func lldSshReadFunc(d *schema.ResourceData, m interface{}, item *apiLLDRule) {
+	d.Set("interfaceid", item.InterfaceID)
+	d.Set("auth_type", SSH_AUTHTYPE_REV[item.AuthType])
+	d.Set("username", item.Username)
+	d.Set("password", item.Password)
+	d.Set("publickey", item.PublicKey)
+	d.Set("privatekey", item.PrivateKey)
+	d.Set("script", item.Params)
+}
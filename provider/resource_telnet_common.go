@@ -0,0 +1,119 @@
+package provider
+
+import (
+	"github.com/hashicorp/terraform-plugin-sdk/helper/customdiff"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/validation"
+	"github.com/tpretz/go-zabbix-api"
+)
+
+var schemaTelnet = map[string]*schema.Schema{
+	"username": &schema.Schema{
+		Type:         schema.TypeString,
+		Required:     true,
+		Description:  "Authentication Username",
+		ValidateFunc: validation.StringIsNotWhiteSpace,
+	},
+	"password": &schema.Schema{
+		Type:        schema.TypeString,
+		Optional:    true,
+		Sensitive:   true,
+		Description: "Authentication Password",
+	},
+	"script": &schema.Schema{
+		Type:         schema.TypeString,
+		Required:     true,
+		Description:  "Script to execute",
+		ValidateFunc: validation.StringIsNotWhiteSpace,
+	},
+}
+
+// terraform resource handler for item type
+func resourceItemTelnet() *schema.Resource {
+	return &schema.Resource{
+		Create: itemGetCreateWrapper(itemTelnetModFunc, itemTelnetReadFunc),
+		Read:   itemGetReadWrapper(itemTelnetReadFunc),
+		Update: itemGetUpdateWrapper(itemTelnetModFunc, itemTelnetReadFunc),
+		Delete: resourceItemDelete,
+		Importer: &schema.ResourceImporter{
+			State: itemImportState(false),
+		},
+		CustomizeDiff: customdiff.All(itemCustomizeDiff, itemTimeoutCustomizeDiff),
+
+		Schema: mergeSchemas(itemCommonSchema, itemDelaySchema, itemInterfaceSchema, itemTimeoutSchema, schemaTelnet),
+	}
+}
+func resourceProtoItemTelnet() *schema.Resource {
+	return &schema.Resource{
+		Create: protoItemGetCreateWrapper(itemTelnetModFunc, itemTelnetReadFunc),
+		Read:   protoItemGetReadWrapper(itemTelnetReadFunc),
+		Update: protoItemGetUpdateWrapper(itemTelnetModFunc, itemTelnetReadFunc),
+		Delete: resourceProtoItemDelete,
+		Importer: &schema.ResourceImporter{
+			State: itemImportState(true),
+		},
+		CustomizeDiff: customdiff.All(itemCustomizeDiff, itemTimeoutCustomizeDiff),
+
+		Schema: mergeSchemas(itemCommonSchema, itemDelaySchema, itemInterfaceSchema, itemTimeoutSchema, itemPrototypeSchema, schemaTelnet),
+	}
+}
+func resourceLLDTelnet() *schema.Resource {
+	return &schema.Resource{
+		Create: lldGetCreateWrapper(lldTelnetModFunc, lldTelnetReadFunc),
+		Read:   lldGetReadWrapper(lldTelnetReadFunc),
+		Update: lldGetUpdateWrapper(lldTelnetModFunc, lldTelnetReadFunc),
+		Delete: resourceLLDDelete,
+		Importer: &schema.ResourceImporter{
+			State: lldImportState(false),
+		},
+		CustomizeDiff: lldCustomizeDiff,
+
+		Schema: mergeSchemas(lldCommonSchema, lldInterfaceSchema, schemaTelnet),
+	}
+}
+func resourceProtoLLDTelnet() *schema.Resource {
+	return &schema.Resource{
+		Create: protoLLDGetCreateWrapper(lldTelnetModFunc, lldTelnetReadFunc),
+		Read:   protoLLDGetReadWrapper(lldTelnetReadFunc),
+		Update: protoLLDGetUpdateWrapper(lldTelnetModFunc, lldTelnetReadFunc),
+		Delete: resourceProtoLLDDelete,
+		Importer: &schema.ResourceImporter{
+			State: lldImportState(true),
+		},
+		CustomizeDiff: lldCustomizeDiff,
+
+		Schema: mergeSchemas(lldCommonSchema, lldInterfaceSchema, schemaTelnet, lldPrototypeSchema),
+	}
+}
+
+// Custom mod handler for item type
+func itemTelnetModFunc(d *schema.ResourceData, m interface{}, item *apiItem) {
+	item.Type = zabbix.TELNETAgent
+	item.Delay = d.Get("delay").(string)
+	item.InterfaceID = d.Get("interfaceid").(string)
+	item.Username = d.Get("username").(string)
+	item.Password = d.Get("password").(string)
+	item.Params = d.Get("script").(string)
+}
+func lldTelnetModFunc(d *schema.ResourceData, m interface{}, item *apiLLDRule) {
+	item.Type = zabbix.TELNETAgent
+	item.InterfaceID = d.Get("interfaceid").(string)
+	item.Username = d.Get("username").(string)
+	item.Password = d.Get("password").(string)
+	item.Params = d.Get("script").(string)
+}
+
+// Custom read handler for item type
+func itemTelnetReadFunc(d *schema.ResourceData, m interface{}, item *apiItem) {
+	d.Set("delay", item.Delay)
+	d.Set("interfaceid", item.InterfaceID)
+	d.Set("username", item.Username)
+	d.Set("password", item.Password)
+	d.Set("script", item.Params)
+}
+func lldTelnetReadFunc(d *schema.ResourceData, m interface{}, item *apiLLDRule) {
+	d.Set("interfaceid", item.InterfaceID)
+	d.Set("username", item.Username)
+	d.Set("password", item.Password)
+	d.Set("script", item.Params)
+}
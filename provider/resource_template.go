@@ -17,7 +17,7 @@ func resourceTemplate() *schema.Resource {
 		Update: resourceTemplateUpdate,
 		Delete: resourceTemplateDelete,
 		Importer: &schema.ResourceImporter{
-			State: schema.ImportStatePassthrough,
+			State: templateImportState(),
 		},
 
 		Schema: map[string]*schema.Schema{
@@ -97,7 +97,7 @@ func dataTemplate() *schema.Resource {
 
 // terraform resource create handler
 func resourceTemplateCreate(d *schema.ResourceData, m interface{}) error {
-	api := m.(*zabbix.API)
+	api := metaAPI(m)
 
 	item := buildTemplateObject(d)
 	items := []zabbix.Template{*item}
@@ -155,7 +155,7 @@ func resourceTemplateRead(d *schema.ResourceData, m interface{}) error {
 
 // generic template read function
 func templateRead(d *schema.ResourceData, m interface{}, params zabbix.Params) error {
-	api := m.(*zabbix.API)
+	api := metaAPI(m)
 
 	templates, err := api.TemplatesGet(params)
 
@@ -201,7 +201,7 @@ func buildTemplateObject(d *schema.ResourceData) *zabbix.Template {
 
 // terraform update resource handler
 func resourceTemplateUpdate(d *schema.ResourceData, m interface{}) error {
-	api := m.(*zabbix.API)
+	api := metaAPI(m)
 
 	item := buildTemplateObject(d)
 	item.TemplateID = d.Id()
@@ -230,6 +230,6 @@ func resourceTemplateUpdate(d *schema.ResourceData, m interface{}) error {
 
 // terraform delete handler
 func resourceTemplateDelete(d *schema.ResourceData, m interface{}) error {
-	api := m.(*zabbix.API)
+	api := metaAPI(m)
 	return api.TemplatesDeleteByIds([]string{d.Id()})
 }
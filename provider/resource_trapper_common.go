@@ -13,8 +13,9 @@ func resourceItemTrapper() *schema.Resource {
 		Update: itemGetUpdateWrapper(itemTrapperModFunc, itemTrapperReadFunc),
 		Delete: resourceItemDelete,
 		Importer: &schema.ResourceImporter{
-			State: schema.ImportStatePassthrough,
+			State: itemImportState(false),
 		},
+		CustomizeDiff: itemCustomizeDiff,
 
 		Schema: itemCommonSchema,
 	}
@@ -26,8 +27,9 @@ func resourceProtoItemTrapper() *schema.Resource {
 		Update: protoItemGetUpdateWrapper(itemTrapperModFunc, itemTrapperReadFunc),
 		Delete: resourceProtoItemDelete,
 		Importer: &schema.ResourceImporter{
-			State: schema.ImportStatePassthrough,
+			State: itemImportState(true),
 		},
+		CustomizeDiff: itemCustomizeDiff,
 
 		Schema: mergeSchemas(itemCommonSchema, itemPrototypeSchema),
 	}
@@ -39,23 +41,38 @@ func resourceLLDTrapper() *schema.Resource {
 		Update: lldGetUpdateWrapper(lldTrapperModFunc, lldTrapperReadFunc),
 		Delete: resourceLLDDelete,
 		Importer: &schema.ResourceImporter{
-			State: schema.ImportStatePassthrough,
+			State: lldImportState(false),
 		},
+		CustomizeDiff: lldCustomizeDiff,
 
 		Schema: lldCommonSchema,
 	}
 }
+func resourceProtoLLDTrapper() *schema.Resource {
+	return &schema.Resource{
+		Create: protoLLDGetCreateWrapper(lldTrapperModFunc, lldTrapperReadFunc),
+		Read:   protoLLDGetReadWrapper(lldTrapperReadFunc),
+		Update: protoLLDGetUpdateWrapper(lldTrapperModFunc, lldTrapperReadFunc),
+		Delete: resourceProtoLLDDelete,
+		Importer: &schema.ResourceImporter{
+			State: lldImportState(true),
+		},
+		CustomizeDiff: lldCustomizeDiff,
+
+		Schema: mergeSchemas(lldCommonSchema, lldPrototypeSchema),
+	}
+}
 
 // Custom mod handler for item type
-func itemTrapperModFunc(d *schema.ResourceData, m interface{}, item *zabbix.Item) {
+func itemTrapperModFunc(d *schema.ResourceData, m interface{}, item *apiItem) {
 	item.Type = zabbix.ZabbixTrapper
 }
-func lldTrapperModFunc(d *schema.ResourceData, m interface{}, item *zabbix.LLDRule) {
+func lldTrapperModFunc(d *schema.ResourceData, m interface{}, item *apiLLDRule) {
 	item.Type = zabbix.ZabbixTrapper
 }
 
 // Custom read handler for item type
-func itemTrapperReadFunc(d *schema.ResourceData, m interface{}, item *zabbix.Item) {
+func itemTrapperReadFunc(d *schema.ResourceData, m interface{}, item *apiItem) {
 }
-func lldTrapperReadFunc(d *schema.ResourceData, m interface{}, item *zabbix.LLDRule) {
+func lldTrapperReadFunc(d *schema.ResourceData, m interface{}, item *apiLLDRule) {
 }
@@ -2,6 +2,7 @@ package provider
 
 import (
 	"errors"
+	"fmt"
 	"regexp"
 	"strings"
 
@@ -36,17 +37,38 @@ var schemaTrigger = map[string]*schema.Schema{
 		Type:         schema.TypeString,
 		Required:     true,
 		ValidateFunc: validation.StringIsNotWhiteSpace,
-		Description:  "Trigger name",
+		Description:  "Trigger name (api description)",
 	},
 	"expression": &schema.Schema{
+		Type:             schema.TypeString,
+		ValidateFunc:     validation.StringIsNotWhiteSpace,
+		Description:      "Trigger Expression",
+		Optional:         true,
+		Computed:         true,
+		ExactlyOneOf:     []string{"expression", "condition"},
+		DiffSuppressFunc: expressionDiffSuppress,
+	},
+	"condition": triggerConditionSchema,
+	"condition_operator": &schema.Schema{
 		Type:         schema.TypeString,
-		ValidateFunc: validation.StringIsNotWhiteSpace,
-		Description:  "Trigger Expression",
-		Required:     true,
+		Optional:     true,
+		Default:      "and",
+		ValidateFunc: validation.StringInSlice(TRIGGER_CONDITION_LOGIC, false),
+		Description:  "Operator joining conditions, one of: " + strings.Join(TRIGGER_CONDITION_LOGIC, ", "),
 	},
 	"comments": &schema.Schema{
 		Type:        schema.TypeString,
-		Description: "Trigger comments",
+		Description: "Trigger description (api comments)",
+		Optional:    true,
+	},
+	"event_name": &schema.Schema{
+		Type:        schema.TypeString,
+		Description: "Event name, used in problems and alerts instead of the trigger name, zabbix 5.2+",
+		Optional:    true,
+	},
+	"opdata": &schema.Schema{
+		Type:        schema.TypeString,
+		Description: "Operational data, zabbix 5.0+",
 		Optional:    true,
 	},
 	"priority": &schema.Schema{
@@ -74,6 +96,11 @@ var schemaTrigger = map[string]*schema.Schema{
 		Description:  "link to url relevent to trigger",
 		ValidateFunc: validation.IsURLWithHTTPorHTTPS,
 	},
+	"url_name": &schema.Schema{
+		Type:        schema.TypeString,
+		Optional:    true,
+		Description: "label for the url, zabbix 6.4+",
+	},
 	"recovery_none": &schema.Schema{
 		Type:        schema.TypeBool,
 		Optional:    true,
@@ -81,9 +108,10 @@ var schemaTrigger = map[string]*schema.Schema{
 		Description: "set recovery mode to none",
 	},
 	"recovery_expression": &schema.Schema{
-		Type:        schema.TypeString,
-		Optional:    true,
-		Description: "use recovery expression (recovery_none must not be true)",
+		Type:             schema.TypeString,
+		Optional:         true,
+		Description:      "use recovery expression (recovery_none must not be true)",
+		DiffSuppressFunc: expressionDiffSuppress,
 	},
 	"correlation_tag": &schema.Schema{
 		Type:        schema.TypeString,
@@ -99,11 +127,12 @@ var schemaTrigger = map[string]*schema.Schema{
 	"dependencies": &schema.Schema{
 		Type:     schema.TypeSet,
 		Optional: true,
+		Computed: true,
 		Elem: &schema.Schema{
 			Type:         schema.TypeString,
 			ValidateFunc: validation.StringMatch(regexp.MustCompile("^[0-9]+$"), "must be a numeric string"),
 		},
-		Description: "Trigger Dependencies",
+		Description: "Trigger Dependencies, checked at plan time, left alone when unset so zabbix_trigger_dependency can manage them",
 	},
 	"tag": &schema.Schema{
 		Type:     schema.TypeSet,
@@ -126,6 +155,16 @@ var schemaTrigger = map[string]*schema.Schema{
 	},
 }
 
+// Prototype schema
+var triggerPrototypeSchema = map[string]*schema.Schema{
+	"discover": &schema.Schema{
+		Type:        schema.TypeBool,
+		Optional:    true,
+		Default:     true,
+		Description: "Discover triggers from this prototype, zabbix 5.0+",
+	},
+}
+
 // terraform resource handler for triggers
 func resourceTrigger() *schema.Resource {
 	return &schema.Resource{
@@ -134,8 +173,9 @@ func resourceTrigger() *schema.Resource {
 		Update: resourceTriggerUpdate(false),
 		Delete: resourceTriggerDelete(false),
 		Importer: &schema.ResourceImporter{
-			State: schema.ImportStatePassthrough,
+			State: triggerImportState(false),
 		},
+		CustomizeDiff: triggerCustomizeDiff,
 
 		Schema: schemaTrigger,
 	}
@@ -147,18 +187,38 @@ func resourceProtoTrigger() *schema.Resource {
 		Update: resourceTriggerUpdate(true),
 		Delete: resourceTriggerDelete(true),
 		Importer: &schema.ResourceImporter{
-			State: schema.ImportStatePassthrough,
+			State: triggerImportState(true),
 		},
+		CustomizeDiff: triggerCustomizeDiff,
 
-		Schema: schemaTrigger,
+		Schema: mergeSchemas(schemaTrigger, triggerPrototypeSchema),
 	}
 }
 
+// apiTrigger api trigger, extended with attributes not handled by the api library,
+// pointers so they are only sent to versions supporting them, and may be cleared
+type apiTrigger struct {
+	zabbix.Trigger
+
+	OpData    *string `json:"opdata,omitempty"`
+	EventName *string `json:"event_name,omitempty"`
+	UrlName   *string `json:"url_name,omitempty"`
+	Discover  *string `json:"discover,omitempty"`
+
+	// replaces the library field, only sent when changed
+	Dependencies *zabbix.TriggerIDs `json:"dependencies,omitempty"`
+}
+
+type apiTriggers []apiTrigger
+
 // Build Trigger struct for create/modify
-func buildTriggerObject(d *schema.ResourceData) zabbix.Trigger {
+func buildTriggerObject(d *schema.ResourceData, m interface{}, prototype bool) apiTrigger {
+	api := metaAPI(m)
+	normalize := metaNormalizeExpressions(m)
+
 	item := zabbix.Trigger{
 		Description:        d.Get("name").(string),
-		Expression:         d.Get("expression").(string),
+		Expression:         triggerExpression(d.Get("expression").(string), api, normalize),
 		Comments:           d.Get("comments").(string),
 		Priority:           TRIGGER_PRIORITY[d.Get("priority").(string)],
 		Status:             0,
@@ -182,7 +242,7 @@ func buildTriggerObject(d *schema.ResourceData) zabbix.Trigger {
 		item.RecoveryMode = 2
 	} else if v := d.Get("recovery_expression").(string); v != "" {
 		item.RecoveryMode = 1
-		item.RecoveryExpression = v
+		item.RecoveryExpression = triggerExpression(v, api, normalize)
 	}
 
 	if v := d.Get("correlation_tag").(string); v != "" {
@@ -194,28 +254,80 @@ func buildTriggerObject(d *schema.ResourceData) zabbix.Trigger {
 		item.ManualClose = 1
 	}
 
-	item.Dependencies = buildTriggerIds(d.Get("dependencies").(*schema.Set))
 	item.Tags = tagGenerate(d)
 
-	return item
+	trigger := apiTrigger{Trigger: item}
+	// unset dependencies are computed and may be managed by zabbix_trigger_dependency, leave them alone
+	if d.HasChange("dependencies") {
+		dependencies := buildTriggerIds(d.Get("dependencies").(*schema.Set))
+		trigger.Dependencies = &dependencies
+	}
+	if api.Config.Version >= 50000 {
+		opdata := d.Get("opdata").(string)
+		trigger.OpData = &opdata
+	}
+	if api.Config.Version >= 50200 {
+		eventName := d.Get("event_name").(string)
+		trigger.EventName = &eventName
+	}
+	if api.Config.Version >= 60400 {
+		urlName := d.Get("url_name").(string)
+		trigger.UrlName = &urlName
+	}
+	if prototype && api.Config.Version >= 50000 {
+		discover := "0"
+		if !d.Get("discover").(bool) {
+			discover = "1"
+		}
+		trigger.Discover = &discover
+	}
+
+	return trigger
+}
+
+// triggerExpression expression to send, converted for 5.4+ servers when normalizing, or when
+// in the legacy syntax the server rejects, plan time checks only allow this while unchanged
+func triggerExpression(v string, api *zabbix.API, normalize bool) string {
+	if api.Config.Version < expressionVersion {
+		return v
+	}
+	if !normalize && validateTriggerExpression(v, api.Config.Version) == nil {
+		return v
+	}
+	if c, err := convertExpression(v); err == nil {
+		return c
+	}
+	return v
+}
+
+// triggerExpressionRead expression as returned by expandExpression, the current value is kept
+// while equivalent, ie a legacy expression converted by the server or by normalize_expressions
+func triggerExpressionRead(d *schema.ResourceData, k, remote string) string {
+	if current := d.Get(k).(string); expressionDiffSuppress(k, current, remote, d) {
+		return current
+	}
+	return remote
 }
 
 // create trigger terraform handler
 func resourceTriggerCreate(prototype bool) schema.CreateFunc {
 	return func(d *schema.ResourceData, m interface{}) error {
-		api := m.(*zabbix.API)
-
-		item := buildTriggerObject(d)
+		api := metaAPI(m)
 
-		items := []zabbix.Trigger{item}
+		item := buildTriggerObject(d, m, prototype)
 
-		var err error
-		if prototype {
-			err = api.ProtoTriggersCreate(items)
-		} else {
-			err = api.TriggersCreate(items)
+		expression, err := triggerConditionExpression(d, api, prototype)
+		if err != nil {
+			return err
+		}
+		if expression != "" {
+			item.Expression = expression
 		}
 
+		items := apiTriggers{item}
+
+		err = triggersCreate(api, items, prototype)
+
 		if err != nil {
 			return err
 		}
@@ -231,7 +343,7 @@ func resourceTriggerCreate(prototype bool) schema.CreateFunc {
 // read tirgger terraform handler
 func resourceTriggerRead(prototype bool) schema.ReadFunc {
 	return func(d *schema.ResourceData, m interface{}) error {
-		api := m.(*zabbix.API)
+		api := metaAPI(m)
 
 		log.Debug("Lookup of trigger with id %s", d.Id())
 
@@ -242,14 +354,7 @@ func resourceTriggerRead(prototype bool) schema.ReadFunc {
 			"selectTags":         "extend",
 		}
 
-		var triggers zabbix.Triggers
-		var err error
-
-		if prototype {
-			triggers, err = api.ProtoTriggersGet(params)
-		} else {
-			triggers, err = api.TriggersGet(params)
-		}
+		triggers, err := triggersGet(api, params, prototype)
 
 		if err != nil {
 			return err
@@ -267,16 +372,31 @@ func resourceTriggerRead(prototype bool) schema.ReadFunc {
 		log.Debug("Got trigger: %+v", t)
 
 		d.Set("name", t.Description)
-		d.Set("expression", t.Expression)
+		d.Set("expression", triggerExpressionRead(d, "expression", t.Expression))
+		if err := triggerConditionRead(d, api, prototype, t.Expression); err != nil {
+			return err
+		}
 		d.Set("comments", t.Comments)
 		d.Set("priority", TRIGGER_PRIORITY_REV[t.Priority])
 		d.Set("enabled", t.Status == 0)
 		d.Set("multiple", t.Type == 1)
 		d.Set("url", t.Url)
-		d.Set("recovery_expression", t.RecoveryExpression)
+		d.Set("recovery_expression", triggerExpressionRead(d, "recovery_expression", t.RecoveryExpression))
 		d.Set("correlation_tag", t.CorrelationTag)
 		d.Set("manual_close", t.ManualClose == 1)
 		d.Set("tag", flattenTags(t.Tags))
+		if t.OpData != nil {
+			d.Set("opdata", *t.OpData)
+		}
+		if t.EventName != nil {
+			d.Set("event_name", *t.EventName)
+		}
+		if t.UrlName != nil {
+			d.Set("url_name", *t.UrlName)
+		}
+		if prototype && t.Discover != nil {
+			d.Set("discover", *t.Discover != "1")
+		}
 
 		if t.RecoveryMode == 2 {
 			d.Set("recovery_none", true)
@@ -295,8 +415,10 @@ func resourceTriggerRead(prototype bool) schema.ReadFunc {
 		}
 
 		dependenciesSet := schema.NewSet(schema.HashString, []interface{}{})
-		for _, v := range t.Dependencies {
-			dependenciesSet.Add(v.TriggerID)
+		if t.Dependencies != nil {
+			for _, v := range *t.Dependencies {
+				dependenciesSet.Add(v.TriggerID)
+			}
 		}
 		d.Set("dependencies", dependenciesSet)
 
@@ -307,21 +429,23 @@ func resourceTriggerRead(prototype bool) schema.ReadFunc {
 // update trigger terraform handler
 func resourceTriggerUpdate(prototype bool) schema.UpdateFunc {
 	return func(d *schema.ResourceData, m interface{}) error {
-		api := m.(*zabbix.API)
+		api := metaAPI(m)
 
-		item := buildTriggerObject(d)
+		item := buildTriggerObject(d, m, prototype)
 
 		item.TriggerID = d.Id()
 
-		items := []zabbix.Trigger{item}
+		expression, err := triggerConditionExpression(d, api, prototype)
+		if err != nil {
+			return err
+		}
+		if expression != "" {
+			item.Expression = expression
+		}
 
-		var err error
+		items := apiTriggers{item}
 
-		if prototype {
-			err = api.ProtoTriggersUpdate(items)
-		} else {
-			err = api.TriggersUpdate(items)
-		}
+		err = triggersUpdate(api, items, prototype)
 
 		if err != nil {
 			return err
@@ -334,10 +458,121 @@ func resourceTriggerUpdate(prototype bool) schema.UpdateFunc {
 // delete trigger terraform handler
 func resourceTriggerDelete(prototype bool) schema.DeleteFunc {
 	return func(d *schema.ResourceData, m interface{}) error {
-		api := m.(*zabbix.API)
+		api := metaAPI(m)
 		if prototype {
 			return api.ProtoTriggersDeleteByIds([]string{d.Id()})
 		}
 		return api.TriggersDeleteByIds([]string{d.Id()})
 	}
 }
+
+// triggersGet wrapper for trigger.get / triggerprototype.get
+func triggersGet(api *zabbix.API, params zabbix.Params, prototype bool) (res apiTriggers, err error) {
+	method := "trigger.get"
+	if prototype {
+		method = "triggerprototype.get"
+	}
+	if _, present := params["output"]; !present {
+		params["output"] = "extend"
+	}
+	err = api.CallWithErrorParse(method, params, &res)
+	return
+}
+
+// triggersCreate wrapper for trigger.create / triggerprototype.create
+func triggersCreate(api *zabbix.API, triggers apiTriggers, prototype bool) (err error) {
+	method := "trigger.create"
+	if prototype {
+		method = "triggerprototype.create"
+	}
+	response, err := api.CallWithError(method, triggers)
+	if err != nil {
+		return
+	}
+
+	result := response.Result.(map[string]interface{})
+	triggerids := result["triggerids"].([]interface{})
+	for i, id := range triggerids {
+		triggers[i].TriggerID = id.(string)
+	}
+	return
+}
+
+// triggersUpdate wrapper for trigger.update / triggerprototype.update
+func triggersUpdate(api *zabbix.API, triggers apiTriggers, prototype bool) (err error) {
+	method := "trigger.update"
+	if prototype {
+		method = "triggerprototype.update"
+	}
+	_, err = api.CallWithError(method, triggers)
+	return
+}
+
+// triggerCustomizeDiff plan time checks common to triggers and trigger prototypes
+func triggerCustomizeDiff(d *schema.ResourceDiff, m interface{}) error {
+	version := 0
+	if api := metaAPI(m); api != nil {
+		version = api.Config.Version
+	}
+
+	if err := triggerConditionCustomizeDiff(d, version); err != nil {
+		return err
+	}
+
+	// both syntaxes are accepted while the server version is unknown, legacy expressions
+	// are checked in the converted form when normalizing or unchanged from the state
+	for _, k := range []string{"expression", "recovery_expression"} {
+		v := d.Get(k).(string)
+		if v == "" || !d.NewValueKnown(k) {
+			continue
+		}
+		if version >= expressionVersion && (metaNormalizeExpressions(m) || triggerExpressionUnchanged(d, k)) {
+			c, err := convertExpression(v)
+			if err != nil {
+				return fmt.Errorf("%s: %s", k, err)
+			}
+			v = c
+		}
+		if err := validateTriggerExpression(v, version); err != nil {
+			return fmt.Errorf("%s: %s", k, err)
+		}
+	}
+
+	if version == 0 {
+		return nil
+	}
+	if err := triggerVersionCustomizeDiff(d, version); err != nil {
+		return err
+	}
+
+	_, prototype := d.Get("discover").(bool)
+	return triggerDependencyCustomizeDiff(d, metaAPI(m), prototype)
+}
+
+// triggerExpressionUnchanged expression equivalent to the state, ie the server converted
+// a legacy expression when upgrading to 5.4
+func triggerExpressionUnchanged(d *schema.ResourceDiff, k string) bool {
+	if !d.HasChange(k) {
+		return true
+	}
+	old, new := d.GetChange(k)
+	return expressionDiffSuppress(k, old.(string), new.(string), nil)
+}
+
+// triggerVersionCustomizeDiff attributes only supported by later versions
+func triggerVersionCustomizeDiff(d *schema.ResourceDiff, version int) error {
+	if d.Get("opdata").(string) != "" && version < 50000 {
+		return errors.New("opdata requires zabbix 5.0 or later")
+	}
+	if d.Get("event_name").(string) != "" && version < 50200 {
+		return errors.New("event_name requires zabbix 5.2 or later")
+	}
+	if d.Get("url_name").(string) != "" && version < 60400 {
+		return errors.New("url_name requires zabbix 6.4 or later")
+	}
+	// discover only present on prototypes
+	if discover, prototype := d.Get("discover").(bool); prototype && !discover && version < 50000 {
+		return errors.New("discover requires zabbix 5.0 or later")
+	}
+	return nil
+}
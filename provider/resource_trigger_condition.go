@@ -0,0 +1,395 @@
+package provider
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/validation"
+	"github.com/tpretz/go-zabbix-api"
+)
+
+// comparison of a condition function with its threshold
+var TRIGGER_CONDITION_OPERATORS = []string{"=", "<>", ">", ">=", "<", "<="}
+
+// joining multiple conditions
+var TRIGGER_CONDITION_LOGIC = []string{"and", "or"}
+
+// condition blocks, rendered into the trigger expression
+var triggerConditionSchema = &schema.Schema{
+	Type:        schema.TypeList,
+	Optional:    true,
+	Description: "Expression conditions, rendered into the trigger expression, alternative to expression",
+	Elem: &schema.Resource{
+		Schema: map[string]*schema.Schema{
+			"function": &schema.Schema{
+				Type:     schema.TypeList,
+				Required: true,
+				MaxItems: 1,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"name": &schema.Schema{
+							Type:         schema.TypeString,
+							Required:     true,
+							ValidateFunc: validation.StringIsNotWhiteSpace,
+							Description:  "Function name, ie last, avg, nodata",
+						},
+						"itemid": &schema.Schema{
+							Type:        schema.TypeString,
+							Optional:    true,
+							Computed:    true,
+							Description: "Item ID, the host and key are looked up",
+						},
+						"host": &schema.Schema{
+							Type:        schema.TypeString,
+							Optional:    true,
+							Computed:    true,
+							Description: "Item host or template name, alternative to itemid",
+						},
+						"key": &schema.Schema{
+							Type:        schema.TypeString,
+							Optional:    true,
+							Computed:    true,
+							Description: "Item key, alternative to itemid",
+						},
+						"params": &schema.Schema{
+							Type:        schema.TypeList,
+							Optional:    true,
+							Elem:        &schema.Schema{Type: schema.TypeString},
+							Description: "Function parameters following the item, quoted as required",
+						},
+					},
+				},
+			},
+			"operator": &schema.Schema{
+				Type:         schema.TypeString,
+				Required:     true,
+				ValidateFunc: validation.StringInSlice(TRIGGER_CONDITION_OPERATORS, false),
+				Description:  "Comparison operator, one of: " + strings.Join(TRIGGER_CONDITION_OPERATORS, ", "),
+			},
+			"threshold": &schema.Schema{
+				Type:         schema.TypeString,
+				Required:     true,
+				ValidateFunc: validation.StringIsNotWhiteSpace,
+				Description:  "Threshold, a number or macro",
+			},
+		},
+	},
+}
+
+// triggerCondition single function compared with a threshold
+type triggerCondition struct {
+	Function  string
+	ItemID    string
+	Host      string
+	Key       string
+	Params    []string
+	Operator  string
+	Threshold string
+}
+
+// parameters not needing quotes, numbers, periods, macros and empty
+var exprRawParam = regexp.MustCompile(`^(-?[0-9.]+[smhdwKMGT]?|\{[^{}]+\}|)$`)
+
+// expandTriggerConditions terraform condition blocks to structs
+func expandTriggerConditions(list []interface{}) []triggerCondition {
+	conditions := make([]triggerCondition, len(list))
+	for i, v := range list {
+		current := v.(map[string]interface{})
+		conditions[i] = triggerCondition{
+			Operator:  current["operator"].(string),
+			Threshold: current["threshold"].(string),
+		}
+		functions := current["function"].([]interface{})
+		if len(functions) < 1 || functions[0] == nil {
+			continue
+		}
+		function := functions[0].(map[string]interface{})
+		conditions[i].Function = function["name"].(string)
+		conditions[i].ItemID = function["itemid"].(string)
+		conditions[i].Host = function["host"].(string)
+		conditions[i].Key = function["key"].(string)
+		for _, p := range function["params"].([]interface{}) {
+			s, _ := p.(string)
+			conditions[i].Params = append(conditions[i].Params, s)
+		}
+	}
+	return conditions
+}
+
+// flattenTriggerConditions structs to terraform condition blocks
+func flattenTriggerConditions(conditions []triggerCondition) []interface{} {
+	list := make([]interface{}, len(conditions))
+	for i, c := range conditions {
+		params := make([]interface{}, len(c.Params))
+		for j, p := range c.Params {
+			params[j] = p
+		}
+		list[i] = map[string]interface{}{
+			"function": []interface{}{
+				map[string]interface{}{
+					"name":   c.Function,
+					"itemid": c.ItemID,
+					"host":   c.Host,
+					"key":    c.Key,
+					"params": params,
+				},
+			},
+			"operator":  c.Operator,
+			"threshold": c.Threshold,
+		}
+	}
+	return list
+}
+
+// renderTriggerConditions expression in the syntax of the server version, {host:key.func()} before 5.4
+func renderTriggerConditions(conditions []triggerCondition, logic string, version int) string {
+	legacy := version > 0 && version < expressionVersion
+
+	var root *exprNode
+	for _, c := range conditions {
+		function := &exprNode{Type: exprFunc, Func: c.Function, Host: c.Host, Key: c.Key, Legacy: legacy}
+		if !legacy {
+			function.Args = []*exprNode{{Type: exprQuery, Host: c.Host, Key: c.Key}}
+		}
+		for _, p := range c.Params {
+			function.Args = append(function.Args, exprParamNode(p, legacy))
+		}
+
+		threshold := &exprNode{Type: exprParam, Value: c.Threshold}
+		node := &exprNode{Type: exprBinary, Value: c.Operator, Args: []*exprNode{function, threshold}}
+		if root == nil {
+			root = node
+			continue
+		}
+		root = &exprNode{Type: exprBinary, Value: logic, Args: []*exprNode{root, node}}
+	}
+	if root == nil {
+		return ""
+	}
+	return renderExpression(root)
+}
+
+// exprParamNode function parameter, strings are quoted and escaped for the syntax
+func exprParamNode(v string, legacy bool) *exprNode {
+	switch {
+	case exprRawParam.MatchString(v) || exprPeriod.MatchString(v) || strings.Contains(v, ":now"):
+	case legacy && (strings.ContainsAny(v, `,)"`) || strings.TrimSpace(v) != v):
+		// legacy parameters only escape quotes
+		return &exprNode{Type: exprString, Value: `"` + strings.Replace(v, `"`, `\"`, -1) + `"`}
+	case !legacy:
+		return &exprNode{Type: exprString, Value: exprQuote(v)}
+	}
+	return &exprNode{Type: exprParam, Value: v}
+}
+
+// parseTriggerConditions parse an expression back into conditions, false if not expressible as conditions
+func parseTriggerConditions(src string) ([]triggerCondition, string, bool) {
+	n, err := parseExpression(src, 0)
+	if err != nil {
+		return nil, "", false
+	}
+
+	// left associative chain of a single and/or
+	logic := "and"
+	nodes := []*exprNode{}
+	for n.Type == exprBinary && (n.Value == "and" || n.Value == "or") {
+		if len(nodes) > 0 && n.Value != logic {
+			return nil, "", false
+		}
+		logic = n.Value
+		nodes = append([]*exprNode{n.Args[1]}, nodes...)
+		n = n.Args[0]
+	}
+	nodes = append([]*exprNode{n}, nodes...)
+
+	conditions := make([]triggerCondition, len(nodes))
+	for i, node := range nodes {
+		c, ok := parseTriggerCondition(node)
+		if !ok {
+			return nil, "", false
+		}
+		conditions[i] = c
+	}
+	return conditions, logic, true
+}
+
+// parseTriggerCondition single function compared with a threshold
+func parseTriggerCondition(n *exprNode) (triggerCondition, bool) {
+	c := triggerCondition{}
+	isComparison := false
+	for _, op := range TRIGGER_CONDITION_OPERATORS {
+		isComparison = isComparison || n.Type == exprBinary && n.Value == op
+	}
+	if !isComparison || n.Args[0].Type != exprFunc {
+		return c, false
+	}
+	function := n.Args[0]
+	c.Function = function.Func
+	c.Operator = n.Value
+	c.Threshold = renderExpression(n.Args[1])
+	c.Params = []string{}
+
+	if function.Legacy {
+		c.Host = function.Host
+		c.Key = function.Key
+		for _, arg := range function.Args {
+			c.Params = append(c.Params, exprUnquote(arg))
+		}
+		return c, true
+	}
+
+	if len(function.Args) < 1 || function.Args[0].Type != exprQuery || function.Args[0].Filter != "" {
+		return c, false
+	}
+	c.Host = function.Args[0].Host
+	c.Key = function.Args[0].Key
+	for _, arg := range function.Args[1:] {
+		if arg.Type == exprString {
+			c.Params = append(c.Params, exprUnescape(arg.Value))
+			continue
+		}
+		c.Params = append(c.Params, renderExpression(arg))
+	}
+	return c, true
+}
+
+// exprUnescape value of a 5.4+ string parameter
+func exprUnescape(v string) string {
+	return strings.NewReplacer(`\\`, `\`, `\"`, `"`).Replace(v[1 : len(v)-1])
+}
+
+// triggerItemRefs look up items referenced by id, trigger prototypes may reference item prototypes
+func triggerItemRefs(api *zabbix.API, ids []string, prototype bool) (map[string]apiItem, error) {
+	refs := map[string]apiItem{}
+	if len(ids) == 0 {
+		return refs, nil
+	}
+
+	lookups := []bool{false}
+	if prototype {
+		lookups = append(lookups, true)
+	}
+	for _, proto := range lookups {
+		items, err := itemsGet(api, zabbix.Params{
+			"itemids":     ids,
+			"output":      []string{"itemid", "key_"},
+			"selectHosts": []string{"host"},
+		}, proto)
+		if err != nil {
+			return nil, err
+		}
+		for _, item := range items {
+			refs[item.ItemID] = item
+		}
+	}
+	return refs, nil
+}
+
+// triggerConditionIds item ids referenced by conditions
+func triggerConditionIds(conditions []triggerCondition) []string {
+	ids := []string{}
+	for _, c := range conditions {
+		if c.ItemID != "" {
+			ids = append(ids, c.ItemID)
+		}
+	}
+	return ids
+}
+
+// triggerConditionExpression render configured conditions, empty when expression is used
+func triggerConditionExpression(d *schema.ResourceData, api *zabbix.API, prototype bool) (string, error) {
+	conditions := expandTriggerConditions(d.Get("condition").([]interface{}))
+	if len(conditions) == 0 {
+		return "", nil
+	}
+
+	refs, err := triggerItemRefs(api, triggerConditionIds(conditions), prototype)
+	if err != nil {
+		return "", err
+	}
+	for i, c := range conditions {
+		if c.ItemID == "" {
+			continue
+		}
+		item, ok := refs[c.ItemID]
+		if !ok || len(item.ItemParent) < 1 {
+			return "", fmt.Errorf("condition.%d: item %s not found", i, c.ItemID)
+		}
+		conditions[i].Host = item.ItemParent[0].Host
+		conditions[i].Key = item.Key
+	}
+
+	expression := renderTriggerConditions(conditions, d.Get("condition_operator").(string), api.Config.Version)
+	log.Debug("rendered trigger conditions: %s", expression)
+	return expression, nil
+}
+
+// triggerConditionRead parse the expression back into conditions, only when conditions are in use,
+// item ids are kept while they still reference the same host and key
+func triggerConditionRead(d *schema.ResourceData, api *zabbix.API, prototype bool, expression string) error {
+	current := expandTriggerConditions(d.Get("condition").([]interface{}))
+	if len(current) == 0 {
+		// unset on import, only meaningful with conditions
+		if d.Get("condition_operator").(string) == "" {
+			d.Set("condition_operator", "and")
+		}
+		return nil
+	}
+
+	conditions, logic, ok := parseTriggerConditions(expression)
+	if !ok {
+		log.Debug("trigger expression not expressible as conditions: %s", expression)
+		d.Set("condition", []interface{}{})
+		return nil
+	}
+
+	refs, err := triggerItemRefs(api, triggerConditionIds(current), prototype)
+	if err != nil {
+		return err
+	}
+	for i := range conditions {
+		if i >= len(current) || current[i].ItemID == "" {
+			continue
+		}
+		item, ok := refs[current[i].ItemID]
+		if ok && len(item.ItemParent) > 0 && item.ItemParent[0].Host == conditions[i].Host && item.Key == conditions[i].Key {
+			conditions[i].ItemID = current[i].ItemID
+		}
+	}
+
+	d.Set("condition", flattenTriggerConditions(conditions))
+	d.Set("condition_operator", logic)
+	return nil
+}
+
+// triggerConditionCustomizeDiff check conditions by rendering them for the server version,
+// unknown or item id references are substituted
+func triggerConditionCustomizeDiff(d *schema.ResourceDiff, version int) error {
+	conditions := expandTriggerConditions(d.Get("condition").([]interface{}))
+	if len(conditions) == 0 || !d.NewValueKnown("condition") {
+		return nil
+	}
+	if d.HasChange("condition") || d.HasChange("condition_operator") {
+		if err := d.SetNewComputed("expression"); err != nil {
+			return err
+		}
+	}
+
+	for i, c := range conditions {
+		if c.ItemID == "" && (c.Host == "" || c.Key == "") {
+			return fmt.Errorf("condition.%d: function requires itemid, or host and key", i)
+		}
+		if c.ItemID != "" {
+			conditions[i].Host = "host"
+			conditions[i].Key = "key"
+		}
+	}
+
+	expression := renderTriggerConditions(conditions, d.Get("condition_operator").(string), version)
+	if err := validateTriggerExpression(expression, version); err != nil {
+		return fmt.Errorf("condition: %s, rendered as %s", err, expression)
+	}
+	return nil
+}
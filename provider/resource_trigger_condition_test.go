@@ -0,0 +1,167 @@
+package provider
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/terraform"
+	"github.com/tpretz/go-zabbix-api"
+)
+
+func TestRenderTriggerConditions(t *testing.T) {
+	conditions := []triggerCondition{
+		{Function: "avg", Host: "Linux", Key: `net.if.in["eth0",bytes]`, Params: []string{"5m"}, Operator: ">", Threshold: "{$IF.MAX}"},
+		{Function: "find", Host: "Linux", Key: "log", Params: []string{"", "regexp", `^ERR "\d+"`}, Operator: "=", Threshold: "1"},
+	}
+
+	if e := renderTriggerConditions(conditions, "or", 60000); e != `avg(/Linux/net.if.in["eth0",bytes],5m)>{$IF.MAX} or find(/Linux/log,,"regexp","^ERR \"\\d+\"")=1` {
+		t.Errorf("unexpected 5.4+ expression %s", e)
+	}
+
+	legacy := []triggerCondition{
+		{Function: "last", Host: "Linux", Key: "agent.ping", Operator: "=", Threshold: "0"},
+		{Function: "str", Host: "Linux", Key: "log", Params: []string{`a, "b"`}, Operator: "=", Threshold: "1"},
+	}
+	if e := renderTriggerConditions(legacy, "and", 50000); e != `{Linux:agent.ping.last()}=0 and {Linux:log.str("a, \"b\"")}=1` {
+		t.Errorf("unexpected legacy expression %s", e)
+	}
+
+	// rendered conditions parse back unchanged
+	for _, version := range []int{50000, 60000} {
+		c := conditions
+		if version < expressionVersion {
+			c = legacy
+		}
+		parsed, logic, ok := parseTriggerConditions(renderTriggerConditions(c, "or", version))
+		if !ok || logic != "or" {
+			t.Fatalf("%d: expected conditions", version)
+		}
+		for i := range parsed {
+			if c[i].Params == nil {
+				c[i].Params = []string{}
+			}
+		}
+		if !reflect.DeepEqual(parsed, c) {
+			t.Errorf("%d: expected %+v, got %+v", version, c, parsed)
+		}
+	}
+}
+
+func TestParseTriggerConditions(t *testing.T) {
+	cases := []struct {
+		expression string
+		ok         bool
+	}{
+		{`last(/host/key)>0`, true},
+		{`last(/host/key)>0 and min(/host/key,5m)<-1`, true},
+		{`last(/host/key)>0 and min(/host/key,5m)<1 or nodata(/host/key,5m)=1`, false},
+		{`(last(/host/key)>0)`, false},
+		{`last(/host/key)+1>0`, false},
+		{`avg(last_foreach(/*/key?[group="g"]))>0`, false},
+		{`last(/host/key`, false},
+	}
+
+	for _, tc := range cases {
+		if _, _, ok := parseTriggerConditions(tc.expression); ok != tc.ok {
+			t.Errorf("%s: expected %t", tc.expression, tc.ok)
+		}
+	}
+}
+
+func TestTriggerConditionCustomizeDiff(t *testing.T) {
+	api60 := &zabbix.API{Config: zabbix.Config{Version: 60000}}
+	condition := func(function map[string]interface{}) []interface{} {
+		return []interface{}{map[string]interface{}{"function": []interface{}{function}, "operator": ">", "threshold": "5"}}
+	}
+
+	cases := []struct {
+		config map[string]interface{}
+		err    string
+	}{
+		{map[string]interface{}{"condition": condition(map[string]interface{}{"name": "last", "itemid": "1"})}, ""},
+		{map[string]interface{}{"condition": condition(map[string]interface{}{"name": "avg", "host": "h", "key": "k", "params": []interface{}{"5m"}})}, ""},
+		{map[string]interface{}{"condition": condition(map[string]interface{}{"name": "avg", "host": "h", "key": "k"})}, "avg expects 2 params, got 1"},
+		{map[string]interface{}{"condition": condition(map[string]interface{}{"name": "last", "host": "h"})}, "requires itemid, or host and key"},
+	}
+
+	for i, tc := range cases {
+		tc.config["name"] = "trigger"
+
+		_, err := resourceTrigger().Diff(nil, terraform.NewResourceConfigRaw(tc.config), api60)
+		switch {
+		case tc.err == "" && err != nil:
+			t.Errorf("case %d: unexpected error %s", i, err)
+		case tc.err != "" && err == nil:
+			t.Errorf("case %d: expected error %q", i, tc.err)
+		case tc.err != "" && !strings.Contains(err.Error(), tc.err):
+			t.Errorf("case %d: expected error %q, got %q", i, tc.err, err)
+		}
+	}
+
+	// exactly one of expression and condition
+	for _, config := range []map[string]interface{}{
+		{"name": "trigger", "expression": "last(/h/k)>0", "condition": condition(map[string]interface{}{"name": "last", "itemid": "1"})},
+		{"name": "trigger"},
+	} {
+		if _, errs := resourceTrigger().Validate(terraform.NewResourceConfigRaw(config)); len(errs) == 0 {
+			t.Errorf("expected exactly one of error for %v", config)
+		}
+	}
+}
+
+func TestTriggerConditionExpression(t *testing.T) {
+	server := exportTestServer(t, map[string]string{
+		"apiinfo.version": `"6.0.0"`,
+		"item.get":        `[{"itemid":"20001","key_":"net.if.in[\"eth0\"]","hosts":[{"hostid":"10001","host":"Linux"}]}]`,
+	})
+	defer server.Close()
+
+	api, err := zabbix.NewAPI(zabbix.Config{Url: server.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	d := resourceTrigger().Data(nil)
+	d.Set("condition_operator", "and")
+	d.Set("condition", []interface{}{
+		map[string]interface{}{
+			"function":  []interface{}{map[string]interface{}{"name": "avg", "itemid": "20001", "params": []interface{}{"5m"}}},
+			"operator":  ">",
+			"threshold": "100",
+		},
+	})
+
+	expression, err := triggerConditionExpression(d, api, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expression != `avg(/Linux/net.if.in["eth0"],5m)>100` {
+		t.Errorf("unexpected expression %s", expression)
+	}
+
+	// item id kept while the expression still references its host and key
+	if err := triggerConditionRead(d, api, false, `avg(/Linux/net.if.in["eth0"],10m)>100`); err != nil {
+		t.Fatal(err)
+	}
+	if d.Get("condition.0.function.0.itemid") != "20001" || d.Get("condition.0.function.0.params.0") != "10m" {
+		t.Errorf("unexpected condition %+v", d.Get("condition"))
+	}
+	if err := triggerConditionRead(d, api, false, `avg(/Linux/other,10m)>100`); err != nil {
+		t.Fatal(err)
+	}
+	if d.Get("condition.0.function.0.itemid") != "" || d.Get("condition.0.function.0.key") != "other" {
+		t.Errorf("unexpected condition %+v", d.Get("condition"))
+	}
+
+	d.Set("condition", []interface{}{
+		map[string]interface{}{
+			"function":  []interface{}{map[string]interface{}{"name": "last", "itemid": "404"}},
+			"operator":  ">",
+			"threshold": "0",
+		},
+	})
+	if _, err := triggerConditionExpression(d, api, false); err == nil || !strings.Contains(err.Error(), "item 404 not found") {
+		t.Errorf("expected not found error, got %v", err)
+	}
+}
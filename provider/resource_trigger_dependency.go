@@ -0,0 +1,377 @@
+package provider
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/validation"
+	"github.com/tpretz/go-zabbix-api"
+)
+
+// triggerDependencyRef trigger as needed for dependency checks
+type triggerDependencyRef struct {
+	TriggerID   string `json:"triggerid"`
+	Description string `json:"description"`
+	Hosts       []struct {
+		Host   string `json:"host"`
+		Status string `json:"status"`
+	} `json:"hosts"`
+	Dependencies []struct {
+		TriggerID string `json:"triggerid"`
+	} `json:"dependencies"`
+}
+
+// templated does the trigger belong to a template (host status 3)
+func (t triggerDependencyRef) templated() bool {
+	for _, h := range t.Hosts {
+		if h.Status == "3" {
+			return true
+		}
+	}
+	return false
+}
+
+func (t triggerDependencyRef) String() string {
+	return fmt.Sprintf("%q (%s)", t.Description, t.TriggerID)
+}
+
+// resourceTriggerDependency terraform trigger dependency resource entrypoint
+func resourceTriggerDependency() *schema.Resource {
+	return &schema.Resource{
+		Create: resourceTriggerDependencyCreate,
+		Read:   resourceTriggerDependencyRead,
+		Delete: resourceTriggerDependencyDelete,
+		Importer: &schema.ResourceImporter{
+			State: schema.ImportStatePassthrough,
+		},
+		CustomizeDiff: triggerDependencyResourceCustomizeDiff,
+
+		Schema: map[string]*schema.Schema{
+			"triggerid": &schema.Schema{
+				Type:         schema.TypeString,
+				Required:     true,
+				ForceNew:     true,
+				Description:  "Dependent trigger ID",
+				ValidateFunc: validation.StringMatch(regexp.MustCompile("^[0-9]+$"), "must be numeric"),
+			},
+			"depends_on_triggerid": &schema.Schema{
+				Type:         schema.TypeString,
+				Required:     true,
+				ForceNew:     true,
+				Description:  "Trigger ID depended on",
+				ValidateFunc: validation.StringMatch(regexp.MustCompile("^[0-9]+$"), "must be numeric"),
+			},
+		},
+	}
+}
+
+// triggerDependencyId split a <triggerid>:<depends_on_triggerid> resource id
+func triggerDependencyId(id string) (string, string, error) {
+	parts := strings.SplitN(id, ":", 2)
+	if len(parts) != 2 || !importNumericId.MatchString(parts[0]) || !importNumericId.MatchString(parts[1]) {
+		return "", "", fmt.Errorf("invalid trigger dependency id %q, expected <triggerid>:<depends_on_triggerid>", id)
+	}
+	return parts[0], parts[1], nil
+}
+
+// resourceTriggerDependencyCreate terraform create handler
+func resourceTriggerDependencyCreate(d *schema.ResourceData, m interface{}) error {
+	api := metaAPI(m)
+
+	triggerid := d.Get("triggerid").(string)
+	dependsOn := d.Get("depends_on_triggerid").(string)
+
+	_, err := api.CallWithError("trigger.adddependencies", zabbix.Params{
+		"triggerid":          triggerid,
+		"dependsOnTriggerid": dependsOn,
+	})
+	if err != nil {
+		return err
+	}
+
+	d.SetId(triggerid + ":" + dependsOn)
+
+	return resourceTriggerDependencyRead(d, m)
+}
+
+// resourceTriggerDependencyRead terraform read handler
+func resourceTriggerDependencyRead(d *schema.ResourceData, m interface{}) error {
+	api := metaAPI(m)
+
+	triggerid, dependsOn, err := triggerDependencyId(d.Id())
+	if err != nil {
+		return err
+	}
+
+	log.Debug("Lookup of trigger dependency %s", d.Id())
+
+	refs, err := triggerDependencyRefs(api, []string{triggerid}, false)
+	if err != nil {
+		return err
+	}
+	trigger, ok := refs[triggerid]
+	if !ok {
+		d.SetId("")
+		return nil
+	}
+
+	found := false
+	for _, dep := range trigger.Dependencies {
+		found = found || dep.TriggerID == dependsOn
+	}
+	if !found {
+		d.SetId("")
+		return nil
+	}
+
+	d.Set("triggerid", triggerid)
+	d.Set("depends_on_triggerid", dependsOn)
+
+	return nil
+}
+
+// resourceTriggerDependencyDelete terraform delete handler, other dependencies of the trigger are kept
+func resourceTriggerDependencyDelete(d *schema.ResourceData, m interface{}) error {
+	api := metaAPI(m)
+
+	triggerid, dependsOn, err := triggerDependencyId(d.Id())
+	if err != nil {
+		return err
+	}
+
+	refs, err := triggerDependencyRefs(api, []string{triggerid}, false)
+	if err != nil {
+		return err
+	}
+	trigger, ok := refs[triggerid]
+	if !ok {
+		return nil
+	}
+
+	remaining := zabbix.TriggerIDs{}
+	for _, dep := range trigger.Dependencies {
+		if dep.TriggerID != dependsOn {
+			remaining = append(remaining, zabbix.TriggerID{TriggerID: dep.TriggerID})
+		}
+	}
+
+	_, err = api.CallWithError("trigger.update", zabbix.Params{
+		"triggerid":    triggerid,
+		"dependencies": remaining,
+	})
+	return err
+}
+
+// triggerDependencyResourceCustomizeDiff plan time checks of a single dependency
+func triggerDependencyResourceCustomizeDiff(d *schema.ResourceDiff, m interface{}) error {
+	api := metaAPI(m)
+	if api == nil || !d.NewValueKnown("triggerid") || !d.NewValueKnown("depends_on_triggerid") {
+		return nil
+	}
+	if !d.HasChange("triggerid") && !d.HasChange("depends_on_triggerid") {
+		return nil
+	}
+
+	triggerid := d.Get("triggerid").(string)
+	refs, err := triggerDependencyRefs(api, []string{triggerid}, false)
+	if err != nil {
+		return err
+	}
+	trigger, ok := refs[triggerid]
+	if !ok {
+		return fmt.Errorf("triggerid: trigger %s not found", triggerid)
+	}
+	templated := trigger.templated()
+
+	err = triggerDependencyCheck(api, trigger, &templated, []string{d.Get("depends_on_triggerid").(string)}, false)
+	if err != nil {
+		return fmt.Errorf("depends_on_triggerid: %s", err)
+	}
+	return nil
+}
+
+// triggerDependencyCustomizeDiff plan time checks of trigger dependencies, only when changed
+func triggerDependencyCustomizeDiff(d *schema.ResourceDiff, api *zabbix.API, prototype bool) error {
+	if !d.HasChange("dependencies") || !d.NewValueKnown("dependencies") {
+		return nil
+	}
+	deps := []string{}
+	for _, v := range d.Get("dependencies").(*schema.Set).List() {
+		deps = append(deps, v.(string))
+	}
+	if len(deps) == 0 {
+		return nil
+	}
+
+	self := triggerDependencyRef{TriggerID: d.Id(), Description: d.Get("name").(string)}
+	templated, err := triggerTemplated(d, api, prototype)
+	if err != nil {
+		return err
+	}
+
+	if err := triggerDependencyCheck(api, self, templated, deps, prototype); err != nil {
+		return fmt.Errorf("dependencies: %s", err)
+	}
+	return nil
+}
+
+// triggerTemplated does the planned trigger belong to a template, from the existing trigger
+// or the hosts of its expression, nil when unknown
+func triggerTemplated(d *schema.ResourceDiff, api *zabbix.API, prototype bool) (*bool, error) {
+	templated := false
+
+	if d.Id() != "" {
+		refs, err := triggerDependencyRefs(api, []string{d.Id()}, prototype)
+		if err != nil {
+			return nil, err
+		}
+		if t, ok := refs[d.Id()]; ok {
+			templated = t.templated()
+			return &templated, nil
+		}
+	}
+
+	expression := d.Get("expression").(string)
+	if expression == "" || !d.NewValueKnown("expression") {
+		return nil, nil
+	}
+	n, err := parseExpression(expression, 0)
+	if err != nil {
+		return nil, nil
+	}
+	hosts := []string{}
+	exprWalk(n, func(n *exprNode) {
+		if n.Type == exprQuery || n.Legacy {
+			hosts = append(hosts, n.Host)
+		}
+	})
+	if len(hosts) == 0 {
+		return nil, nil
+	}
+
+	templates, err := api.TemplatesGet(zabbix.Params{
+		"output": []string{"templateid"},
+		"filter": map[string]interface{}{"host": hosts},
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(templates) > 0 {
+		templated = true
+		return &templated, nil
+	}
+
+	// neither found, ie a template created in the same apply
+	found, err := api.HostsGet(zabbix.Params{
+		"output": []string{"hostid"},
+		"filter": map[string]interface{}{"host": hosts},
+	})
+	if err != nil || len(found) < 1 {
+		return nil, err
+	}
+	return &templated, nil
+}
+
+// triggerDependencyCheck dependencies must exist, match the template or host level of the trigger
+// and not lead back to the trigger itself, templated nil skips the level check
+func triggerDependencyCheck(api *zabbix.API, self triggerDependencyRef, templated *bool, deps []string, prototype bool) error {
+	for _, id := range deps {
+		if id == self.TriggerID {
+			return errors.New("trigger cannot depend on itself")
+		}
+	}
+
+	known, err := triggerDependencyRefs(api, deps, prototype)
+	if err != nil {
+		return err
+	}
+	for _, id := range deps {
+		dep, ok := known[id]
+		switch {
+		case !ok:
+			return fmt.Errorf("trigger %s not found", id)
+		case templated != nil && *templated && !dep.templated():
+			return fmt.Errorf("template trigger cannot depend on host trigger %s", dep)
+		case templated != nil && !*templated && dep.templated():
+			return fmt.Errorf("host trigger cannot depend on template trigger %s", dep)
+		}
+	}
+
+	// new triggers cannot be part of a cycle
+	if self.TriggerID == "" {
+		return nil
+	}
+
+	// breadth first walk of the dependency graph, looking for the trigger itself
+	parent := map[string]string{}
+	queue := []string{}
+	for _, id := range deps {
+		parent[id] = self.TriggerID
+		queue = append(queue, id)
+	}
+	for len(queue) > 0 {
+		next := []string{}
+		for _, id := range queue {
+			for _, dep := range known[id].Dependencies {
+				if dep.TriggerID == self.TriggerID {
+					return fmt.Errorf("dependency cycle %s", triggerDependencyPath(self, known, parent, id))
+				}
+				if _, seen := parent[dep.TriggerID]; !seen {
+					parent[dep.TriggerID] = id
+					next = append(next, dep.TriggerID)
+				}
+			}
+		}
+		if len(next) == 0 {
+			break
+		}
+
+		refs, err := triggerDependencyRefs(api, next, prototype)
+		if err != nil {
+			return err
+		}
+		for id, ref := range refs {
+			known[id] = ref
+		}
+		queue = next
+	}
+	return nil
+}
+
+// triggerDependencyPath render a cycle, from the trigger back to itself
+func triggerDependencyPath(self triggerDependencyRef, known map[string]triggerDependencyRef, parent map[string]string, last string) string {
+	path := []string{self.String()}
+	for id := last; id != self.TriggerID; id = parent[id] {
+		path = append([]string{known[id].String()}, path...)
+	}
+	return self.String() + " -> " + strings.Join(path, " -> ")
+}
+
+// triggerDependencyRefs look up triggers by id, trigger prototypes may depend on other prototypes
+func triggerDependencyRefs(api *zabbix.API, ids []string, prototype bool) (map[string]triggerDependencyRef, error) {
+	refs := map[string]triggerDependencyRef{}
+
+	methods := []string{"trigger.get"}
+	if prototype {
+		methods = append(methods, "triggerprototype.get")
+	}
+	for _, method := range methods {
+		res := []triggerDependencyRef{}
+		err := api.CallWithErrorParse(method, zabbix.Params{
+			"triggerids":         ids,
+			"output":             []string{"triggerid", "description"},
+			"selectHosts":        []string{"host", "status"},
+			"selectDependencies": []string{"triggerid"},
+		}, &res)
+		if err != nil {
+			return nil, err
+		}
+		for _, t := range res {
+			refs[t.TriggerID] = t
+		}
+	}
+	return refs, nil
+}
@@ -0,0 +1,117 @@
+package provider
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/terraform"
+	"github.com/tpretz/go-zabbix-api"
+)
+
+// trigger graph 1 -> 2 -> 3 on a host, 10 on a template
+const triggerDependencyGraph = `[
+	{"triggerid":"1","description":"one","hosts":[{"host":"Linux","status":"0"}],"dependencies":[{"triggerid":"2"}]},
+	{"triggerid":"2","description":"two","hosts":[{"host":"Linux","status":"0"}],"dependencies":[{"triggerid":"3"}]},
+	{"triggerid":"3","description":"three","hosts":[{"host":"Linux","status":"0"}],"dependencies":[]},
+	{"triggerid":"10","description":"template","hosts":[{"host":"Template","status":"3"}],"dependencies":[]}
+]`
+
+func triggerDependencyTestAPI(t *testing.T) (*zabbix.API, func()) {
+	server := exportTestServer(t, map[string]string{
+		"apiinfo.version": `"6.0.0"`,
+		"trigger.get":     triggerDependencyGraph,
+		"template.get":    `[{"templateid":"100","host":"Template"}]`,
+	})
+	api, err := zabbix.NewAPI(zabbix.Config{Url: server.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return api, server.Close
+}
+
+func TestTriggerDependencyCheck(t *testing.T) {
+	api, closer := triggerDependencyTestAPI(t)
+	defer closer()
+
+	host, template := false, true
+	cases := []struct {
+		self      string
+		templated *bool
+		deps      []string
+		err       string
+	}{
+		{"", &host, []string{"1"}, ""},
+		{"", nil, []string{"10"}, ""},
+		{"4", &host, []string{"1", "3"}, ""},
+		{"3", &host, []string{"1"}, `dependency cycle "three" (3) -> "one" (1) -> "two" (2) -> "three" (3)`},
+		{"3", &host, []string{"3"}, "cannot depend on itself"},
+		{"3", &host, []string{"99"}, "trigger 99 not found"},
+		{"3", &host, []string{"10"}, `host trigger cannot depend on template trigger "template" (10)`},
+		{"11", &template, []string{"1"}, `template trigger cannot depend on host trigger "one" (1)`},
+	}
+
+	for i, tc := range cases {
+		self := triggerDependencyRef{TriggerID: tc.self, Description: "three"}
+		err := triggerDependencyCheck(api, self, tc.templated, tc.deps, false)
+		switch {
+		case tc.err == "" && err != nil:
+			t.Errorf("case %d: unexpected error %s", i, err)
+		case tc.err != "" && err == nil:
+			t.Errorf("case %d: expected error %q", i, tc.err)
+		case tc.err != "" && !strings.Contains(err.Error(), tc.err):
+			t.Errorf("case %d: expected error %q, got %q", i, tc.err, err)
+		}
+	}
+}
+
+func TestTriggerDependencyCustomizeDiff(t *testing.T) {
+	api, closer := triggerDependencyTestAPI(t)
+	defer closer()
+
+	state := &terraform.InstanceState{ID: "3", Attributes: map[string]string{"name": "three", "expression": "last(/Linux/key)>0"}}
+	config := map[string]interface{}{"name": "three", "expression": "last(/Linux/key)>0", "dependencies": []interface{}{"1"}}
+	if _, err := resourceTrigger().Diff(state, terraform.NewResourceConfigRaw(config), api); err == nil || !strings.Contains(err.Error(), "dependencies: dependency cycle") {
+		t.Errorf("expected cycle error, got %v", err)
+	}
+
+	// new trigger, template level from the expression hosts
+	config = map[string]interface{}{"name": "new", "expression": "last(/Template/key)>0", "dependencies": []interface{}{"1"}}
+	if _, err := resourceTrigger().Diff(nil, terraform.NewResourceConfigRaw(config), api); err == nil || !strings.Contains(err.Error(), "template trigger cannot depend on host trigger") {
+		t.Errorf("expected template error, got %v", err)
+	}
+
+	// hosts of the expression not found, ie created in the same apply, the level is unknown
+	unknown := exportTestServer(t, map[string]string{
+		"apiinfo.version": `"6.0.0"`,
+		"trigger.get":     triggerDependencyGraph,
+	})
+	defer unknown.Close()
+	unknownAPI, err := zabbix.NewAPI(zabbix.Config{Url: unknown.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+	config = map[string]interface{}{"name": "new", "expression": "last(/New Template/key)>0", "dependencies": []interface{}{"10"}}
+	if _, err := resourceTrigger().Diff(nil, terraform.NewResourceConfigRaw(config), unknownAPI); err != nil {
+		t.Errorf("unexpected error %s", err)
+	}
+
+	config = map[string]interface{}{"triggerid": "3", "depends_on_triggerid": "1"}
+	if _, err := resourceTriggerDependency().Diff(nil, terraform.NewResourceConfigRaw(config), api); err == nil || !strings.Contains(err.Error(), "depends_on_triggerid: dependency cycle") {
+		t.Errorf("expected cycle error, got %v", err)
+	}
+	config = map[string]interface{}{"triggerid": "1", "depends_on_triggerid": "3"}
+	if _, err := resourceTriggerDependency().Diff(nil, terraform.NewResourceConfigRaw(config), api); err != nil {
+		t.Errorf("unexpected error %s", err)
+	}
+}
+
+func TestTriggerDependencyId(t *testing.T) {
+	if a, b, err := triggerDependencyId("12:34"); err != nil || a != "12" || b != "34" {
+		t.Errorf("unexpected %s %s %v", a, b, err)
+	}
+	for _, id := range []string{"12", "12:", "a:34", "12:34:56"} {
+		if _, _, err := triggerDependencyId(id); err == nil {
+			t.Errorf("%s: expected error", id)
+		}
+	}
+}
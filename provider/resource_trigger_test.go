@@ -0,0 +1,238 @@
+package provider
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/terraform"
+	"github.com/tpretz/go-zabbix-api"
+)
+
+func TestBuildTriggerObjectVersions(t *testing.T) {
+	d := resourceProtoTrigger().Data(nil)
+	d.Set("name", "High load on {#CPU}")
+	d.Set("expression", "last(/Linux/system.cpu.load[{#CPU}])>5")
+	d.Set("event_name", "Load {ITEM.LASTVALUE1}")
+	d.Set("url_name", "Runbook")
+	d.Set("discover", false)
+
+	cases := []struct {
+		version  int
+		present  []string
+		excluded []string
+	}{
+		{40000, nil, []string{"opdata", "event_name", "url_name", "discover"}},
+		{50000, []string{`"opdata":""`, `"discover":"1"`}, []string{"event_name", "url_name"}},
+		{50200, []string{`"event_name":"Load {ITEM.LASTVALUE1}"`}, []string{"url_name"}},
+		{60400, []string{`"opdata":""`, `"url_name":"Runbook"`}, nil},
+	}
+
+	for _, tc := range cases {
+		api := &zabbix.API{Config: zabbix.Config{Version: tc.version}}
+		b, err := json.Marshal(buildTriggerObject(d, api, true))
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, s := range tc.present {
+			if !strings.Contains(string(b), s) {
+				t.Errorf("%d: expected %s in %s", tc.version, s, b)
+			}
+		}
+		for _, s := range tc.excluded {
+			if strings.Contains(string(b), `"`+s+`"`) {
+				t.Errorf("%d: unexpected %s in %s", tc.version, s, b)
+			}
+		}
+	}
+
+	// discover only sent on prototypes
+	api := &zabbix.API{Config: zabbix.Config{Version: 60400}}
+	if b, _ := json.Marshal(buildTriggerObject(d, api, false)); strings.Contains(string(b), "discover") {
+		t.Errorf("unexpected discover in %s", b)
+	}
+
+	// unset dependencies are left alone
+	if b, _ := json.Marshal(buildTriggerObject(d, api, false)); strings.Contains(string(b), "dependencies") {
+		t.Errorf("unexpected dependencies in %s", b)
+	}
+}
+
+func TestTriggerDependenciesUnmanaged(t *testing.T) {
+	requests := []exportTestRequest{}
+	server := exportTestRecorder(t, map[string]string{
+		"apiinfo.version": `"6.0.0"`,
+		"trigger.update":  `{"triggerids":["1"]}`,
+		"trigger.get":     `[{"triggerid":"1","description":"renamed","expression":"last(/host/key)>5","priority":"0","status":"0","type":"0","recovery_mode":"0","correlation_mode":"0","manual_close":"0","dependencies":[{"triggerid":"2"}],"tags":[]}]`,
+	}, &requests)
+	defer server.Close()
+
+	api, err := zabbix.NewAPI(zabbix.Config{Url: server.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		state        map[string]string
+		dependencies []interface{}
+		expected     string
+	}{
+		// dependency added by zabbix_trigger_dependency, outside of this resource
+		{map[string]string{"dependencies.#": "1", fmt.Sprintf("dependencies.%d", schema.HashString("2")): "2"}, nil, ""},
+		{map[string]string{"dependencies.#": "0"}, []interface{}{"3"}, `"dependencies":[{"triggerid":"3"}]`},
+	}
+
+	for i, tc := range cases {
+		state := &terraform.InstanceState{ID: "1", Attributes: map[string]string{
+			"name": "trigger", "expression": "last(/host/key)>5", "enabled": "true", "priority": "not_classified",
+		}}
+		for k, v := range tc.state {
+			state.Attributes[k] = v
+		}
+		config := map[string]interface{}{"name": "renamed", "expression": "last(/host/key)>5"}
+		if tc.dependencies != nil {
+			config["dependencies"] = tc.dependencies
+		}
+		// planned without a server, dependency lookups are covered by the dependency tests
+		r := resourceTrigger()
+		diff, err := r.Diff(state, terraform.NewResourceConfigRaw(config), nil)
+		if err != nil {
+			t.Fatalf("case %d: %s", i, err)
+		}
+		requests = requests[:0]
+		if _, err := r.Apply(state, diff, api); err != nil {
+			t.Fatalf("case %d: %s", i, err)
+		}
+
+		var update string
+		for _, req := range requests {
+			if req.Method == "trigger.update" {
+				update = string(req.Params)
+			}
+		}
+		if update == "" {
+			t.Fatalf("case %d: trigger not updated", i)
+		}
+		if tc.expected == "" && strings.Contains(update, "dependencies") {
+			t.Errorf("case %d: unexpected dependencies in %s", i, update)
+		}
+		if tc.expected != "" && !strings.Contains(update, tc.expected) {
+			t.Errorf("case %d: expected %s in %s", i, tc.expected, update)
+		}
+	}
+}
+
+func TestTriggerUnmarshal(t *testing.T) {
+	var triggers apiTriggers
+	raw := `[{"triggerid":"1","description":"name","expression":"1=1","opdata":"{ITEM.LASTVALUE1}","event_name":"event","url_name":"docs","discover":"1","priority":"0","status":"0","type":"0","recovery_mode":"0","correlation_mode":"0","manual_close":"0"}]`
+	if err := json.Unmarshal([]byte(raw), &triggers); err != nil {
+		t.Fatal(err)
+	}
+	tr := triggers[0]
+	if tr.OpData == nil || *tr.OpData != "{ITEM.LASTVALUE1}" || *tr.EventName != "event" || *tr.UrlName != "docs" || *tr.Discover != "1" {
+		t.Errorf("unexpected trigger %+v", tr)
+	}
+}
+
+func TestTriggerCustomizeDiff(t *testing.T) {
+	api44 := &zabbix.API{Config: zabbix.Config{Version: 40400}}
+	api50 := &zabbix.API{Config: zabbix.Config{Version: 50000}}
+	api60 := &zabbix.API{Config: zabbix.Config{Version: 60000}}
+	api64 := &zabbix.API{Config: zabbix.Config{Version: 60400}}
+
+	cases := []struct {
+		config map[string]interface{}
+		api    *zabbix.API
+		err    string
+	}{
+		{map[string]interface{}{"opdata": "{ITEM.LASTVALUE}"}, api44, "opdata requires zabbix 5.0"},
+		{map[string]interface{}{"opdata": "{ITEM.LASTVALUE}"}, api50, ""},
+		{map[string]interface{}{"event_name": "event"}, api50, "event_name requires zabbix 5.2"},
+		{map[string]interface{}{"event_name": "event"}, api60, ""},
+		{map[string]interface{}{"url_name": "docs"}, api60, "url_name requires zabbix 6.4"},
+		{map[string]interface{}{"url_name": "docs"}, api64, ""},
+	}
+
+	for i, tc := range cases {
+		tc.config["name"] = "trigger"
+		tc.config["expression"] = "last(/host/key)=1"
+		if tc.api.Config.Version < 50400 {
+			tc.config["expression"] = "{host:key.last()}=1"
+		}
+
+		_, err := resourceTrigger().Diff(nil, terraform.NewResourceConfigRaw(tc.config), tc.api)
+		switch {
+		case tc.err == "" && err != nil:
+			t.Errorf("case %d: unexpected error %s", i, err)
+		case tc.err != "" && err == nil:
+			t.Errorf("case %d: expected error %q", i, tc.err)
+		case tc.err != "" && !strings.Contains(err.Error(), tc.err):
+			t.Errorf("case %d: expected error %q, got %q", i, tc.err, err)
+		}
+	}
+
+	config := map[string]interface{}{"name": "trigger", "expression": "{host:key.last()}=1", "discover": false}
+	if _, err := resourceProtoTrigger().Diff(nil, terraform.NewResourceConfigRaw(config), api44); err == nil || !strings.Contains(err.Error(), "discover requires zabbix 5.0") {
+		t.Errorf("expected discover version error, got %v", err)
+	}
+}
+
+func TestTriggerNormalizeExpressions(t *testing.T) {
+	config := map[string]interface{}{"name": "trigger", "expression": "{host:key.last()}>0", "recovery_expression": "{host:key.last()}=0"}
+	api60 := &zabbix.API{Config: zabbix.Config{Version: 60000}}
+	api50 := &zabbix.API{Config: zabbix.Config{Version: 50000}}
+	normalize60 := &providerMeta{api: api60, normalizeExpressions: true}
+
+	// legacy syntax rejected by 5.4+ unless normalizing
+	if _, err := resourceTrigger().Diff(nil, terraform.NewResourceConfigRaw(config), api60); err == nil {
+		t.Errorf("expected legacy expression error")
+	}
+	if _, err := resourceTrigger().Diff(nil, terraform.NewResourceConfigRaw(config), normalize60); err != nil {
+		t.Errorf("unexpected error %s", err)
+	}
+
+	// legacy config against the server converted state after an upgrade
+	state := &terraform.InstanceState{ID: "1", Attributes: map[string]string{
+		"name": "trigger", "expression": "last(/host/key)>5", "recovery_expression": "{host:key.last()}=0",
+	}}
+	config = map[string]interface{}{"name": "trigger", "expression": "{host:key.last()} > 5", "recovery_expression": "{host:key.last()}=0"}
+	diff, err := resourceTrigger().Diff(state, terraform.NewResourceConfigRaw(config), api60)
+	if err != nil {
+		t.Errorf("unexpected error %s", err)
+	}
+	if diff != nil && (diff.Attributes["expression"] != nil || diff.Attributes["recovery_expression"] != nil) {
+		t.Errorf("unexpected expression diff %+v", diff.Attributes)
+	}
+	config["expression"] = "{host:key.last()}>6"
+	if _, err := resourceTrigger().Diff(state, terraform.NewResourceConfigRaw(config), api60); err == nil {
+		t.Errorf("expected legacy expression error for a changed expression")
+	}
+
+	d := resourceTrigger().Data(nil)
+	d.Set("name", "trigger")
+	d.Set("expression", "{host:key.last()}>0")
+	d.Set("recovery_expression", "{host:key.last()}=0")
+
+	tr := buildTriggerObject(d, normalize60, false)
+	if tr.Expression != "last(/host/key)>0" || tr.RecoveryExpression != "last(/host/key)=0" {
+		t.Errorf("expected converted expressions, got %s / %s", tr.Expression, tr.RecoveryExpression)
+	}
+	// legacy syntax is never sent to 5.4+, only canonicalized when normalizing
+	d.Set("recovery_expression", "last( /host/key ) = 0")
+	if tr := buildTriggerObject(d, api60, false); tr.Expression != "last(/host/key)>0" || tr.RecoveryExpression != "last( /host/key ) = 0" {
+		t.Errorf("unexpected expressions without normalizing, got %s / %s", tr.Expression, tr.RecoveryExpression)
+	}
+	if tr := buildTriggerObject(d, &providerMeta{api: api50, normalizeExpressions: true}, false); tr.Expression != "{host:key.last()}>0" {
+		t.Errorf("unexpected conversion for 5.0, got %s", tr.Expression)
+	}
+
+	// equivalent server form keeps the configured expression
+	if v := triggerExpressionRead(d, "expression", "last(/host/key)>0"); v != "{host:key.last()}>0" {
+		t.Errorf("expected configured expression, got %s", v)
+	}
+	if v := triggerExpressionRead(d, "expression", "last(/host/key)>1"); v != "last(/host/key)>1" {
+		t.Errorf("expected server expression, got %s", v)
+	}
+}